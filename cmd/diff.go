@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/brainicorn/jsonschemagen/diff"
+
+	"github.com/spf13/cobra"
+)
+
+var diffCompatibilities = map[string]diff.Compatibility{
+	"any":      diff.CompatibilityBreaking,
+	"backward": diff.CompatibilityBackward,
+	"forward":  diff.CompatibilityForward,
+	"full":     diff.CompatibilityFull,
+}
+
+// DiffCmd is the command that compares two schemas.
+type DiffCmd struct {
+	Cmd           *cobra.Command
+	format        string
+	compatibility string
+}
+
+// NewDiffCommand creates a new instance of the DiffCmd.
+func NewDiffCommand() *DiffCmd {
+	dc := &DiffCmd{}
+	dc.Cmd = &cobra.Command{
+		Use:   "diff [old schema file] [new schema file]",
+		Short: "Compares two schemas and classifies the changes",
+		Long: `diff compares two versions of a schema and reports added and
+removed properties, changed types, tightened and loosened
+constraints, required changes and enum changes.
+
+Each change is classified like a schema registry does:
+backward-compatible changes only loosen the schema so consumers
+can be upgraded first, forward-compatible changes only tighten
+it so producers can be upgraded first, and breaking changes are
+neither.
+
+The command exits with a non-zero status if a change doesn't
+keep the compatibility passed with --compatibility.`,
+		Args:          cobra.ExactArgs(2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          dc.doDiff,
+	}
+
+	flags := dc.Cmd.Flags()
+	flags.StringVar(&dc.format, "format", "text", "output format: text or markdown")
+	flags.StringVar(&dc.compatibility, "compatibility", "any", "compatibility the changes must keep: any (no breaking changes), backward, forward or full")
+	return dc
+}
+
+func (c *DiffCmd) doDiff(cmd *cobra.Command, args []string) error {
+	required, found := diffCompatibilities[c.compatibility]
+	if !found {
+		return fmt.Errorf("invalid compatibility %s", c.compatibility)
+	}
+
+	if c.format != "text" && c.format != "markdown" {
+		return fmt.Errorf("invalid format %s", c.format)
+	}
+
+	oldBytes, err := ioutil.ReadFile(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	newBytes, err := ioutil.ReadFile(args[1])
+
+	if err != nil {
+		return err
+	}
+
+	changes, err := diff.CompareJSON(oldBytes, newBytes)
+
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+
+	if c.format == "markdown" {
+		fmt.Fprint(out, diff.Markdown(changes))
+	} else {
+		for _, change := range changes {
+			fmt.Fprintln(out, change)
+		}
+		fmt.Fprintf(out, "%d changes, overall compatibility: %s\n", len(changes), diff.Summarize(changes))
+	}
+
+	// a mix of backward and forward compatible changes is breaking as a whole
+	overall := diff.Summarize(changes)
+
+	if !required.Allows(overall) {
+		if overall == diff.CompatibilityBreaking {
+			return fmt.Errorf("the changes are breaking")
+		}
+
+		return fmt.Errorf("the changes are only %s compatible but %s compatibility is required", overall, c.compatibility)
+	}
+
+	return nil
+}
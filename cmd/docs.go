@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/brainicorn/jsonschemagen/docs"
+
+	"github.com/spf13/cobra"
+)
+
+// DocsCmd is the command that renders a schema into Markdown reference pages.
+type DocsCmd struct {
+	Cmd       *cobra.Command
+	rootName  string
+	outputDir string
+}
+
+// NewDocsCommand creates a new instance of the DocsCmd.
+func NewDocsCommand() *DocsCmd {
+	dc := &DocsCmd{}
+	dc.Cmd = &cobra.Command{
+		Use:   "docs [schema file]",
+		Short: "Renders a schema into Markdown reference pages",
+		Long: `docs renders a json-schema into Markdown reference pages.
+
+The root and every definition get a page with a table of their
+properties showing the name, type, whether it's required, the
+constraints, the default and the description. Refs link to the
+page of the definition, enum values and oneOf/anyOf variants
+are listed and x-go-path is shown as the source type.
+
+With --output the root page is written to README.md in the
+directory and each definition to <name>.md next to it.
+Otherwise everything is written to stdout as a single page.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          dc.doDocs,
+	}
+
+	flags := dc.Cmd.Flags()
+	flags.StringVar(&dc.rootName, "root", "", "heading of the root page (default is taken from x-go-path or Root)")
+	flags.StringVarP(&dc.outputDir, "output", "o", "", "directory to write the pages to (default is a single page on stdout)")
+	return dc
+}
+
+func (c *DocsCmd) doDocs(cmd *cobra.Command, args []string) error {
+	schemaBytes, err := ioutil.ReadFile(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	pages, err := docs.RenderJSON(schemaBytes, docs.Options{
+		RootName:   c.rootName,
+		SinglePage: c.outputDir == "",
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if c.outputDir == "" {
+		_, err = cmd.OutOrStdout().Write(pages[0].Content)
+		return err
+	}
+
+	if err = os.MkdirAll(c.outputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %s", err)
+	}
+
+	for _, page := range pages {
+		if err = ioutil.WriteFile(filepath.Join(c.outputDir, page.FileName), page.Content, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/spf13/cobra"
+)
+
+// ExampleCmd is the command that builds an example instance of a schema.
+type ExampleCmd struct {
+	Cmd        *cobra.Command
+	seed       int64
+	minimal    bool
+	outputFile string
+}
+
+// NewExampleCommand creates a new instance of the ExampleCmd.
+func NewExampleCommand() *ExampleCmd {
+	ec := &ExampleCmd{}
+	ec.Cmd = &cobra.Command{
+		Use:   "example [schema file]",
+		Short: "Builds an example JSON document from a schema",
+		Long: `example builds a representative JSON document that's valid
+against a schema, for docs and test fixtures.
+
+Defaults, consts and enums are used where the schema has them.
+Other values are generated to match the type, format, numeric
+bounds, string lengths and patterns and array sizes. The first
+branch of a oneOf or anyOf is used.
+
+The same schema and --seed always give the same document.
+--minimal only fills required properties.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          ec.doExample,
+	}
+
+	flags := ec.Cmd.Flags()
+	flags.Int64Var(&ec.seed, "seed", 0, "seed for the values the schema doesn't pin down")
+	flags.BoolVar(&ec.minimal, "minimal", false, "only fill required properties")
+	flags.StringVarP(&ec.outputFile, "output", "o", "", "file to write the example to (default is stdout)")
+	return ec
+}
+
+func (c *ExampleCmd) doExample(cmd *cobra.Command, args []string) error {
+	schemaBytes, err := ioutil.ReadFile(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	root, err := schema.FromJSON(schemaBytes)
+
+	if err != nil {
+		return fmt.Errorf("error parsing schema: %s", err)
+	}
+
+	example, err := schema.MarshalIndentExample(root, schema.ExampleOptions{Seed: c.seed, Minimal: c.minimal}, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	example = append(example, '\n')
+
+	if c.outputFile != "" {
+		return ioutil.WriteFile(c.outputFile, example, 0644)
+	}
+
+	_, err = cmd.OutOrStdout().Write(example)
+	return err
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/brainicorn/jsonschemagen/importer"
+
+	"github.com/spf13/cobra"
+)
+
+// ImportCmd is the command that generates GO types from a schema.
+type ImportCmd struct {
+	Cmd        *cobra.Command
+	pkgName    string
+	importPath string
+	rootName   string
+	outputFile string
+}
+
+// NewImportCommand creates a new instance of the ImportCmd.
+func NewImportCommand() *ImportCmd {
+	ic := &ImportCmd{}
+	ic.Cmd = &cobra.Command{
+		Use:   "import [schema file]",
+		Short: "Generates annotated GO types from a schema",
+		Long: `import turns an existing json-schema into GO source.
+
+Every definition becomes a named type and the root becomes a
+type named by --root. Required properties become value fields
+and optional ones become pointers with omitempty. Constraints
+are written as @jsonSchema annotations, oneOf and anyOf become
+annotated interfaces and enums become typed constants.
+
+Annotations refer to other types by their fully-qualified path
+so --import-path must be the import path of the package the
+source is written to. Running jsonschemagen on that package
+gives back an equivalent schema.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          ic.doImport,
+	}
+
+	flags := ic.Cmd.Flags()
+	flags.StringVar(&ic.pkgName, "package", "", "name of the generated package (required)")
+	flags.StringVar(&ic.importPath, "import-path", "", "import path of the generated package (default is the package name)")
+	flags.StringVar(&ic.rootName, "root", "", "name of the root type (default is taken from x-go-path or Root)")
+	flags.StringVarP(&ic.outputFile, "output", "o", "", "file to write the source to (default is stdout)")
+	return ic
+}
+
+func (c *ImportCmd) doImport(cmd *cobra.Command, args []string) error {
+	if c.pkgName == "" {
+		return fmt.Errorf("--package is required")
+	}
+
+	schemaBytes, err := ioutil.ReadFile(args[0])
+
+	if err != nil {
+		return err
+	}
+
+	src, err := importer.GenerateJSON(schemaBytes, importer.Options{
+		PackageName: c.pkgName,
+		ImportPath:  c.importPath,
+		RootName:    c.rootName,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if c.outputFile != "" {
+		return ioutil.WriteFile(c.outputFile, src, 0644)
+	}
+
+	_, err = cmd.OutOrStdout().Write(src)
+	return err
+}
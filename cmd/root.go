@@ -3,7 +3,6 @@ package cmd
 import (
 	"bufio"
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -38,6 +37,42 @@ const (
 	goFileName = "schema_accessor.go"
 )
 
+var requiredPolicies = map[string]generator.RequiredPolicy{
+	"annotation":               generator.RequiredAnnotationOnly,
+	"not-omitempty":            generator.RequiredNotOmitEmpty,
+	"not-omitempty-or-pointer": generator.RequiredNotOmitEmptyOrPointer,
+}
+
+var nullableStyles = map[string]generator.NullableStyle{
+	"none":       generator.NullableNone,
+	"type-array": generator.NullableTypeArray,
+	"openapi":    generator.NullableOpenAPI,
+}
+
+var propertyOrderStyles = map[string]generator.PropertyOrderStyle{
+	"none":          generator.PropertyOrderNone,
+	"propertyOrder": generator.PropertyOrderKeyword,
+	"x-order":       generator.PropertyOrderXOrder,
+}
+
+var specVersions = map[string]schema.SpecVersion{
+	"draft-04": schema.SpecVersionDraftV4,
+	"draft-06": schema.SpecVersionDraft06,
+	"draft-07": schema.SpecVersionDraft07,
+	"2019-09":  schema.SpecVersionDraft201909,
+	"2020-12":  schema.SpecVersionDraft202012,
+}
+
+var openAPIVersions = map[string]schema.OpenAPIVersion{
+	"3.0": schema.OpenAPIVersion30,
+	"3.1": schema.OpenAPIVersion31,
+}
+
+var openAPIFormats = map[string]func(map[string]schema.JSONSchema, schema.OpenAPIVersion) ([]byte, error){
+	"yaml": schema.MarshalOpenAPIComponentsYAML,
+	"json": schema.MarshalOpenAPIComponents,
+}
+
 type templateData struct {
 	VarName string
 	Schema  string
@@ -57,28 +92,43 @@ type RootCmd struct {
 	removeDir      bool
 	outputDir      string
 	rootFilename   string
-	basePackage    string
-	rootType       string
+	roots          []generator.RootSpec
+	parallelism    int
+	writtenVars    map[string]bool
+	rootFilenames  map[generator.RootSpec]string
 	gen            *generator.JSONSchemaGenerator
 	suppressXAttrs bool
+	requiredPolicy string
+	typeMappings   string
+	nullableStyle  string
+	propertyOrder  string
+	specVersion    string
+	openAPI        string
+	openAPIFormat  string
 }
 
 // NewRootCommand creates a new instance of the RootCmd.
 func NewRootCommand() *RootCmd {
 	rc := &RootCmd{}
 	rc.Cmd = &cobra.Command{
-		Use:   "jsonschemagen [base package] [root type]",
+		Use:   "jsonschemagen [base package] [root type] [[base package] [root type]...] | [package pattern...]",
 		Short: "A commandline tool for generating json-schema from Go code",
 		Long: `jsonschemagen is a commandline tool for generating json-schema from Go code.
 
 jsonschemagen takes a base package and a root object
 and generates a full json-schema for all involved types.
+Several package and type pairs can be passed to generate
+many root schemas while only loading the code once.
+Alternatively, package patterns like ./... can be passed
+to generate every type annotated with @jsonSchema(root=true).
 
 It will generate very basic schema without any code changes,
 however, java-style annotations can be used to customize and
 generate complex schemas that follow the json-schema spec.
 
 For more information, see http://json-schema.org/`,
+		// packages and types are positional args, so they can't be mistaken for unknown subcommands
+		Args: cobra.ArbitraryArgs,
 		RunE: rc.doGeneration,
 	}
 
@@ -94,6 +144,19 @@ For more information, see http://json-schema.org/`,
 	flags.StringVarP(&rc.outputDir, "output", "o", "./schema", "output directory for files (default is ./schema)")
 	flags.StringVarP(&rc.rootFilename, "filename", "f", "", "filename for root schema (default is calculated using pkg and type)")
 	flags.BoolVarP(&rc.suppressXAttrs, "suppress-x-attrs", "x", false, "supress non-standard attributes")
+	flags.StringVar(&rc.requiredPolicy, "required", "annotation", "how required fields are inferred: annotation, not-omitempty or not-omitempty-or-pointer")
+	flags.StringVar(&rc.specVersion, "spec-version", "draft-04", "json-schema version to generate: draft-04, draft-06, draft-07, 2019-09 or 2020-12")
+	flags.StringVar(&rc.openAPI, "openapi", "", "write a single OpenAPI components file for the roots instead of json-schema files: 3.0 or 3.1")
+	flags.StringVar(&rc.openAPIFormat, "openapi-format", "yaml", "format of the OpenAPI components file: yaml or json")
+	flags.StringVar(&rc.nullableStyle, "nullable-pointers", "none", "how pointer fields accept null: none, type-array or openapi")
+	flags.StringVar(&rc.propertyOrder, "property-order", "none", "keyword that holds the position of each property: none, propertyOrder or x-order")
+	flags.IntVarP(&rc.parallelism, "parallel", "p", 1, "number of root schemas to generate at the same time")
+	flags.StringVar(&rc.typeMappings, "type-mappings", "", "json file mapping fully-qualified go types to the schemas to use for them")
+
+	rc.Cmd.AddCommand(NewDiffCommand().Cmd)
+	rc.Cmd.AddCommand(NewImportCommand().Cmd)
+	rc.Cmd.AddCommand(NewExampleCommand().Cmd)
+	rc.Cmd.AddCommand(NewDocsCommand().Cmd)
 	return rc
 }
 
@@ -123,7 +186,8 @@ func (c *RootCmd) getLogLevel() generator.LogLevel {
 
 func (c *RootCmd) doGeneration(cmd *cobra.Command, args []string) error {
 	var err error
-	var rootSchema schema.JSONSchema
+	var absOutputDir string
+	var rootSchemas map[generator.RootSpec]schema.JSONSchema
 
 	start := time.Now()
 
@@ -131,51 +195,214 @@ func (c *RootCmd) doGeneration(cmd *cobra.Command, args []string) error {
 		fmt.Println(logHead)
 	}
 
-	if len(args) < 2 {
+	if len(args) < 1 {
 		cmd.Usage()
 		os.Exit(0)
 	}
 
-	if !c.isPackageString(args[0]) {
-		return fmt.Errorf("invalid package specifier %s", args[0])
+	// a single argument or arguments that aren't package and type pairs are package patterns
+	// like ./... to search for annotated roots
+	discover := len(args) == 1 || !c.isIdent(args[1])
+
+	c.roots = nil
+	c.rootFilenames = make(map[generator.RootSpec]string)
+
+	if !discover {
+		if len(args)%2 != 0 {
+			return fmt.Errorf("expected pairs of package and type but got %d arguments", len(args))
+		}
+
+		for i := 0; i < len(args); i += 2 {
+			if !c.isPackageString(args[i]) {
+				return fmt.Errorf("invalid package specifier %s", args[i])
+			}
+
+			if !c.isIdent(args[i+1]) {
+				return fmt.Errorf("invalid type specifier %s", args[i+1])
+			}
+
+			c.roots = append(c.roots, generator.RootSpec{Package: args[i], Type: args[i+1]})
+		}
 	}
 
-	if !c.isIdent(args[1]) {
-		return fmt.Errorf("invalid type specifier %s", args[1])
+	policy, found := requiredPolicies[c.requiredPolicy]
+	if !found {
+		return fmt.Errorf("invalid required policy %s", c.requiredPolicy)
+	}
+
+	nullable, found := nullableStyles[c.nullableStyle]
+	if !found {
+		return fmt.Errorf("invalid nullable style %s", c.nullableStyle)
+	}
+
+	propertyOrder, found := propertyOrderStyles[c.propertyOrder]
+	if !found {
+		return fmt.Errorf("invalid property order %s", c.propertyOrder)
+	}
+
+	version, found := specVersions[c.specVersion]
+	if !found {
+		return fmt.Errorf("invalid spec version %s", c.specVersion)
+	}
+
+	if c.openAPI != "" {
+		if _, found = openAPIVersions[c.openAPI]; !found {
+			return fmt.Errorf("invalid OpenAPI version %s", c.openAPI)
+		}
+
+		if _, found = openAPIFormats[c.openAPIFormat]; !found {
+			return fmt.Errorf("invalid OpenAPI format %s", c.openAPIFormat)
+		}
+
+		if c.defFiles || c.codegen {
+			return fmt.Errorf("the openapi flag can't be used with separate-files or codegen")
+		}
 	}
 
-	c.basePackage = args[0]
-	c.rootType = args[1]
 	opts := generator.NewOptions()
+	opts.SpecVersion = version
+	opts.RequiredPolicy = policy
+	opts.NullablePointers = nullable
+	opts.PropertyOrder = propertyOrder
 	opts.LogLevel = c.getLogLevel()
 	opts.AutoCreateDefs = !c.inlineDefs
 	opts.IncludeTests = c.includeTests
 	opts.SupressXAttrs = c.suppressXAttrs
+
+	// OpenAPI 3.0 schemas are based on draft-04 and 3.1 schemas are 2020-12
+	if c.openAPI == string(schema.OpenAPIVersion30) {
+		opts.SpecVersion = schema.SpecVersionDraftV4
+	} else if c.openAPI == string(schema.OpenAPIVersion31) {
+		opts.SpecVersion = schema.SpecVersionDraft202012
+	}
+	opts.Parallelism = c.parallelism
+
+	if c.typeMappings != "" {
+		var mappingBytes []byte
+
+		mappingBytes, err = ioutil.ReadFile(c.typeMappings)
+
+		if err == nil {
+			opts.TypeMappings, err = generator.ParseTypeMappings(mappingBytes)
+		}
+
+		if err != nil {
+			return fmt.Errorf("error loading type mappings from %s: %s", c.typeMappings, err)
+		}
+	}
+
 	c.opts = opts
-	c.gen = generator.NewJSONSchemaGenerator(c.basePackage, c.rootType, opts)
+	c.writtenVars = make(map[string]bool)
+
+	if discover {
+		c.gen = generator.NewJSONSchemaGenerator("", "", opts)
+		err = c.discoverRoots(args)
+	} else {
+		c.gen = generator.NewJSONSchemaGenerator(c.roots[0].Package, c.roots[0].Type, opts)
+	}
+
+	if err == nil && c.openAPI == "" && len(c.roots) > 1 && len(strings.TrimSpace(c.rootFilename)) > 0 {
+		err = fmt.Errorf("the filename flag can only be used with a single root")
+	}
 
-	rootSchema, err = c.gen.Generate()
+	if err == nil && c.openAPI != "" {
+		err = c.writeComponentsFile()
+
+		c.gen.LogInfo("total generation took ", time.Since(start))
+		return err
+	}
 
 	if err == nil {
-		err = c.writeSchemaFiles(rootSchema)
+		rootSchemas, err = c.gen.GenerateAll(c.roots)
+	}
+
+	if err == nil {
+		absOutputDir, err = c.prepareOutputDir()
+	}
+
+	for _, root := range c.roots {
+		if err != nil {
+			break
+		}
+
+		err = c.writeSchemaFiles(absOutputDir, root, rootSchemas[root])
 	}
 
 	c.gen.LogInfo("total generation took ", time.Since(start))
 	return err
 }
 
-func (c *RootCmd) writeSchemaFiles(rootSchema schema.JSONSchema) error {
+// discoverRoots finds the types annotated with @jsonSchema(root=true) in the packages matching the
+// patterns, along with the filenames from their annotations.
+func (c *RootCmd) discoverRoots(patterns []string) error {
+	annotated, err := c.gen.FindAnnotatedRoots(patterns...)
+
+	if err != nil {
+		return err
+	}
+
+	if len(annotated) < 1 {
+		return fmt.Errorf("no @jsonSchema(root=true) types found in %s", strings.Join(patterns, ", "))
+	}
+
+	filenameRoots := make(map[string]generator.RootSpec)
+
+	for _, root := range annotated {
+		c.gen.LogInfo("found root ", root)
+		c.roots = append(c.roots, root.RootSpec)
+
+		if root.Filename == "" {
+			continue
+		}
+
+		if other, found := filenameRoots[root.Filename]; found {
+			return fmt.Errorf("roots %s and %s both use the filename %s", other, root.RootSpec, root.Filename)
+		}
+
+		filenameRoots[root.Filename] = root.RootSpec
+		c.rootFilenames[root.RootSpec] = root.Filename
+	}
+
+	return nil
+}
+
+// writeComponentsFile generates the roots and writes them and all of their definitions to a single
+// OpenAPI components file.
+func (c *RootCmd) writeComponentsFile() error {
 	var err error
 	var absOutputDir string
-	var schemaBytes []byte
-	var codeBuffer bytes.Buffer
-	var codeFile *os.File
-	var defSchema schema.JSONSchema
-	var gofname string
+	var components map[string]schema.JSONSchema
+	var componentBytes []byte
 
-	tmpl, _ := template.New("schemaTemplate").Parse("\t// {{.VarName}} is a json-schema accessor\n\t{{.VarName}} = `{{.Schema}}`\n\n")
+	components, err = c.gen.GenerateComponents(c.roots)
+
+	if err == nil {
+		componentBytes, err = openAPIFormats[c.openAPIFormat](components, openAPIVersions[c.openAPI])
+	}
+
+	if err == nil {
+		absOutputDir, err = c.prepareOutputDir()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	filename := "components." + c.openAPIFormat
+	if len(strings.TrimSpace(c.rootFilename)) > 0 {
+		filename = c.rootFilename
+	}
+
+	componentsFile := filepath.Join(absOutputDir, filename)
+	c.gen.LogInfo("writing components file ", componentsFile)
 
-	absOutputDir, err = filepath.Abs(c.outputDir)
+	return ioutil.WriteFile(componentsFile, componentBytes, 0664)
+}
+
+// prepareOutputDir creates the output dir, removing it first if asked to, and returns its absolute
+// path.
+func (c *RootCmd) prepareOutputDir() (string, error) {
+	absOutputDir, err := filepath.Abs(c.outputDir)
 	c.gen.LogInfo("remove dir? ", c.removeDir)
 	if err == nil {
 		if c.removeDir {
@@ -189,13 +416,28 @@ func (c *RootCmd) writeSchemaFiles(rootSchema schema.JSONSchema) error {
 		}
 	}
 
-	if err == nil {
-		schemaBytes, err = json.MarshalIndent(rootSchema, "", "  ")
-	}
+	return absOutputDir, err
+}
+
+func (c *RootCmd) writeSchemaFiles(absOutputDir string, root generator.RootSpec, rootSchema schema.JSONSchema) error {
+	var err error
+	var schemaBytes []byte
+	var codeBuffer bytes.Buffer
+	var codeFile *os.File
+	var defSchema schema.JSONSchema
+	var gofname string
+
+	tmpl, _ := template.New("schemaTemplate").Parse("\t// {{.VarName}} is a json-schema accessor\n\t{{.VarName}} = `{{.Schema}}`\n\n")
+
+	schemaBytes, err = schema.MarshalIndentForVersion(rootSchema, c.opts.SpecVersion, "", "  ")
 
 	if err == nil {
 		//write the main schema file
-		fname := refToFilename(c.basePackage + "/" + c.rootType)
+		fname := refToFilename(root.String())
+		if annotatedName, found := c.rootFilenames[root]; found {
+			fname = annotatedName
+		}
+
 		if len(strings.TrimSpace(c.rootFilename)) > 0 {
 			fname = c.rootFilename
 		}
@@ -241,9 +483,10 @@ func (c *RootCmd) writeSchemaFiles(rootSchema schema.JSONSchema) error {
 		}
 
 		if err == nil {
-			schemaBytes, err = json.Marshal(rootSchema)
-			if err == nil {
-				err = tmpl.Execute(&codeBuffer, templateData{VarName: refToVarName(c.basePackage + "/" + c.rootType), Schema: string(schemaBytes)})
+			schemaBytes, err = schema.MarshalForVersion(rootSchema, c.opts.SpecVersion)
+			if err == nil && !c.writtenVars[refToVarName(root.String())] {
+				c.writtenVars[refToVarName(root.String())] = true
+				err = tmpl.Execute(&codeBuffer, templateData{VarName: refToVarName(root.String()), Schema: string(schemaBytes)})
 			}
 		}
 	}
@@ -254,7 +497,7 @@ func (c *RootCmd) writeSchemaFiles(rootSchema schema.JSONSchema) error {
 			defSchema, err = c.gen.SubGenerate(p, t)
 
 			if c.defFiles {
-				schemaBytes, err = json.MarshalIndent(defSchema, "", "  ")
+				schemaBytes, err = schema.MarshalIndentForVersion(defSchema, c.opts.SpecVersion, "", "  ")
 
 				if err == nil {
 					schemaPath := filepath.Join(absOutputDir, refToFilename(defK))
@@ -265,8 +508,10 @@ func (c *RootCmd) writeSchemaFiles(rootSchema schema.JSONSchema) error {
 
 			if c.codegen {
 				if err == nil {
-					schemaBytes, err = json.Marshal(defSchema)
-					if err == nil {
+					schemaBytes, err = schema.MarshalForVersion(defSchema, c.opts.SpecVersion)
+					// roots often share definitions, so only write each one once
+					if err == nil && !c.writtenVars[refToVarName(defK)] {
+						c.writtenVars[refToVarName(defK)] = true
 						err = tmpl.Execute(&codeBuffer, templateData{VarName: refToVarName(defK), Schema: string(schemaBytes)})
 					}
 				}
@@ -283,6 +528,10 @@ func (c *RootCmd) writeSchemaFiles(rootSchema schema.JSONSchema) error {
 		}
 	}
 
+	if codeFile != nil {
+		codeFile.Close()
+	}
+
 	return err
 }
 
@@ -318,8 +567,14 @@ func refToPackageAndType(ref string) (string, string) {
 	s = strings.Replace(s, "_", ".", -1)
 	s = strings.Replace(s, "-", "/", -1)
 
-	pkg := s[:strings.LastIndex(s, "/")]
-	typ := s[strings.LastIndex(s, "/")+1:]
+	// generic instantiations carry their type arguments in brackets after the type name
+	base := s
+	if idx := strings.Index(s, "["); idx != -1 {
+		base = s[:idx]
+	}
+
+	pkg := s[:strings.LastIndex(base, "/")]
+	typ := s[strings.LastIndex(base, "/")+1:]
 
 	return pkg, typ
 
@@ -344,6 +599,9 @@ func refToVarName(ref string) string {
 	s = strings.Replace(s, "-", " ", -1)
 	s = strings.Replace(s, ".", " ", -1)
 	s = strings.Replace(s, "/", " ", -1)
+	s = strings.Replace(s, "[", " ", -1)
+	s = strings.Replace(s, "]", " ", -1)
+	s = strings.Replace(s, ",", " ", -1)
 
 	s = strings.Title(s)
 
@@ -0,0 +1,653 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// upperLimits and lowerLimits are the numeric keywords that bound a value from above or below.
+var upperLimits = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+var lowerLimits = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+
+// annotations are keywords that don't affect validation.
+var annotations = []string{"title", "description", "default"}
+
+// opaqueKeywords are compared as a whole. Adding one tightens the schema, removing it loosens it,
+// and changing it can go either way so it's breaking.
+var opaqueKeywords = []string{"pattern", "format", "not", "propertyNames", "patternProperties", "dependencies", "dependentRequired", "dependentSchemas", "contains"}
+
+type comparer struct {
+	oldRoot interface{}
+	newRoot interface{}
+	changes []Change
+	// comparing holds the pairs of refs being compared so recursive schemas terminate
+	comparing map[string]bool
+}
+
+func (c *comparer) add(path string, kind ChangeKind, compatibility Compatibility, format string, args ...interface{}) {
+	c.changes = append(c.changes, Change{
+		Path:          path,
+		Kind:          kind,
+		Compatibility: compatibility,
+		Message:       fmt.Sprintf(format, args...),
+	})
+}
+
+// constraint records a change to a constraint as tightened or loosened.
+func (c *comparer) constraint(path string, tightened bool, format string, args ...interface{}) {
+	if tightened {
+		c.add(path, ConstraintTightened, CompatibilityForward, format, args...)
+		return
+	}
+
+	c.add(path, ConstraintLoosened, CompatibilityBackward, format, args...)
+}
+
+func (c *comparer) compare(oldNode, newNode interface{}, path string) error {
+	oldRef, oldNode, err := resolve(c.oldRoot, oldNode)
+	if err != nil {
+		return fmt.Errorf("error in old schema at '%s': %s", displayPath(path), err)
+	}
+
+	newRef, newNode, err := resolve(c.newRoot, newNode)
+	if err != nil {
+		return fmt.Errorf("error in new schema at '%s': %s", displayPath(path), err)
+	}
+
+	if oldRef != "" || newRef != "" {
+		key := oldRef + " " + newRef
+		if c.comparing[key] {
+			return nil
+		}
+
+		c.comparing[key] = true
+		defer delete(c.comparing, key)
+	}
+
+	oldBool, oldIsBool := oldNode.(bool)
+	newBool, newIsBool := newNode.(bool)
+
+	if (oldIsBool && !oldBool) || (newIsBool && !newBool) {
+		if !reflect.DeepEqual(oldNode, newNode) {
+			c.constraint(path, newIsBool && !newBool, "schema changed from %s to %s", describe(oldNode), describe(newNode))
+		}
+		return nil
+	}
+
+	oldSchema := normalize(oldNode)
+	newSchema := normalize(newNode)
+
+	c.compareTypes(oldSchema, newSchema, path)
+	c.compareEnums(oldSchema, newSchema, path)
+
+	for _, keyword := range upperLimits {
+		c.compareLimit(oldSchema, newSchema, keyword, path, true)
+	}
+
+	for _, keyword := range lowerLimits {
+		c.compareLimit(oldSchema, newSchema, keyword, path, false)
+	}
+
+	c.compareMultipleOf(oldSchema, newSchema, path)
+	c.compareUniqueItems(oldSchema, newSchema, path)
+
+	for _, keyword := range opaqueKeywords {
+		c.compareOpaque(oldSchema, newSchema, keyword, path)
+	}
+
+	c.compareRequired(oldSchema, newSchema, path)
+
+	if err = c.compareProperties(oldSchema, newSchema, path); err == nil {
+		err = c.compareAdditionalProperties(oldSchema, newSchema, path)
+	}
+
+	if err == nil {
+		err = c.compareItems(oldSchema, newSchema, path)
+	}
+
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		if err == nil {
+			err = c.compareCombinator(oldSchema, newSchema, keyword, path)
+		}
+	}
+
+	for _, keyword := range annotations {
+		if !reflect.DeepEqual(oldSchema[keyword], newSchema[keyword]) {
+			c.add(keywordPath(path, keyword), AnnotationChanged, CompatibilityFull, "%s changed from %s to %s", keyword, describe(oldSchema[keyword]), describe(newSchema[keyword]))
+		}
+	}
+
+	return err
+}
+
+func (c *comparer) compareTypes(oldSchema, newSchema map[string]interface{}, path string) {
+	oldTypes := typeSet(oldSchema)
+	newTypes := typeSet(newSchema)
+
+	if reflect.DeepEqual(oldTypes, newTypes) {
+		return
+	}
+
+	oldDesc := describeTypes(oldTypes)
+	newDesc := describeTypes(newTypes)
+	typePath := keywordPath(path, "type")
+
+	switch {
+	case coversTypes(newTypes, oldTypes):
+		c.add(typePath, TypeChanged, CompatibilityBackward, "type widened from %s to %s", oldDesc, newDesc)
+	case coversTypes(oldTypes, newTypes):
+		c.add(typePath, TypeChanged, CompatibilityForward, "type narrowed from %s to %s", oldDesc, newDesc)
+	default:
+		c.add(typePath, TypeChanged, CompatibilityBreaking, "type changed from %s to %s", oldDesc, newDesc)
+	}
+}
+
+func (c *comparer) compareEnums(oldSchema, newSchema map[string]interface{}, path string) {
+	oldEnum, oldHas := oldSchema["enum"].([]interface{})
+	newEnum, newHas := newSchema["enum"].([]interface{})
+	enumPath := keywordPath(path, "enum")
+
+	switch {
+	case !oldHas && !newHas:
+		return
+	case !oldHas:
+		c.constraint(enumPath, true, "enum %s added", describe(newEnum))
+		return
+	case !newHas:
+		c.constraint(enumPath, false, "enum %s removed", describe(oldEnum))
+		return
+	}
+
+	oldValues := valueSet(oldEnum)
+	newValues := valueSet(newEnum)
+
+	for _, value := range newEnum {
+		if !oldValues[describe(value)] {
+			c.add(enumPath, EnumValueAdded, CompatibilityBackward, "enum value %s added", describe(value))
+		}
+	}
+
+	for _, value := range oldEnum {
+		if !newValues[describe(value)] {
+			c.add(enumPath, EnumValueRemoved, CompatibilityForward, "enum value %s removed", describe(value))
+		}
+	}
+}
+
+func (c *comparer) compareLimit(oldSchema, newSchema map[string]interface{}, keyword string, path string, upper bool) {
+	oldLimit, oldHas := toRat(oldSchema[keyword])
+	newLimit, newHas := toRat(newSchema[keyword])
+	limitPath := keywordPath(path, keyword)
+
+	switch {
+	case !oldHas && !newHas:
+		return
+	case !oldHas:
+		c.constraint(limitPath, true, "%s %s added", keyword, ratString(newLimit))
+		return
+	case !newHas:
+		c.constraint(limitPath, false, "%s %s removed", keyword, ratString(oldLimit))
+		return
+	}
+
+	cmp := newLimit.Cmp(oldLimit)
+	if cmp == 0 {
+		return
+	}
+
+	direction := "raised"
+	if cmp < 0 {
+		direction = "lowered"
+	}
+
+	c.constraint(limitPath, (cmp < 0) == upper, "%s %s from %s to %s", keyword, direction, ratString(oldLimit), ratString(newLimit))
+}
+
+func (c *comparer) compareMultipleOf(oldSchema, newSchema map[string]interface{}, path string) {
+	oldMultiple, oldHas := toRat(oldSchema["multipleOf"])
+	newMultiple, newHas := toRat(newSchema["multipleOf"])
+	multiplePath := keywordPath(path, "multipleOf")
+
+	switch {
+	case !oldHas && !newHas:
+		return
+	case !oldHas:
+		c.constraint(multiplePath, true, "multipleOf %s added", ratString(newMultiple))
+		return
+	case !newHas:
+		c.constraint(multiplePath, false, "multipleOf %s removed", ratString(oldMultiple))
+		return
+	case oldMultiple.Cmp(newMultiple) == 0:
+		return
+	}
+
+	msg := fmt.Sprintf("multipleOf changed from %s to %s", ratString(oldMultiple), ratString(newMultiple))
+
+	// every multiple of the old value is a multiple of the new value if the new one divides it
+	switch {
+	case new(big.Rat).Quo(oldMultiple, newMultiple).IsInt():
+		c.constraint(multiplePath, false, "%s", msg)
+	case new(big.Rat).Quo(newMultiple, oldMultiple).IsInt():
+		c.constraint(multiplePath, true, "%s", msg)
+	default:
+		c.add(multiplePath, ConstraintChanged, CompatibilityBreaking, "%s", msg)
+	}
+}
+
+func (c *comparer) compareUniqueItems(oldSchema, newSchema map[string]interface{}, path string) {
+	oldUnique, _ := oldSchema["uniqueItems"].(bool)
+	newUnique, _ := newSchema["uniqueItems"].(bool)
+
+	if oldUnique != newUnique {
+		c.constraint(keywordPath(path, "uniqueItems"), newUnique, "uniqueItems changed from %t to %t", oldUnique, newUnique)
+	}
+}
+
+func (c *comparer) compareOpaque(oldSchema, newSchema map[string]interface{}, keyword string, path string) {
+	oldValue, oldHas := oldSchema[keyword]
+	newValue, newHas := newSchema[keyword]
+	opaquePath := keywordPath(path, keyword)
+
+	switch {
+	case !oldHas && !newHas:
+		return
+	case !oldHas:
+		c.constraint(opaquePath, true, "%s %s added", keyword, describe(newValue))
+	case !newHas:
+		c.constraint(opaquePath, false, "%s %s removed", keyword, describe(oldValue))
+	case !reflect.DeepEqual(oldValue, newValue):
+		c.add(opaquePath, ConstraintChanged, CompatibilityBreaking, "%s changed from %s to %s", keyword, describe(oldValue), describe(newValue))
+	}
+}
+
+func (c *comparer) compareRequired(oldSchema, newSchema map[string]interface{}, path string) {
+	oldRequired := stringSet(oldSchema["required"])
+	newRequired := stringSet(newSchema["required"])
+	requiredPath := keywordPath(path, "required")
+
+	for _, name := range sortedKeys(newRequired) {
+		if !oldRequired[name] {
+			c.add(requiredPath, RequiredAdded, CompatibilityForward, "property '%s' is now required", name)
+		}
+	}
+
+	for _, name := range sortedKeys(oldRequired) {
+		if !newRequired[name] {
+			c.add(requiredPath, RequiredRemoved, CompatibilityBackward, "property '%s' is no longer required", name)
+		}
+	}
+}
+
+func (c *comparer) compareProperties(oldSchema, newSchema map[string]interface{}, path string) error {
+	oldProps, _ := oldSchema["properties"].(map[string]interface{})
+	newProps, _ := newSchema["properties"].(map[string]interface{})
+
+	names := make(map[string]bool)
+	for name := range oldProps {
+		names[name] = true
+	}
+	for name := range newProps {
+		names[name] = true
+	}
+
+	for _, name := range sortedKeys(names) {
+		propPath := keywordPath(keywordPath(path, "properties"), name)
+		oldProp, oldHas := oldProps[name]
+		newProp, newHas := newProps[name]
+
+		switch {
+		case !oldHas:
+			// a closed object didn't accept the property before, an open one accepted anything
+			if closed(oldSchema) {
+				c.add(propPath, PropertyAdded, CompatibilityBackward, "property '%s' added", name)
+			} else {
+				c.add(propPath, PropertyAdded, CompatibilityForward, "property '%s' added", name)
+			}
+		case !newHas:
+			if closed(newSchema) {
+				c.add(propPath, PropertyRemoved, CompatibilityForward, "property '%s' removed", name)
+			} else {
+				c.add(propPath, PropertyRemoved, CompatibilityBackward, "property '%s' removed", name)
+			}
+		default:
+			if err := c.compare(oldProp, newProp, propPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *comparer) compareAdditionalProperties(oldSchema, newSchema map[string]interface{}, path string) error {
+	oldValue, oldHas := oldSchema["additionalProperties"]
+	newValue, newHas := newSchema["additionalProperties"]
+
+	if !oldHas && !newHas {
+		return nil
+	}
+
+	// a missing additionalProperties allows anything, just like true
+	if !oldHas {
+		oldValue = true
+	}
+
+	if !newHas {
+		newValue = true
+	}
+
+	return c.compare(oldValue, newValue, keywordPath(path, "additionalProperties"))
+}
+
+func (c *comparer) compareItems(oldSchema, newSchema map[string]interface{}, path string) error {
+	oldTuple, _ := oldSchema["prefixItems"].([]interface{})
+	newTuple, _ := newSchema["prefixItems"].([]interface{})
+
+	if len(oldTuple) != len(newTuple) {
+		c.add(keywordPath(path, "prefixItems"), ConstraintChanged, CompatibilityBreaking, "tuple length changed from %d to %d", len(oldTuple), len(newTuple))
+	}
+
+	for i := 0; i < len(oldTuple) && i < len(newTuple); i++ {
+		if err := c.compare(oldTuple[i], newTuple[i], keywordPath(keywordPath(path, "prefixItems"), strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+
+	oldItems, oldHas := oldSchema["items"]
+	newItems, newHas := newSchema["items"]
+
+	if !oldHas && !newHas {
+		return nil
+	}
+
+	if !oldHas {
+		oldItems = true
+	}
+
+	if !newHas {
+		newItems = true
+	}
+
+	return c.compare(oldItems, newItems, keywordPath(path, "items"))
+}
+
+func (c *comparer) compareCombinator(oldSchema, newSchema map[string]interface{}, keyword string, path string) error {
+	oldList, _ := oldSchema[keyword].([]interface{})
+	newList, _ := newSchema[keyword].([]interface{})
+	listPath := keywordPath(path, keyword)
+
+	for i := 0; i < len(oldList) && i < len(newList); i++ {
+		if err := c.compare(oldList[i], newList[i], keywordPath(listPath, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+
+	// more allOf schemas tighten, more anyOf or oneOf options loosen
+	tightens := keyword == "allOf"
+
+	switch {
+	// a combinator that appears only adds constraints, whichever one it is
+	case len(oldList) == 0 && len(newList) > 0:
+		c.constraint(listPath, true, "%s with %d schemas added", keyword, len(newList))
+	case len(newList) == 0 && len(oldList) > 0:
+		c.constraint(listPath, false, "%s with %d schemas removed", keyword, len(oldList))
+	case len(newList) > len(oldList):
+		c.constraint(listPath, tightens, "%d %s schemas added", len(newList)-len(oldList), keyword)
+	case len(newList) < len(oldList):
+		c.constraint(listPath, !tightens, "%d %s schemas removed", len(oldList)-len(newList), keyword)
+	}
+
+	return nil
+}
+
+// resolve follows local $refs and returns the last ref followed along with its target.
+func resolve(root interface{}, node interface{}) (string, interface{}, error) {
+	var lastRef string
+
+	for i := 0; ; i++ {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return lastRef, node, nil
+		}
+
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return lastRef, node, nil
+		}
+
+		if i > 32 || !strings.HasPrefix(ref, "#") {
+			return "", nil, fmt.Errorf("unable to resolve $ref '%s'", ref)
+		}
+
+		fragment, err := url.PathUnescape(strings.TrimPrefix(ref, "#"))
+		target, found := lookupPointer(root, fragment)
+
+		if err != nil || !found {
+			return "", nil, fmt.Errorf("unable to resolve $ref '%s'", ref)
+		}
+
+		lastRef = ref
+		node = target
+	}
+}
+
+func lookupPointer(tree interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return tree, true
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	current := tree
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if current, ok = m[token]; !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// normalize returns a copy of the schema with keywords that mean the same thing in different
+// drafts written the same way, so schemas for different drafts can be compared.
+func normalize(node interface{}) map[string]interface{} {
+	s := make(map[string]interface{})
+
+	if m, ok := node.(map[string]interface{}); ok {
+		for k, v := range m {
+			s[k] = v
+		}
+	}
+
+	// draft-04 boolean exclusive limits become the numeric limits of newer drafts
+	for _, pair := range [][2]string{{"exclusiveMaximum", "maximum"}, {"exclusiveMinimum", "minimum"}} {
+		if exclusive, isBool := s[pair[0]].(bool); isBool {
+			delete(s, pair[0])
+			if exclusive {
+				s[pair[0]] = s[pair[1]]
+				delete(s, pair[1])
+			}
+		}
+	}
+
+	// a const is an enum with one value
+	if constValue, found := s["const"]; found {
+		if _, hasEnum := s["enum"]; !hasEnum {
+			s["enum"] = []interface{}{constValue}
+		}
+		delete(s, "const")
+	}
+
+	// tuple items become the prefixItems of 2020-12
+	if tuple, isTuple := s["items"].([]interface{}); isTuple {
+		s["prefixItems"] = tuple
+		delete(s, "items")
+		if additional, found := s["additionalItems"]; found {
+			s["items"] = additional
+		}
+	}
+
+	delete(s, "additionalItems")
+
+	// OpenAPI's nullable adds null to the type
+	if nullable, _ := s["nullable"].(bool); nullable {
+		if types := stringList(s["type"]); types != nil {
+			nullable := []interface{}{"null"}
+			for _, t := range types {
+				nullable = append(nullable, t)
+			}
+			s["type"] = nullable
+		}
+	}
+
+	return s
+}
+
+// closed returns true if the object schema doesn't allow properties it doesn't list.
+func closed(s map[string]interface{}) bool {
+	additional, isBool := s["additionalProperties"].(bool)
+
+	return isBool && !additional
+}
+
+// typeSet returns the set of types the schema allows or nil if it allows any type.
+func typeSet(s map[string]interface{}) map[string]bool {
+	types := stringList(s["type"])
+	if types == nil {
+		return nil
+	}
+
+	set := make(map[string]bool)
+	for _, t := range types {
+		set[t] = true
+	}
+
+	return set
+}
+
+// coversTypes returns true if every value of the inner types is allowed by the outer types.
+func coversTypes(outer, inner map[string]bool) bool {
+	if outer == nil {
+		return true
+	}
+
+	if inner == nil {
+		return false
+	}
+
+	for t := range inner {
+		if !outer[t] && !(t == "integer" && outer["number"]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func describeTypes(types map[string]bool) string {
+	if types == nil {
+		return "any"
+	}
+
+	return strings.Join(sortedKeys(types), " or ")
+}
+
+func stringList(value interface{}) []string {
+	switch typed := value.(type) {
+	case string:
+		return []string{typed}
+	case []interface{}:
+		var strs []string
+		for _, item := range typed {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	}
+
+	return nil
+}
+
+func stringSet(value interface{}) map[string]bool {
+	set := make(map[string]bool)
+
+	for _, s := range stringList(value) {
+		set[s] = true
+	}
+
+	return set
+}
+
+func valueSet(values []interface{}) map[string]bool {
+	set := make(map[string]bool)
+
+	for _, value := range values {
+		set[describe(value)] = true
+	}
+
+	return set
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func keywordPath(path string, token string) string {
+	return path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func toRat(value interface{}) (*big.Rat, bool) {
+	if number, ok := value.(json.Number); ok {
+		return new(big.Rat).SetString(number.String())
+	}
+
+	return nil, false
+}
+
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	f, _ := r.Float64()
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func describe(value interface{}) string {
+	if value == nil {
+		return "none"
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(b)
+}
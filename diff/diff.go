@@ -0,0 +1,200 @@
+// Package diff compares two versions of a schema and classifies every change by how it affects
+// the producers and consumers of the JSON, the way a schema registry does.
+//
+// A change is backward-compatible if the new schema accepts everything the old one did, so
+// consumers can be upgraded before producers. It's forward-compatible if the old schema accepts
+// everything the new one does, so producers can be upgraded first. Changes that are neither are
+// breaking, and changes that don't affect validation, like a new description, are fully
+// compatible.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// Compatibility classifies a change.
+type Compatibility string
+
+const (
+	// CompatibilityFull is for changes that don't affect which documents are valid.
+	CompatibilityFull Compatibility = "full"
+	// CompatibilityBackward is for changes that only loosen the schema.
+	CompatibilityBackward Compatibility = "backward"
+	// CompatibilityForward is for changes that only tighten the schema.
+	CompatibilityForward Compatibility = "forward"
+	// CompatibilityBreaking is for changes that are neither backward nor forward compatible.
+	CompatibilityBreaking Compatibility = "breaking"
+)
+
+// Allows returns true if changes with the given compatibility keep this level of compatibility.
+// CompatibilityBreaking allows everything except breaking changes.
+func (c Compatibility) Allows(change Compatibility) bool {
+	switch c {
+	case CompatibilityFull:
+		return change == CompatibilityFull
+	case CompatibilityBackward, CompatibilityForward:
+		return change == CompatibilityFull || change == c
+	}
+
+	return change != CompatibilityBreaking
+}
+
+// ChangeKind is the kind of a change.
+type ChangeKind string
+
+// The kinds of changes Compare reports. Tightened constraints are forward-compatible, loosened
+// ones are backward-compatible and changed ones are breaking.
+const (
+	PropertyAdded       ChangeKind = "property-added"
+	PropertyRemoved     ChangeKind = "property-removed"
+	TypeChanged         ChangeKind = "type-changed"
+	ConstraintTightened ChangeKind = "constraint-tightened"
+	ConstraintLoosened  ChangeKind = "constraint-loosened"
+	ConstraintChanged   ChangeKind = "constraint-changed"
+	RequiredAdded       ChangeKind = "required-added"
+	RequiredRemoved     ChangeKind = "required-removed"
+	EnumValueAdded      ChangeKind = "enum-value-added"
+	EnumValueRemoved    ChangeKind = "enum-value-removed"
+	AnnotationChanged   ChangeKind = "annotation-changed"
+)
+
+// Change is a single difference between the old and new schema.
+type Change struct {
+	// Path is a JSON Pointer to where the change was found. Refs are followed, so a change inside a
+	// definition shows up under each property that uses it.
+	Path          string
+	Kind          ChangeKind
+	Compatibility Compatibility
+	Message       string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("[%s] %s: %s", c.Compatibility, displayPath(c.Path), c.Message)
+}
+
+// Compare compares the old and new schemas.
+func Compare(oldSchema, newSchema schema.JSONSchema) ([]Change, error) {
+	oldBytes, err := json.Marshal(oldSchema)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newBytes, err := json.Marshal(newSchema)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return CompareJSON(oldBytes, newBytes)
+}
+
+// CompareJSON compares the JSON encodings of the old and new schemas. The schemas can be written
+// for different drafts.
+func CompareJSON(oldBytes, newBytes []byte) ([]Change, error) {
+	oldTree, err := decode(oldBytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding old schema: %s", err)
+	}
+
+	newTree, err := decode(newBytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding new schema: %s", err)
+	}
+
+	c := &comparer{oldRoot: oldTree, newRoot: newTree, comparing: make(map[string]bool)}
+
+	if err = c.compare(oldTree, newTree, ""); err != nil {
+		return nil, err
+	}
+
+	return c.changes, nil
+}
+
+// Summarize returns the compatibility of all of the changes together.
+func Summarize(changes []Change) Compatibility {
+	overall := CompatibilityFull
+
+	for _, change := range changes {
+		switch {
+		case overall == CompatibilityFull:
+			overall = change.Compatibility
+		case change.Compatibility != CompatibilityFull && change.Compatibility != overall:
+			overall = CompatibilityBreaking
+		}
+	}
+
+	return overall
+}
+
+// markdownSections are the changelog sections in the order they're written.
+var markdownSections = []struct {
+	compatibility Compatibility
+	title         string
+}{
+	{CompatibilityBreaking, "Breaking changes"},
+	{CompatibilityBackward, "Backward-compatible changes"},
+	{CompatibilityForward, "Forward-compatible changes"},
+	{CompatibilityFull, "Fully compatible changes"},
+}
+
+// Markdown writes the changes as a markdown changelog grouped by compatibility.
+func Markdown(changes []Change) string {
+	var buf bytes.Buffer
+
+	buf.WriteString("# Schema changes\n\n")
+
+	if len(changes) < 1 {
+		buf.WriteString("No changes.\n")
+		return buf.String()
+	}
+
+	fmt.Fprintf(&buf, "Overall compatibility: **%s**\n", Summarize(changes))
+
+	for _, section := range markdownSections {
+		var sectionChanges []Change
+
+		for _, change := range changes {
+			if change.Compatibility == section.compatibility {
+				sectionChanges = append(sectionChanges, change)
+			}
+		}
+
+		if len(sectionChanges) < 1 {
+			continue
+		}
+
+		fmt.Fprintf(&buf, "\n## %s\n\n", section.title)
+
+		for _, change := range sectionChanges {
+			fmt.Fprintf(&buf, "- `%s`: %s\n", displayPath(change.Path), change.Message)
+		}
+	}
+
+	return buf.String()
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	return path
+}
+
+func decode(b []byte) (interface{}, error) {
+	var tree interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	err := dec.Decode(&tree)
+
+	return tree, err
+}
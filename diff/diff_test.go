@@ -0,0 +1,206 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func compareStrings(t *testing.T, oldJSON, newJSON string) []string {
+	changes, err := CompareJSON([]byte(oldJSON), []byte(newJSON))
+	assert.NoError(t, err)
+
+	var found []string
+	for _, change := range changes {
+		found = append(found, change.String())
+	}
+
+	return found
+}
+
+func TestCompareProperties(t *testing.T) {
+	t.Parallel()
+
+	oldJSON := `{"type": "object", "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}, "required": ["name"]}`
+	newJSON := `{"type": "object", "properties": {"name": {"type": "string"}, "email": {"type": "string"}}, "required": ["email"]}`
+
+	assert.Equal(t, []string{
+		"[forward] /required: property 'email' is now required",
+		"[backward] /required: property 'name' is no longer required",
+		"[backward] /properties/age: property 'age' removed",
+		"[forward] /properties/email: property 'email' added",
+	}, compareStrings(t, oldJSON, newJSON))
+}
+
+func TestComparePropertiesOfClosedObjects(t *testing.T) {
+	t.Parallel()
+
+	oldJSON := `{"type": "object", "properties": {"age": {"type": "integer"}}, "additionalProperties": false}`
+	newJSON := `{"type": "object", "properties": {"email": {"type": "string"}}, "additionalProperties": false}`
+
+	// a closed object didn't accept email before and doesn't accept age any more
+	assert.Equal(t, []string{
+		"[forward] /properties/age: property 'age' removed",
+		"[backward] /properties/email: property 'email' added",
+	}, compareStrings(t, oldJSON, newJSON))
+
+	assert.Equal(t, []string{
+		"[backward] /additionalProperties: schema changed from false to true",
+	}, compareStrings(t, oldJSON, `{"type": "object", "properties": {"age": {"type": "integer"}}}`))
+}
+
+func TestCompareTypes(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"[backward] /type: type widened from integer to number"}, compareStrings(t, `{"type": "integer"}`, `{"type": "number"}`))
+	assert.Equal(t, []string{"[forward] /type: type narrowed from null or string to string"}, compareStrings(t, `{"type": ["string", "null"]}`, `{"type": "string"}`))
+	assert.Equal(t, []string{"[breaking] /type: type changed from string to integer"}, compareStrings(t, `{"type": "string"}`, `{"type": "integer"}`))
+	assert.Equal(t, []string{"[backward] /type: type widened from string to any"}, compareStrings(t, `{"type": "string"}`, `{}`))
+
+	// OpenAPI's nullable is the same as a null type
+	assert.Empty(t, compareStrings(t, `{"type": ["string", "null"]}`, `{"type": "string", "nullable": true}`))
+}
+
+func TestCompareConstraints(t *testing.T) {
+	t.Parallel()
+
+	oldJSON := `{"type": "object", "properties": {
+		"name": {"type": "string", "maxLength": 10, "minLength": 1, "pattern": "^a"},
+		"count": {"type": "integer", "maximum": 10, "multipleOf": 2},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3}
+	}}`
+	newJSON := `{"type": "object", "properties": {
+		"name": {"type": "string", "maxLength": 20, "minLength": 2, "pattern": "^b"},
+		"count": {"type": "integer", "maximum": 5, "multipleOf": 4},
+		"tags": {"type": "array", "items": {"type": "string", "format": "email"}, "uniqueItems": true}
+	}}`
+
+	assert.Equal(t, []string{
+		"[forward] /properties/count/maximum: maximum lowered from 10 to 5",
+		"[forward] /properties/count/multipleOf: multipleOf changed from 2 to 4",
+		"[backward] /properties/name/maxLength: maxLength raised from 10 to 20",
+		"[forward] /properties/name/minLength: minLength raised from 1 to 2",
+		"[breaking] /properties/name/pattern: pattern changed from \"^a\" to \"^b\"",
+		"[backward] /properties/tags/maxItems: maxItems 3 removed",
+		"[forward] /properties/tags/uniqueItems: uniqueItems changed from false to true",
+		"[forward] /properties/tags/items/format: format \"email\" added",
+	}, compareStrings(t, oldJSON, newJSON))
+}
+
+func TestCompareEnums(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{
+		"[backward] /enum: enum value \"blue\" added",
+		"[forward] /enum: enum value \"green\" removed",
+	}, compareStrings(t, `{"type": "string", "enum": ["red", "green"]}`, `{"type": "string", "enum": ["red", "blue"]}`))
+
+	// a const is an enum with one value
+	assert.Equal(t, []string{
+		"[backward] /enum: enum value \"v2\" added",
+	}, compareStrings(t, `{"const": "v1"}`, `{"enum": ["v1", "v2"]}`))
+}
+
+func TestCompareCombinators(t *testing.T) {
+	t.Parallel()
+
+	either := `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`
+
+	// an anyOf that wasn't there before restricts what's valid
+	assert.Equal(t, []string{"[forward] /anyOf: anyOf with 2 schemas added"}, compareStrings(t, `{}`, either))
+	assert.Equal(t, []string{"[backward] /anyOf: anyOf with 2 schemas removed"}, compareStrings(t, either, `{}`))
+	assert.Equal(t, []string{"[forward] /oneOf: oneOf with 1 schemas added"}, compareStrings(t, `{}`, `{"oneOf": [{"type": "string"}]}`))
+
+	// more options loosen an existing anyOf, more allOf schemas tighten
+	assert.Equal(t, []string{"[backward] /anyOf: 1 anyOf schemas added"}, compareStrings(t, either, `{"anyOf": [{"type": "string"}, {"type": "integer"}, {"type": "null"}]}`))
+	assert.Equal(t, []string{"[forward] /anyOf: 1 anyOf schemas removed"}, compareStrings(t, either, `{"anyOf": [{"type": "string"}]}`))
+	assert.Equal(t, []string{"[forward] /allOf: 1 allOf schemas added"}, compareStrings(t, `{"allOf": [{"type": "object"}]}`, `{"allOf": [{"type": "object"}, {"required": ["id"]}]}`))
+}
+
+func TestCompareFollowsRefs(t *testing.T) {
+	t.Parallel()
+
+	oldJSON := `{
+		"type": "object",
+		"properties": {"child": {"$ref": "#/definitions/Child"}, "other": {"$ref": "#/definitions/Child"}},
+		"definitions": {"Child": {"type": "object", "properties": {"name": {"type": "string"}, "next": {"$ref": "#/definitions/Child"}}}}
+	}`
+	newJSON := `{
+		"type": "object",
+		"properties": {"child": {"$ref": "#/$defs/Child"}, "other": {"$ref": "#/$defs/Child"}},
+		"$defs": {"Child": {"type": "object", "properties": {"name": {"type": "integer"}, "next": {"$ref": "#/$defs/Child"}}}}
+	}`
+
+	// recursion stops at the recursive ref and a shared definition is reported where it's used
+	assert.Equal(t, []string{
+		"[breaking] /properties/child/properties/name/type: type changed from string to integer",
+		"[breaking] /properties/other/properties/name/type: type changed from string to integer",
+	}, compareStrings(t, oldJSON, newJSON))
+
+	_, err := CompareJSON([]byte(`{"$ref": "#/definitions/Missing"}`), []byte(`{}`))
+	assert.EqualError(t, err, "error in old schema at '/': unable to resolve $ref '#/definitions/Missing'")
+}
+
+func TestCompareAcrossDrafts(t *testing.T) {
+	t.Parallel()
+
+	draft04 := `{"type": "array", "items": [{"type": "number", "maximum": 5, "exclusiveMaximum": true}], "additionalItems": false}`
+	draft202012 := `{"type": "array", "prefixItems": [{"type": "number", "exclusiveMaximum": 5}], "items": false}`
+
+	assert.Empty(t, compareStrings(t, draft04, draft202012))
+}
+
+func TestCompareSchemas(t *testing.T) {
+	t.Parallel()
+
+	oldSchema := schema.NewStringSchema()
+	newSchema := schema.NewStringSchema()
+	newSchema.SetMaxLength(5)
+	newSchema.SetDescription("a short string")
+
+	changes, err := Compare(oldSchema, newSchema)
+
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, ConstraintTightened, changes[0].Kind)
+	assert.Equal(t, AnnotationChanged, changes[1].Kind)
+	assert.Equal(t, CompatibilityForward, Summarize(changes))
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	full := Change{Compatibility: CompatibilityFull}
+	backward := Change{Compatibility: CompatibilityBackward}
+	forward := Change{Compatibility: CompatibilityForward}
+
+	assert.Equal(t, CompatibilityFull, Summarize(nil))
+	assert.Equal(t, CompatibilityBackward, Summarize([]Change{full, backward, backward}))
+	assert.Equal(t, CompatibilityBreaking, Summarize([]Change{backward, full, forward}))
+
+	assert.True(t, CompatibilityBreaking.Allows(CompatibilityForward))
+	assert.False(t, CompatibilityBreaking.Allows(CompatibilityBreaking))
+	assert.True(t, CompatibilityBackward.Allows(CompatibilityFull))
+	assert.False(t, CompatibilityBackward.Allows(CompatibilityForward))
+	assert.False(t, CompatibilityFull.Allows(CompatibilityBackward))
+}
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	changes, err := CompareJSON([]byte(`{"type": "string", "enum": ["a", "b"]}`), []byte(`{"type": "integer", "enum": ["a", "c"]}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "# Schema changes\n\n"+
+		"Overall compatibility: **breaking**\n\n"+
+		"## Breaking changes\n\n"+
+		"- `/type`: type changed from string to integer\n\n"+
+		"## Backward-compatible changes\n\n"+
+		"- `/enum`: enum value \"c\" added\n\n"+
+		"## Forward-compatible changes\n\n"+
+		"- `/enum`: enum value \"b\" removed\n", Markdown(changes))
+
+	assert.Equal(t, "# Schema changes\n\nNo changes.\n", Markdown(nil))
+}
@@ -0,0 +1,296 @@
+// Package docs renders a JSON Schema into Markdown reference pages so that docs for config structs
+// don't have to be maintained by hand.
+//
+// The root and every definition get a page with a table of their properties:
+//
+//	pages, err := docs.RenderJSON(schemaBytes, docs.Options{})
+//
+// Refs become links to the page of the definition they point to, enum values are listed with their
+// names and descriptions, oneOf and anyOf variants are listed and the x-go-path of a definition is
+// shown as its source type.
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// RootFileName is the file name of the root page. Definition pages are named after the definition.
+const RootFileName = "README.md"
+
+// Options holds the configuration for rendering docs.
+type Options struct {
+	// RootName is the heading of the root page. Defaults to the last element of the root's
+	// x-go-path, or Root.
+	RootName string
+	// SinglePage renders the root and all of the definitions into one page whose links are anchors
+	// instead of a page per definition.
+	SinglePage bool
+}
+
+// Page is a rendered Markdown page.
+type Page struct {
+	// Name is the heading of the page.
+	Name string
+	// FileName is the name other pages link to this one by, so pages have to be written next to each
+	// other under these names for the links to work.
+	FileName string
+	Content  []byte
+}
+
+type renderer struct {
+	opts     Options
+	root     schema.JSONSchema
+	defs     map[string]schema.JSONSchema
+	defKeys  []string
+	defNames map[string]string
+	rootName string
+}
+
+// Render returns the pages for the schema. The root page comes first, followed by a page for each
+// definition sorted by name.
+func Render(root schema.JSONSchema, opts Options) ([]Page, error) {
+	r := &renderer{
+		opts:     opts,
+		root:     root,
+		defs:     root.GetDefinitions(),
+		defNames: make(map[string]string),
+	}
+
+	r.nameDefinitions()
+
+	if err := r.checkRefs(root, make(map[schema.JSONSchema]bool)); err != nil {
+		return nil, err
+	}
+
+	if opts.SinglePage {
+		var buf bytes.Buffer
+
+		r.writeDefinition(&buf, r.rootName, root, 1)
+
+		for _, key := range r.defKeys {
+			buf.WriteString("\n")
+			r.writeDefinition(&buf, r.defNames[key], r.defs[key], 2)
+		}
+
+		return []Page{{Name: r.rootName, FileName: RootFileName, Content: buf.Bytes()}}, nil
+	}
+
+	pages := make([]Page, 0, len(r.defKeys)+1)
+
+	var buf bytes.Buffer
+	r.writeDefinition(&buf, r.rootName, root, 1)
+	r.writeIndex(&buf)
+	pages = append(pages, Page{Name: r.rootName, FileName: RootFileName, Content: buf.Bytes()})
+
+	for _, key := range r.defKeys {
+		var buf bytes.Buffer
+		r.writeDefinition(&buf, r.defNames[key], r.defs[key], 1)
+		pages = append(pages, Page{Name: r.defNames[key], FileName: r.defNames[key] + ".md", Content: buf.Bytes()})
+	}
+
+	return pages, nil
+}
+
+// RenderJSON parses the JSON encoding of a schema and returns the pages for it.
+func RenderJSON(schemaBytes []byte, opts Options) ([]Page, error) {
+	root, err := schema.FromJSON(schemaBytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing schema: %s", err)
+	}
+
+	return Render(root, opts)
+}
+
+// nameDefinitions picks a unique page name for the root and every definition and sorts the
+// definitions by it.
+func (r *renderer) nameDefinitions() {
+	// the root page is always README.md so no definition can be named that
+	taken := map[string]bool{"readme": true}
+
+	reserve := func(name string) string {
+		unique := name
+		for i := 2; taken[strings.ToLower(unique)]; i++ {
+			unique = fmt.Sprintf("%s%d", name, i)
+		}
+
+		// anchors and some file systems are case-insensitive
+		taken[strings.ToLower(unique)] = true
+
+		return unique
+	}
+
+	r.rootName = r.opts.RootName
+	if r.rootName == "" {
+		r.rootName = goPathName(r.root)
+	}
+	if r.rootName == "" {
+		r.rootName = "Root"
+	}
+	r.rootName = reserve(r.rootName)
+
+	keys := make([]string, 0, len(r.defs))
+	for key := range r.defs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := goPathName(r.defs[key])
+		if name == "" {
+			name = definitionName(key)
+		}
+
+		r.defNames[key] = reserve(name)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return r.defNames[keys[i]] < r.defNames[keys[j]]
+	})
+
+	r.defKeys = keys
+}
+
+// checkRefs returns an error for a local $ref that doesn't point to the root or a definition.
+func (r *renderer) checkRefs(s schema.JSONSchema, seen map[schema.JSONSchema]bool) error {
+	if s == nil || seen[s] {
+		return nil
+	}
+	seen[s] = true
+
+	if key, isDefinition := definitionKey(s.GetRef()); isDefinition {
+		if _, found := r.defs[key]; !found {
+			return fmt.Errorf("unresolvable $ref %s", s.GetRef())
+		}
+	}
+
+	for _, sub := range subschemas(s) {
+		if err := r.checkRefs(sub, seen); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// link returns a Markdown link to the page of the schema a $ref points to. Refs that aren't to the
+// root or a definition are shown as is.
+func (r *renderer) link(ref string) string {
+	name := ""
+
+	if ref == "#" {
+		name = r.rootName
+	} else if key, isDefinition := definitionKey(ref); isDefinition {
+		name = r.defNames[key]
+	}
+
+	if name == "" {
+		return code(ref)
+	}
+
+	return fmt.Sprintf("[%s](%s)", name, r.target(name))
+}
+
+// definitionKey returns the name of the definition a $ref points to, using either definitions or
+// the $defs of draft 2019-09 and newer.
+func definitionKey(ref string) (string, bool) {
+	for _, prefix := range []string{schema.DefinitionRoot, schema.DefsRoot} {
+		if strings.HasPrefix(ref, prefix) {
+			return ref[len(prefix):], true
+		}
+	}
+
+	return "", false
+}
+
+// target returns where links to the named page go.
+func (r *renderer) target(name string) string {
+	if r.opts.SinglePage {
+		return "#" + anchor(name)
+	}
+
+	if name == r.rootName {
+		return RootFileName
+	}
+
+	return name + ".md"
+}
+
+func (r *renderer) writeIndex(buf *bytes.Buffer) {
+	if len(r.defKeys) == 0 {
+		return
+	}
+
+	buf.WriteString("\n## Definitions\n\n")
+
+	for _, key := range r.defKeys {
+		name := r.defNames[key]
+		fmt.Fprintf(buf, "- [%s](%s)", name, r.target(name))
+
+		if summary := summary(r.defs[key]); summary != "" {
+			fmt.Fprintf(buf, ": %s", summary)
+		}
+
+		buf.WriteString("\n")
+	}
+}
+
+// goPathName returns the type name from the schema's x-go-path.
+func goPathName(s schema.JSONSchema) string {
+	path := goPath(s)
+	if path == "" {
+		return ""
+	}
+
+	if idx := strings.Index(path, "["); idx != -1 {
+		path = path[:idx]
+	}
+
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// goPath returns the x-go-path of an object schema.
+func goPath(s schema.JSONSchema) string {
+	if obj, ok := s.(schema.ObjectSchema); ok {
+		return obj.GetGoPath()
+	}
+
+	if path, ok := s.GetExtensions()["x-go-path"].(string); ok {
+		return path
+	}
+
+	return ""
+}
+
+// sourceType formats an x-go-path like github.com/example/pets/Pet the way GO refers to the type,
+// github.com/example/pets.Pet.
+func sourceType(path string) string {
+	base, args := path, ""
+	if idx := strings.Index(path, "["); idx != -1 {
+		base, args = path[:idx], path[idx:]
+	}
+
+	if idx := strings.LastIndex(base, "/"); idx != -1 {
+		base = base[:idx] + "." + base[idx+1:]
+	}
+
+	return base + args
+}
+
+// definitionName returns a page name for a definition key like github_com-example-pets-Pet.
+func definitionName(key string) string {
+	if idx := strings.Index(key, "["); idx != -1 {
+		key = key[:idx]
+	}
+
+	if name := key[strings.LastIndexAny(key, "-/")+1:]; name != "" {
+		return name
+	}
+
+	return "Definition"
+}
@@ -0,0 +1,179 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const storeSchema = `{
+	"type": "object",
+	"title": "Config for the store.",
+	"x-go-path": "github.com/acme/store/Config",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string", "default": "rex", "pattern": "^[a-z|]+$", "maxLength": 20, "description": "the name"},
+		"score": {"type": "number", "maximum": 10, "exclusiveMaximum": true, "multipleOf": 0.5, "default": "5"},
+		"nick": {"type": ["string", "null"]},
+		"born": {"type": "string", "format": "date-time"},
+		"pet": {"type": "object", "oneOf": [{"$ref": "#/definitions/github_com-acme-store-Cat"}, {"$ref": "#/definitions/github_com-acme-store-Dog"}]},
+		"counts": {"type": "object", "additionalProperties": {"type": "integer"}},
+		"friends": {"type": "array", "items": {"$ref": "#/definitions/github_com-acme-store-Cat"}, "minItems": 1},
+		"best": {"anyOf": [{"$ref": "#/definitions/github_com-acme-store-Dog"}, {"type": "null"}]},
+		"color": {"type": "string", "enum": ["red", "blue"], "x-enumDescriptions": ["fire", "sky"]},
+		"level": {"$ref": "#/definitions/Level"},
+		"home": {"type": "object", "required": ["zip"], "properties": {"zip": {"type": "string"}, "rooms": {"type": "array", "items": {"type": "object", "properties": {"size": {"type": "integer", "minimum": 1}}}}}},
+		"next": {"$ref": "#"}
+	},
+	"definitions": {
+		"github_com-acme-store-Cat": {"type": "object", "description": "A cat.\nLikes fish.", "properties": {"meow": {"type": "boolean"}, "claws": {"type": "integer"}}, "x-go-path": "github.com/acme/store/Cat"},
+		"github_com-acme-store-Dog": {"type": "object", "properties": {"bark": {"type": "boolean"}}, "x-go-path": "github.com/acme/store/Dog"},
+		"Level": {"type": "integer", "enum": [1, 2], "x-enumNames": ["low", "high"]}
+	}
+}`
+
+func renderStore(t *testing.T, opts Options) []Page {
+	pages, err := RenderJSON([]byte(storeSchema), opts)
+	assert.NoError(t, err)
+
+	return pages
+}
+
+func TestRenderPages(t *testing.T) {
+	t.Parallel()
+
+	pages := renderStore(t, Options{})
+
+	var files []string
+	for _, page := range pages {
+		files = append(files, page.FileName)
+	}
+	assert.Equal(t, []string{"README.md", "Cat.md", "Dog.md", "Level.md"}, files)
+
+	root := string(pages[0].Content)
+	assert.Contains(t, root, "# Config\n\nConfig for the store.\n")
+	assert.Contains(t, root, "- **Source type:** `github.com/acme/store.Config`\n")
+	assert.Contains(t, root, "| Name | Type | Required | Constraints | Default | Description |\n")
+
+	for _, row := range []string{
+		"| `name` | string | yes | maxLength: 20, pattern: `^[a-z\\|]+$` | `\"rex\"` | the name |",
+		"| `score` | number | no | maximum: 10 (exclusive), multipleOf: 0.5 | `5` |  |",
+		"| `nick` | string or null | no |  |  |  |",
+		"| `born` | string (date-time) | no |  |  |  |",
+		"| `pet` | one of [Cat](Cat.md), [Dog](Dog.md) | no |  |  |  |",
+		"| `counts` | map of integer | no |  |  |  |",
+		"| `friends` | array of [Cat](Cat.md) | no | minItems: 1 |  |  |",
+		"| `best` | [Dog](Dog.md) or null | no |  |  |  |",
+		"| `color` | string | no | enum: `\"red\"`, `\"blue\"` |  |  |",
+		"| `level` | [Level](Level.md) | no |  |  |  |",
+		"| `home.zip` | string | yes |  |  |  |",
+		"| `home.rooms[].size` | integer | no | minimum: 1 |  |  |",
+		"| `next` | [Config](README.md) | no |  |  |  |",
+	} {
+		assert.Contains(t, root, row+"\n")
+	}
+
+	assert.Contains(t, root, "### `pet` variants\n\nExactly one of:\n\n- [Cat](Cat.md): A cat.\n- [Dog](Dog.md)\n")
+	assert.Contains(t, root, "### `color` values\n\n| Value | Description |\n| --- | --- |\n| `\"red\"` | fire |\n")
+	assert.NotContains(t, root, "`best` variants")
+	assert.Contains(t, root, "## Definitions\n\n- [Cat](Cat.md): A cat.\n- [Dog](Dog.md)\n- [Level](Level.md)\n")
+
+	level := string(pages[3].Content)
+	assert.Contains(t, level, "- **Type:** integer\n- **Constraints:** enum: `1`, `2`\n")
+	assert.Contains(t, level, "## Values\n\n| Value | Name |\n| --- | --- |\n| `1` | low |\n| `2` | high |\n")
+}
+
+func TestRenderKeepsPropertyOrder(t *testing.T) {
+	t.Parallel()
+
+	pages := renderStore(t, Options{})
+
+	cat := string(pages[1].Content)
+	assert.Regexp(t, "(?s)`meow`.*`claws`", cat)
+
+	root := string(pages[0].Content)
+	assert.Regexp(t, "(?s)`name`.*`score`.*`home`.*`home.zip`.*`home.rooms`.*`next`", root)
+}
+
+func TestRenderSinglePage(t *testing.T) {
+	t.Parallel()
+
+	pages := renderStore(t, Options{SinglePage: true, RootName: "Store"})
+	assert.Len(t, pages, 1)
+
+	content := string(pages[0].Content)
+	assert.Contains(t, content, "# Store\n")
+	assert.Contains(t, content, "\n## Cat\n\nA cat.\nLikes fish.\n")
+	assert.Contains(t, content, "\n### Properties\n")
+	assert.Contains(t, content, "| `pet` | one of [Cat](#cat), [Dog](#dog) |")
+	assert.Contains(t, content, "| `next` | [Store](#store) |")
+	assert.NotContains(t, content, "## Definitions")
+}
+
+func TestRenderNames(t *testing.T) {
+	t.Parallel()
+
+	pages, err := RenderJSON([]byte(`{
+		"$ref": "#/definitions/a-Pet",
+		"definitions": {
+			"a-Pet": {"type": "object", "x-go-path": "github.com/acme/a/Pet"},
+			"b-Pet": {"type": "object", "x-go-path": "github.com/acme/b/Pet[github.com/acme/b/Cat]"},
+			"README": {"type": "string"}
+		}
+	}`), Options{})
+	assert.NoError(t, err)
+
+	var names []string
+	for _, page := range pages {
+		names = append(names, page.Name)
+	}
+	assert.Equal(t, []string{"Root", "Pet", "Pet2", "README2"}, names)
+
+	assert.Contains(t, string(pages[0].Content), "- **Type:** [Pet](Pet.md)\n")
+	assert.Contains(t, string(pages[2].Content), "- **Source type:** `github.com/acme/b.Pet[github.com/acme/b/Cat]`\n")
+}
+
+func TestRenderDraft202012(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := schema.FromJSON([]byte(storeSchema))
+	assert.NoError(t, err)
+
+	// the same schema written for 2020-12 refers to its definitions through $defs
+	converted, err := schema.MarshalForVersion(parsed, schema.SpecVersionDraft202012)
+	assert.NoError(t, err)
+	assert.Contains(t, string(converted), `"$ref":"#/$defs/github_com-acme-store-Cat"`)
+
+	pages, err := RenderJSON(converted, Options{})
+	assert.NoError(t, err)
+
+	root := string(pages[0].Content)
+	assert.Contains(t, root, "| `pet` | one of [Cat](Cat.md), [Dog](Dog.md) | no |  |  |  |\n")
+	assert.Contains(t, root, "| `friends` | array of [Cat](Cat.md) | no | minItems: 1 |  |  |\n")
+	assert.Contains(t, root, "| `level` | [Level](Level.md) | no |  |  |  |\n")
+	assert.Contains(t, root, "### `pet` variants\n\nExactly one of:\n\n- [Cat](Cat.md): A cat.\n- [Dog](Dog.md)\n")
+	assert.Contains(t, root, "## Definitions\n\n- [Cat](Cat.md): A cat.\n- [Dog](Dog.md)\n- [Level](Level.md)\n")
+
+	_, err = RenderJSON([]byte(`{"type": "object", "properties": {"pet": {"$ref": "#/$defs/Pet"}}}`), Options{})
+	assert.EqualError(t, err, "unresolvable $ref #/$defs/Pet")
+}
+
+func TestRenderUnresolvableRef(t *testing.T) {
+	t.Parallel()
+
+	_, err := RenderJSON([]byte(`{"type": "object", "properties": {"pet": {"$ref": "#/definitions/Pet"}}}`), Options{})
+	assert.EqualError(t, err, "unresolvable $ref #/definitions/Pet")
+}
+
+func TestRenderBooleanSchemas(t *testing.T) {
+	t.Parallel()
+
+	pages, err := RenderJSON([]byte(`{"type": "object", "properties": {"anything": true, "never": false}}`), Options{})
+	assert.NoError(t, err)
+
+	root := string(pages[0].Content)
+	assert.Contains(t, root, "| `anything` | any | no |  |  |  |\n")
+	assert.Contains(t, root, "| `never` | nothing | no |  |  |  |\n")
+}
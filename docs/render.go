@@ -0,0 +1,623 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// cellEscaper escapes text for a table cell. GFM tables split on pipes even inside code spans.
+var cellEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// row is a line of a property table. Properties of inline objects get a row of their own under a
+// dotted name.
+type row struct {
+	name     string
+	schema   schema.JSONSchema
+	required bool
+}
+
+func (r *renderer) writeDefinition(buf *bytes.Buffer, name string, s schema.JSONSchema, level int) {
+	fmt.Fprintf(buf, "%s %s\n", heading(level), name)
+
+	if s.GetTitle() != "" {
+		fmt.Fprintf(buf, "\n%s\n", s.GetTitle())
+	}
+
+	if s.GetDescription() != "" && s.GetDescription() != s.GetTitle() {
+		fmt.Fprintf(buf, "\n%s\n", s.GetDescription())
+	}
+
+	buf.WriteString("\n")
+
+	if path := goPath(s); path != "" {
+		fmt.Fprintf(buf, "- **Source type:** %s\n", code(sourceType(path)))
+	}
+
+	fmt.Fprintf(buf, "- **Type:** %s\n", r.typeText(s))
+
+	if c := constraints(s); len(c) > 0 {
+		fmt.Fprintf(buf, "- **Constraints:** %s\n", strings.Join(c, ", "))
+	}
+
+	if def := defaultText(s); def != "" {
+		fmt.Fprintf(buf, "- **Default:** %s\n", def)
+	}
+
+	if len(s.GetEnum()) > 0 {
+		fmt.Fprintf(buf, "\n%s Values\n\n", heading(level+1))
+		writeValues(buf, s)
+	}
+
+	if variants, intro := variantsOf(s); len(variants) > 0 {
+		fmt.Fprintf(buf, "\n%s Variants\n\n", heading(level+1))
+		r.writeVariants(buf, variants, intro)
+	}
+
+	rows := r.rows(s, "")
+	if len(rows) == 0 {
+		return
+	}
+
+	fmt.Fprintf(buf, "\n%s Properties\n\n", heading(level+1))
+	buf.WriteString("| Name | Type | Required | Constraints | Default | Description |\n")
+	buf.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+
+	for _, rw := range rows {
+		required := "no"
+		if rw.required {
+			required = "yes"
+		}
+
+		cells := []string{
+			rw.name,
+			r.typeText(rw.schema),
+			required,
+			strings.Join(constraints(rw.schema), ", "),
+			defaultText(rw.schema),
+			description(rw.schema),
+		}
+
+		for i, c := range cells {
+			cells[i] = cellEscaper.Replace(c)
+		}
+
+		fmt.Fprintf(buf, "| %s |\n", strings.Join(cells, " | "))
+	}
+
+	// enums with names or descriptions and unions get a section of their own below the table
+	for _, rw := range rows {
+		if len(rw.schema.GetEnumNames()) > 0 || len(rw.schema.GetEnumDescriptions()) > 0 {
+			fmt.Fprintf(buf, "\n%s %s values\n\n", heading(level+2), rw.name)
+			writeValues(buf, rw.schema)
+		}
+
+		if variants, intro := variantsOf(rw.schema); len(variants) > 0 {
+			fmt.Fprintf(buf, "\n%s %s variants\n\n", heading(level+2), rw.name)
+			r.writeVariants(buf, variants, intro)
+		}
+	}
+}
+
+// rows returns the table rows for the properties of an object in declaration order, followed by
+// its pattern properties and additional properties.
+func (r *renderer) rows(s schema.JSONSchema, prefix string) []row {
+	obj, ok := s.(schema.ObjectSchema)
+	if !ok || s.GetRef() != "" {
+		return nil
+	}
+
+	required := make(map[string]bool)
+	for _, name := range obj.GetRequired() {
+		required[name] = true
+	}
+
+	var rows []row
+
+	props := obj.GetProperties()
+	for _, name := range obj.GetPropertyOrder() {
+		rows = append(rows, row{name: code(prefix + name), schema: props[name], required: required[name]})
+		rows = append(rows, r.nestedRows(props[name], prefix+name)...)
+	}
+
+	patterns := obj.GetPatternProperties()
+	keys := make([]string, 0, len(patterns))
+	for pattern := range patterns {
+		keys = append(keys, pattern)
+	}
+	sort.Strings(keys)
+
+	for _, pattern := range keys {
+		rows = append(rows, row{name: prefix + "names matching " + code(pattern), schema: patterns[pattern]})
+	}
+
+	// a map's value type is already the type of the map itself
+	if ap := obj.GetAdditionalProperties(); ap != nil && ap.Schema != nil && len(rows) > 0 {
+		rows = append(rows, row{name: prefix + "any other name", schema: ap.Schema})
+	}
+
+	return rows
+}
+
+// nestedRows returns the rows for the properties of an inline object, or of the inline objects in an
+// array.
+func (r *renderer) nestedRows(s schema.JSONSchema, path string) []row {
+	if s == nil {
+		return nil
+	}
+
+	if arr, ok := s.(schema.ArraySchema); ok && s.GetRef() == "" {
+		return r.nestedRows(arr.GetItems(), path+"[]")
+	}
+
+	if obj, ok := s.(schema.ObjectSchema); ok && len(obj.GetProperties()) > 0 {
+		return r.rows(s, path+".")
+	}
+
+	return nil
+}
+
+// typeText describes the type of a schema, with refs as links.
+func (r *renderer) typeText(s schema.JSONSchema) string {
+	if s == nil {
+		return "any"
+	}
+
+	if b, ok := s.(schema.BooleanSchema); ok {
+		if b.GetBoolean() {
+			return "any"
+		}
+		return "nothing"
+	}
+
+	if ref := s.GetRef(); ref != "" {
+		return r.link(ref)
+	}
+
+	if inner, nullable := unwrapNullable(s); inner != s {
+		if nullable {
+			return r.typeText(inner) + " or null"
+		}
+		return r.typeText(inner)
+	}
+
+	if oneOf := s.GetOneOf(); len(oneOf) > 0 {
+		return "one of " + r.typeList(oneOf)
+	}
+
+	if anyOf := s.GetAnyOf(); len(anyOf) > 0 {
+		return "any of " + r.typeList(anyOf)
+	}
+
+	if allOf := s.GetAllOf(); len(allOf) > 0 {
+		return "all of " + r.typeList(allOf)
+	}
+
+	jsonTypes, nullable := typesOf(s)
+
+	parts := make([]string, 0, len(jsonTypes)+1)
+	for _, jsonType := range jsonTypes {
+		parts = append(parts, r.singleType(s, jsonType))
+	}
+
+	if nullable {
+		parts = append(parts, schema.SchemaTypeNull)
+	}
+
+	if len(parts) == 0 {
+		return "any"
+	}
+
+	return strings.Join(parts, " or ")
+}
+
+func (r *renderer) singleType(s schema.JSONSchema, jsonType string) string {
+	switch jsonType {
+	case schema.SchemaTypeArray:
+		if arr, ok := s.(schema.ArraySchema); ok && arr.GetItems() != nil {
+			return "array of " + r.typeText(arr.GetItems())
+		}
+
+	case schema.SchemaTypeObject:
+		if obj, ok := s.(schema.ObjectSchema); ok && len(obj.GetProperties()) == 0 {
+			if ap := obj.GetAdditionalProperties(); ap != nil && ap.Schema != nil {
+				return "map of " + r.typeText(ap.Schema)
+			}
+		}
+
+	default:
+		if simple, ok := s.(schema.SimpleSchema); ok && simple.GetFormat() != "" {
+			return fmt.Sprintf("%s (%s)", jsonType, simple.GetFormat())
+		}
+	}
+
+	return jsonType
+}
+
+func (r *renderer) typeList(branches []schema.JSONSchema) string {
+	types := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		types = append(types, r.typeText(branch))
+	}
+
+	return strings.Join(types, ", ")
+}
+
+func (r *renderer) writeVariants(buf *bytes.Buffer, variants []schema.JSONSchema, intro string) {
+	fmt.Fprintf(buf, "%s:\n\n", intro)
+
+	for _, variant := range variants {
+		fmt.Fprintf(buf, "- %s", r.typeText(variant))
+
+		described := variant
+		if resolved := r.resolve(variant.GetRef()); resolved != nil {
+			described = resolved
+		}
+
+		if summary := summary(described); summary != "" {
+			fmt.Fprintf(buf, ": %s", summary)
+		}
+
+		buf.WriteString("\n")
+	}
+}
+
+// resolve returns the root or definition a $ref points to, or nil.
+func (r *renderer) resolve(ref string) schema.JSONSchema {
+	if ref == "#" {
+		return r.root
+	}
+
+	if key, isDefinition := definitionKey(ref); isDefinition {
+		return r.defs[key]
+	}
+
+	return nil
+}
+
+// variantsOf returns the branches of a oneOf or anyOf along with how to introduce them. Refs that
+// are only made nullable by an anyOf aren't variants.
+func variantsOf(s schema.JSONSchema) ([]schema.JSONSchema, string) {
+	if s.GetRef() != "" {
+		return nil, ""
+	}
+
+	if inner, _ := unwrapNullable(s); inner != s {
+		return nil, ""
+	}
+
+	if len(s.GetOneOf()) > 0 {
+		return s.GetOneOf(), "Exactly one of"
+	}
+
+	if len(s.GetAnyOf()) > 0 {
+		return s.GetAnyOf(), "At least one of"
+	}
+
+	return nil, ""
+}
+
+// writeValues writes a table of the enum values with their names and descriptions.
+func writeValues(buf *bytes.Buffer, s schema.JSONSchema) {
+	names, descriptions := s.GetEnumNames(), s.GetEnumDescriptions()
+
+	header := []string{"Value"}
+	if len(names) > 0 {
+		header = append(header, "Name")
+	}
+	if len(descriptions) > 0 {
+		header = append(header, "Description")
+	}
+
+	fmt.Fprintf(buf, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(buf, "|%s\n", strings.Repeat(" --- |", len(header)))
+
+	for i, value := range s.GetEnum() {
+		cells := []string{code(jsonText(value))}
+		if len(names) > 0 {
+			cells = append(cells, listItem(names, i))
+		}
+		if len(descriptions) > 0 {
+			cells = append(cells, listItem(descriptions, i))
+		}
+
+		for i, c := range cells {
+			cells[i] = cellEscaper.Replace(c)
+		}
+
+		fmt.Fprintf(buf, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+// constraints returns the validation keywords of a schema that aren't part of its type.
+func constraints(s schema.JSONSchema) []string {
+	var c []string
+
+	if enum := s.GetEnum(); len(enum) > 0 {
+		values := make([]string, 0, len(enum))
+		for _, v := range enum {
+			values = append(values, code(jsonText(v)))
+		}
+		c = append(c, "enum: "+strings.Join(values, ", "))
+	}
+
+	if value, hasConst := s.GetConst(); hasConst {
+		c = append(c, "const: "+code(jsonText(value)))
+	}
+
+	if str, ok := s.(schema.StringSchema); ok {
+		if str.GetMinLength() > 0 {
+			c = append(c, "minLength: "+strconv.FormatInt(str.GetMinLength(), 10))
+		}
+		if str.GetMaxLength() > 0 {
+			c = append(c, "maxLength: "+strconv.FormatInt(str.GetMaxLength(), 10))
+		}
+		if str.GetPattern() != "" {
+			c = append(c, "pattern: "+code(str.GetPattern()))
+		}
+	}
+
+	if num, ok := s.(schema.NumericSchema); ok {
+		c = append(c, numericConstraints(num)...)
+	}
+
+	if arr, ok := s.(schema.ArraySchema); ok {
+		if arr.GetMinItems() > 0 {
+			c = append(c, "minItems: "+strconv.FormatInt(arr.GetMinItems(), 10))
+		}
+		if arr.GetMaxItems() > 0 {
+			c = append(c, "maxItems: "+strconv.FormatInt(arr.GetMaxItems(), 10))
+		}
+		if arr.GetUniqueItems() {
+			c = append(c, "uniqueItems")
+		}
+	}
+
+	if obj, ok := s.(schema.ObjectSchema); ok {
+		if obj.GetMinProperties() > 0 {
+			c = append(c, "minProperties: "+strconv.FormatInt(obj.GetMinProperties(), 10))
+		}
+		if obj.GetMaxProperties() > 0 {
+			c = append(c, "maxProperties: "+strconv.FormatInt(obj.GetMaxProperties(), 10))
+		}
+		if ap := obj.GetAdditionalProperties(); ap != nil && ap.Schema == nil && !ap.Boolean {
+			c = append(c, "additionalProperties: false")
+		}
+	}
+
+	return c
+}
+
+// numericConstraints returns the bounds of a number. A bound of 0 can't be told apart from a
+// missing one so it's left out, the same as the generator does.
+func numericConstraints(num schema.NumericSchema) []string {
+	var c []string
+
+	// draft-06 and newer exclusive limits are numbers of their own
+	exclusiveMin, hasExclusiveMin := num.GetExtensions()["exclusiveMinimum"].(float64)
+	exclusiveMax, hasExclusiveMax := num.GetExtensions()["exclusiveMaximum"].(float64)
+
+	switch {
+	case hasExclusiveMin:
+		c = append(c, "exclusiveMinimum: "+formatNumber(exclusiveMin))
+	case num.GetMinimum() != 0 && num.GetExclusiveMinimum():
+		c = append(c, "minimum: "+formatNumber(num.GetMinimum())+" (exclusive)")
+	case num.GetMinimum() != 0:
+		c = append(c, "minimum: "+formatNumber(num.GetMinimum()))
+	}
+
+	switch {
+	case hasExclusiveMax:
+		c = append(c, "exclusiveMaximum: "+formatNumber(exclusiveMax))
+	case num.GetMaximum() != 0 && num.GetExclusiveMaximum():
+		c = append(c, "maximum: "+formatNumber(num.GetMaximum())+" (exclusive)")
+	case num.GetMaximum() != 0:
+		c = append(c, "maximum: "+formatNumber(num.GetMaximum()))
+	}
+
+	if num.GetMultipleOf() != 0 {
+		c = append(c, "multipleOf: "+formatNumber(num.GetMultipleOf()))
+	}
+
+	return c
+}
+
+// defaultText returns the default as a code span. The generator writes defaults from annotations as
+// strings, so a string default of a schema that isn't a string is shown as is.
+func defaultText(s schema.JSONSchema) string {
+	def := s.GetDefault()
+	if def == nil {
+		return ""
+	}
+
+	if str, ok := def.(string); ok {
+		jsonTypes, _ := typesOf(s)
+		if len(jsonTypes) > 0 && !contains(jsonTypes, schema.SchemaTypeString) {
+			return code(str)
+		}
+	}
+
+	return code(jsonText(def))
+}
+
+// description returns the description of a schema, or its title if it has none.
+func description(s schema.JSONSchema) string {
+	if s.GetDescription() != "" {
+		return s.GetDescription()
+	}
+
+	return s.GetTitle()
+}
+
+// summary returns the first line of the description of a schema.
+func summary(s schema.JSONSchema) string {
+	return strings.TrimSpace(strings.SplitN(description(s), "\n", 2)[0])
+}
+
+// subschemas returns the schemas directly nested in s.
+func subschemas(s schema.JSONSchema) []schema.JSONSchema {
+	var subs []schema.JSONSchema
+
+	subs = append(subs, s.GetAllOf()...)
+	subs = append(subs, s.GetAnyOf()...)
+	subs = append(subs, s.GetOneOf()...)
+	subs = append(subs, s.GetNot())
+	subs = appendSorted(subs, s.GetDefinitions())
+
+	if arr, ok := s.(schema.ArraySchema); ok {
+		subs = append(subs, arr.GetItems())
+	}
+
+	if obj, ok := s.(schema.ObjectSchema); ok {
+		subs = appendSorted(subs, obj.GetProperties())
+		subs = appendSorted(subs, obj.GetPatternProperties())
+		subs = appendSorted(subs, obj.GetDependentSchemas())
+		subs = append(subs, obj.GetPropertyNames())
+
+		if ap := obj.GetAdditionalProperties(); ap != nil {
+			subs = append(subs, ap.Schema)
+		}
+	}
+
+	return subs
+}
+
+func appendSorted(subs []schema.JSONSchema, schemas map[string]schema.JSONSchema) []schema.JSONSchema {
+	keys := make([]string, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		subs = append(subs, schemas[key])
+	}
+
+	return subs
+}
+
+// typesOf returns the JSON types of a schema other than null, along with true if it accepts null.
+func typesOf(s schema.JSONSchema) ([]string, bool) {
+	soa := s.GetType()
+
+	if soa == nil {
+		return nil, s.GetNullable()
+	}
+
+	all := soa.Array
+	if len(all) == 0 && soa.String != "" {
+		all = []string{soa.String}
+	}
+
+	var jsonTypes []string
+	nullable := s.GetNullable()
+
+	for _, t := range all {
+		if t == schema.SchemaTypeNull {
+			nullable = true
+			continue
+		}
+		jsonTypes = append(jsonTypes, t)
+	}
+
+	return jsonTypes, nullable
+}
+
+// unwrapNullable returns the schema that's made nullable by s along with true if s accepts null.
+// The generator wraps nullable refs in an anyOf with a null schema or, for OpenAPI, in an allOf.
+func unwrapNullable(s schema.JSONSchema) (schema.JSONSchema, bool) {
+	_, nullable := typesOf(s)
+
+	if s.GetType() != nil || s.GetRef() != "" {
+		return s, nullable
+	}
+
+	if nullable && len(s.GetAllOf()) == 1 && len(s.GetAnyOf()) == 0 && len(s.GetOneOf()) == 0 {
+		return s.GetAllOf()[0], true
+	}
+
+	for _, branches := range [][]schema.JSONSchema{s.GetAnyOf(), s.GetOneOf()} {
+		if len(branches) != 2 || len(s.GetAllOf()) > 0 {
+			continue
+		}
+
+		for i, branch := range branches {
+			if isNullSchema(branch) {
+				return branches[1-i], true
+			}
+		}
+	}
+
+	return s, nullable
+}
+
+func isNullSchema(s schema.JSONSchema) bool {
+	soa := s.GetType()
+
+	return soa != nil && (soa.String == schema.SchemaTypeNull || (len(soa.Array) == 1 && soa.Array[0] == schema.SchemaTypeNull))
+}
+
+func heading(level int) string {
+	return strings.Repeat("#", level)
+}
+
+// code returns text as a Markdown code span.
+func code(text string) string {
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+
+	return "`" + text + "`"
+}
+
+// anchor returns the anchor GitHub generates for a heading.
+func anchor(text string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func jsonText(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func listItem(items []string, i int) string {
+	if i < len(items) {
+		return items[i]
+	}
+
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,8 +1,10 @@
 package generator
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/brainicorn/ganno"
 )
@@ -17,16 +19,26 @@ type boolOrPath struct {
 	path    string
 }
 
+type dependency struct {
+	properties []string
+	path       string
+}
+
 type schemaAnno struct {
 	attrs map[string][]string
 
-	required             bool
+	required             *bool
+	root                 bool
+	filename             string
+	nullable             *bool
 	id                   string
 	description          string
 	definition           string
 	format               string
 	title                string
 	defaultValue         string
+	enum                 []string
+	constValue           *string
 	maximum              float64
 	exclusiveMaximum     bool
 	minimum              float64
@@ -47,10 +59,8 @@ type schemaAnno struct {
 	not                  string
 	additionalProperties *boolOrPath
 	additionalItems      bool
-
-	// TODO implement these somehow, maybe??
-	//PatternProperties    ???
-	//Dependencies         ???
+	patternProperties    map[string]string
+	dependencies         map[string]*dependency
 }
 
 func (a *schemaAnno) AnnotationName() string {
@@ -71,6 +81,9 @@ func (f *schemaAnnoFactory) ValidateAndCreate(name string, attrs map[string][]st
 		anyOf:      make([]string, 0),
 		oneOf:      make([]string, 0),
 		schemaType: make([]string, 0),
+
+		patternProperties: make(map[string]string),
+		dependencies:      make(map[string]*dependency),
 	}
 
 	for k, v := range attrs {
@@ -80,7 +93,24 @@ func (f *schemaAnnoFactory) ValidateAndCreate(name string, attrs map[string][]st
 			if err != nil {
 				return nil, fmt.Errorf("error setting @jsonSchema 'required': %s", err)
 			}
-			anno.required = b
+			anno.required = &b
+		case "nullable":
+			b, err := strconv.ParseBool(v[0])
+			if err != nil {
+				return nil, fmt.Errorf("error setting @jsonSchema 'nullable': %s", err)
+			}
+			anno.nullable = &b
+		case "root":
+			b, err := strconv.ParseBool(v[0])
+			if err != nil {
+				return nil, fmt.Errorf("error setting @jsonSchema 'root': %s", err)
+			}
+			anno.root = b
+		case "filename":
+			if v[0] == "" || strings.ContainsAny(v[0], `/\`) {
+				return nil, fmt.Errorf("error setting @jsonSchema 'filename': '%s' is not a plain file name", v[0])
+			}
+			anno.filename = v[0]
 		case "id":
 			if v[0] != "" {
 				anno.id = v[0]
@@ -111,6 +141,13 @@ func (f *schemaAnnoFactory) ValidateAndCreate(name string, attrs map[string][]st
 				anno.defaultValue = v[0]
 			}
 
+		case "enum":
+			anno.enum = append(anno.enum, v...)
+
+		case "const":
+			constValue := v[0]
+			anno.constValue = &constValue
+
 		case "maximum":
 			f, err := strconv.ParseFloat(v[0], 64)
 
@@ -259,6 +296,30 @@ func (f *schemaAnnoFactory) ValidateAndCreate(name string, attrs map[string][]st
 				return nil, fmt.Errorf("error setting @jsonSchema 'additionalProperties': %s", "must be a bool, jsonType, or typePath")
 			}
 
+		case "patternproperties":
+			for _, item := range v {
+				props, err := parsePatternProperties(item)
+				if err != nil {
+					return nil, fmt.Errorf("error setting @jsonSchema 'patternProperties': %s", err)
+				}
+
+				for pattern, path := range props {
+					anno.patternProperties[pattern] = path
+				}
+			}
+
+		case "dependencies":
+			for _, item := range v {
+				deps, err := parseDependencies(item)
+				if err != nil {
+					return nil, fmt.Errorf("error setting @jsonSchema 'dependencies': %s", err)
+				}
+
+				for prop, dep := range deps {
+					anno.dependencies[prop] = dep
+				}
+			}
+
 		case "additionalitems":
 			b, err := strconv.ParseBool(v[0])
 			if err != nil {
@@ -273,3 +334,105 @@ func (f *schemaAnnoFactory) ValidateAndCreate(name string, attrs map[string][]st
 
 	return anno, nil
 }
+
+// parsePatternProperties parses a single patternProperties entry which is either a "regex=typePath"
+// pair or a JSON object string mapping regexes to type paths.
+func parsePatternProperties(item string) (map[string]string, error) {
+	props := make(map[string]string)
+
+	if strings.HasPrefix(strings.TrimSpace(item), "{") {
+		if err := json.Unmarshal([]byte(item), &props); err != nil {
+			return nil, err
+		}
+	} else {
+		idx := strings.LastIndex(item, "=")
+		if idx < 1 {
+			return nil, fmt.Errorf("'%s' must be in the form regex=typePath", item)
+		}
+
+		props[item[:idx]] = strings.TrimSpace(item[idx+1:])
+	}
+
+	for pattern, path := range props {
+		if pattern == "" {
+			return nil, fmt.Errorf("pattern for '%s' must not be empty", path)
+		}
+
+		if !isSelfRef(path) && !isIdent(path) && !isPackageType(path) {
+			return nil, fmt.Errorf("'%s' is not a valid ident or type selector", path)
+		}
+	}
+
+	return props, nil
+}
+
+// parseDependencies parses a single dependencies entry which is either a "property=dep1,dep2" /
+// "property=typePath" pair or a JSON object string mapping property names to either form.
+func parseDependencies(item string) (map[string]*dependency, error) {
+	deps := make(map[string]*dependency)
+
+	if strings.HasPrefix(strings.TrimSpace(item), "{") {
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(item), &raw); err != nil {
+			return nil, err
+		}
+
+		for prop, rv := range raw {
+			switch depValue := rv.(type) {
+			case string:
+				dep, err := parseDependencyValue(depValue)
+				if err != nil {
+					return nil, err
+				}
+				deps[prop] = dep
+			case []interface{}:
+				dep := &dependency{}
+				for _, dv := range depValue {
+					name, ok := dv.(string)
+					if !ok {
+						return nil, fmt.Errorf("dependency '%v' for '%s' is not a property name", dv, prop)
+					}
+					dep.properties = append(dep.properties, name)
+				}
+				deps[prop] = dep
+			default:
+				return nil, fmt.Errorf("dependency for '%s' must be an array of property names or a type", prop)
+			}
+		}
+
+		return deps, nil
+	}
+
+	idx := strings.Index(item, "=")
+	if idx < 1 {
+		return nil, fmt.Errorf("'%s' must be in the form property=dependencies", item)
+	}
+
+	dep, err := parseDependencyValue(item[idx+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	deps[strings.TrimSpace(item[:idx])] = dep
+
+	return deps, nil
+}
+
+func parseDependencyValue(value string) (*dependency, error) {
+	value = strings.TrimSpace(value)
+
+	if isSelfRef(value) || isPackageType(value) {
+		return &dependency{path: value}, nil
+	}
+
+	dep := &dependency{}
+	for _, prop := range strings.Split(value, ",") {
+		prop = strings.TrimSpace(prop)
+		if prop == "" {
+			return nil, fmt.Errorf("'%s' contains an empty property name", value)
+		}
+		dep.properties = append(dep.properties, prop)
+	}
+
+	return dep, nil
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const rgbSize = 3
+
+type ArrayRGB [rgbSize]uint8
+
+type ArrayHolder struct {
+	Coordinates [2]float64   `json:"coordinates"`
+	Color       ArrayRGB     `json:"color"`
+	Checksum    [4]byte      `json:"checksum"`
+	Data        []byte       `json:"data"`
+	Path        [][3]float64 `json:"path"`
+	Grid        [2][2]int    `json:"grid"`
+	Tags        []string     `json:"tags"`
+	// @jsonSchema(minItems=1)
+	Corners [4]float64 `json:"corners"`
+}
+
+func TestFixedSizeArrays(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.AutoCreateDefs = false
+
+	jsonSchema, err := GenerateIt(pkg, "ArrayHolder", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	assertArrayLength := func(sch schema.JSONSchema, min, max int64) schema.JSONSchema {
+		array := sch.(schema.ArraySchema)
+		assert.Equal(t, min, array.GetMinItems())
+		assert.Equal(t, max, array.GetMaxItems())
+
+		return array.GetItems()
+	}
+
+	items := assertArrayLength(props["coordinates"], 2, 2)
+	assert.Equal(t, schema.SchemaTypeNumber, items.GetType().String)
+
+	// the length is evaluated through the type checker
+	items = assertArrayLength(props["color"], 3, 3)
+	assert.Equal(t, schema.SchemaTypeInteger, items.GetType().String)
+
+	// encoding/json only base64 encodes byte slices
+	items = assertArrayLength(props["checksum"], 4, 4)
+	assert.Equal(t, schema.SchemaTypeInteger, items.GetType().String)
+	assert.Equal(t, schema.SchemaTypeString, props["data"].GetType().String)
+
+	points := assertArrayLength(props["path"], 0, 0)
+	items = assertArrayLength(points, 3, 3)
+	assert.Equal(t, schema.SchemaTypeNumber, items.GetType().String)
+
+	rows := assertArrayLength(props["grid"], 2, 2)
+	assertArrayLength(rows, 2, 2)
+
+	assertArrayLength(props["tags"], 0, 0)
+
+	// annotations override the length
+	assertArrayLength(props["corners"], 1, 4)
+}
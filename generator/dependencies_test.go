@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type DependencyBilling struct {
+	BillingAddress string `json:"billingAddress"`
+}
+
+// @jsonSchema(dependencies=["cardNumber=expiry,cvv", "billing=github.com/brainicorn/jsonschemagen/generator/DependencyBilling"])
+type DependencyPayment struct {
+	CardNumber string `json:"cardNumber"`
+	Expiry     string `json:"expiry"`
+	CVV        string `json:"cvv"`
+	Billing    bool   `json:"billing"`
+}
+
+func TestDependenciesDraft4(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "DependencyPayment", opts)
+
+	assert.NoError(t, err)
+
+	obj := jsonSchema.(schema.ObjectSchema)
+	deps := obj.GetDependencies()
+
+	assert.Equal(t, []string{"expiry", "cvv"}, deps["cardNumber"].Array)
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-DependencyBilling", deps["billing"].Schema.GetRef())
+	assert.Nil(t, obj.GetDependentRequired())
+	assert.Nil(t, obj.GetDependentSchemas())
+}
+
+func TestDependenciesDraft202012(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.SpecVersion = schema.SpecVersionDraft202012
+
+	jsonSchema, err := GenerateIt(pkg, "DependencyPayment", opts)
+
+	assert.NoError(t, err)
+
+	obj := jsonSchema.(schema.ObjectSchema)
+
+	assert.Nil(t, obj.GetDependencies())
+	assert.Equal(t, []string{"expiry", "cvv"}, obj.GetDependentRequired()["cardNumber"])
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-DependencyBilling", obj.GetDependentSchemas()["billing"].GetRef())
+}
+
+func TestDependenciesJSONObject(t *testing.T) {
+	t.Parallel()
+
+	deps, err := parseDependencies(`{"a":["b","c"],"d":"github.com/x/y/Type"}`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, deps["a"].properties)
+	assert.Equal(t, "github.com/x/y/Type", deps["d"].path)
+}
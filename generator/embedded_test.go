@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type EmbeddedBase struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Created is when the base was created
+	Created string `json:"created"`
+}
+
+type EmbeddedAudit struct {
+	// Created is when the audit was created
+	Created string `json:"created"`
+	Updated string `json:"updated"`
+}
+
+type EmbeddedTagged struct {
+	Label string `json:"label"`
+}
+
+type embeddedHidden struct {
+	Secret string `json:"secret"`
+}
+
+type EmbeddedDeep struct {
+	EmbeddedAudit
+}
+
+type EmbeddedPet interface{}
+
+type EmbeddedLabel string
+
+type embeddedLabel string
+
+type EmbeddedOwner struct {
+	EmbeddedBase
+	*EmbeddedAudit
+	EmbeddedTagged `json:"tagged"`
+	embeddedHidden
+	EmbeddedPet
+	EmbeddedLabel
+	embeddedLabel
+
+	// Name on the outer struct hides EmbeddedBase.Name
+	Name string `json:"name"`
+}
+
+type EmbeddedTwice struct {
+	EmbeddedDeep
+	EmbeddedBase
+}
+
+type EmbeddedTieLeft struct {
+	// Value is the tagged value
+	Value string `json:"Value"`
+}
+
+type EmbeddedTieRight struct {
+	// Value is the untagged value
+	Value string
+}
+
+type EmbeddedTie struct {
+	EmbeddedTieLeft
+	EmbeddedTieRight
+}
+
+func TestEmbeddedVisibility(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "EmbeddedOwner", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	// created is on both EmbeddedBase and EmbeddedAudit at the same depth so it's dropped
+	assert.NotContains(t, props, "created")
+	assert.Contains(t, props, "id")
+	assert.Contains(t, props, "updated")
+	assert.Contains(t, props, "secret")
+	assert.Contains(t, props, "EmbeddedPet")
+	assert.Contains(t, props, "EmbeddedLabel")
+	assert.NotContains(t, props, "embeddedLabel")
+	assert.NotContains(t, props, "label")
+
+	tagged := props["tagged"]
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-EmbeddedTagged", tagged.GetRef())
+
+	assert.Equal(t, "Name on the outer struct hides EmbeddedBase.Name", props["name"].GetTitle())
+}
+
+func TestEmbeddedDepthPrecedence(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "EmbeddedTwice", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	// EmbeddedBase.Created is shallower than EmbeddedDeep.EmbeddedAudit.Created
+	assert.Equal(t, "Created is when the base was created", props["created"].GetTitle())
+}
+
+func TestEmbeddedTaggedTieBreak(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "EmbeddedTie", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	assert.Len(t, props, 1)
+	assert.Equal(t, "Value is the tagged value", props["Value"].GetTitle())
+}
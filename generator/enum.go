@@ -0,0 +1,646 @@
+package generator
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+	"golang.org/x/tools/go/packages"
+)
+
+// maxEvalDepth limits how many method calls deep we follow when statically evaluating String and
+// MarshalText methods.
+const maxEvalDepth = 4
+
+// enumConst is a constant declared with a named type.
+type enumConst struct {
+	name  string
+	value constant.Value
+	doc   string
+}
+
+// addEnumForDecl adds enum values for every constant declared with the decl's named type. If the
+// type implements encoding.TextMarshaler the schema becomes a string schema and the enum holds the
+// marshalled text. If the type implements fmt.Stringer the String forms are used as the enum names.
+// The returned schema should be used in place of the one passed in.
+func (g *JSONSchemaGenerator) addEnumForDecl(sch schema.JSONSchema, decl *declInfo) schema.JSONSchema {
+	named, consts := g.findEnumConsts(decl)
+
+	if len(consts) < 1 {
+		return sch
+	}
+
+	textMarshalFn := lookupMethod(named, "MarshalText")
+	if textMarshalFn != nil && !isMarshalMethod(textMarshalFn) {
+		textMarshalFn = nil
+	}
+
+	stringFn := lookupMethod(named, "String")
+	if stringFn != nil && !isStringerMethod(stringFn) {
+		stringFn = nil
+	}
+
+	values := make([]interface{}, 0, len(consts))
+	names := make([]string, 0, len(consts))
+	descs := make([]string, 0, len(consts))
+	hasDescs := false
+
+	for _, c := range consts {
+		values = append(values, constantToJSON(c.value))
+		names = append(names, c.name)
+		descs = append(descs, c.doc)
+
+		if c.doc != "" {
+			hasDescs = true
+		}
+	}
+
+	if textMarshalFn != nil {
+		g.LogVerboseF("%s implements encoding.TextMarshaler, using text values for enum\n", decl.typeSpec.Name.Name)
+		ss := schema.NewStringSchema()
+		if anno, err := g.findJSONSchemaAnnotationForDecl(decl); err == nil {
+			g.populateStringAttrs(ss, anno)
+		}
+		sch = ss
+
+		texts, ok := g.evalConstTexts(textMarshalFn, consts)
+		if !ok {
+			g.LogVerboseF("unable to statically evaluate MarshalText for %s, skipping enum\n", decl.typeSpec.Name.Name)
+			return sch
+		}
+
+		values = make([]interface{}, 0, len(texts))
+		for _, text := range texts {
+			values = append(values, text)
+		}
+	} else if stringFn != nil {
+		if texts, ok := g.evalConstTexts(stringFn, consts); ok {
+			names = texts
+		}
+	}
+
+	sch.SetEnum(values)
+
+	if !g.options.SupressXAttrs {
+		sch.SetEnumNames(names)
+
+		if hasDescs {
+			sch.SetEnumDescriptions(descs)
+		}
+	}
+
+	return sch
+}
+
+// findEnumConsts returns the named type for the decl along with the constants declared with that
+// type, in source order. Constants with duplicate values are only reported once.
+func (g *JSONSchemaGenerator) findEnumConsts(decl *declInfo) (*types.Named, []*enumConst) {
+	if len(decl.typeArgs) > 0 {
+		return nil, nil
+	}
+
+	named := namedTypeForDecl(decl)
+	if named == nil || named.TypeParams().Len() > 0 {
+		return nil, nil
+	}
+
+	scope := decl.pkg.Types.Scope()
+	typeName := named.Obj()
+
+	var found []*types.Const
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || c.Name() == "_" || !types.Identical(c.Type(), named) {
+			continue
+		}
+
+		// unexported constants of an exported type are usually sentinels such as a max value
+		if typeName.Exported() && !c.Exported() {
+			continue
+		}
+
+		found = append(found, c)
+	}
+
+	if len(found) < 1 {
+		return named, nil
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Pos() < found[j].Pos()
+	})
+
+	docs := constDocs(decl.pkg)
+	seen := make(map[string]bool)
+	consts := make([]*enumConst, 0, len(found))
+
+	for _, c := range found {
+		if seen[c.Val().ExactString()] {
+			continue
+		}
+		seen[c.Val().ExactString()] = true
+
+		consts = append(consts, &enumConst{
+			name:  c.Name(),
+			value: c.Val(),
+			doc:   docs[c.Pos()],
+		})
+	}
+
+	return named, consts
+}
+
+// constDocs returns the doc comment of every constant in the package keyed by the position of its
+// name. Trailing line comments are used when there's no leading doc comment.
+func constDocs(pkg *packages.Package) map[token.Pos]string {
+	docs := make(map[token.Pos]string)
+
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST {
+				continue
+			}
+
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				cg := vs.Doc
+
+				if cg == nil && len(gd.Specs) == 1 {
+					cg = gd.Doc
+				}
+
+				if cg == nil {
+					cg = vs.Comment
+				}
+
+				if cg == nil {
+					continue
+				}
+
+				for _, name := range vs.Names {
+					docs[name.Pos()] = strings.TrimSpace(cg.Text())
+				}
+			}
+		}
+	}
+
+	return docs
+}
+
+func constantToJSON(val constant.Value) interface{} {
+	switch val.Kind() {
+	case constant.String:
+		return constant.StringVal(val)
+	case constant.Bool:
+		return constant.BoolVal(val)
+	case constant.Int:
+		if i, exact := constant.Int64Val(val); exact {
+			return i
+		}
+		u, _ := constant.Uint64Val(val)
+		return u
+	case constant.Float:
+		f, _ := constant.Float64Val(val)
+		return f
+	}
+
+	return val.ExactString()
+}
+
+func lookupMethod(named *types.Named, name string) *types.Func {
+	if named == nil {
+		return nil
+	}
+
+	sel := types.NewMethodSet(types.NewPointer(named)).Lookup(named.Obj().Pkg(), name)
+	if sel == nil {
+		return nil
+	}
+
+	fn, _ := sel.Obj().(*types.Func)
+
+	return fn
+}
+
+func isStringerMethod(fn *types.Func) bool {
+	sig := fn.Type().(*types.Signature)
+
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 && types.Identical(sig.Results().At(0).Type(), types.Typ[types.String])
+}
+
+// isMarshalMethod checks for the func() ([]byte, error) signature shared by MarshalJSON and
+// MarshalText.
+func isMarshalMethod(fn *types.Func) bool {
+	sig := fn.Type().(*types.Signature)
+
+	return sig.Params().Len() == 0 && sig.Results().Len() == 2 &&
+		types.Identical(sig.Results().At(0).Type(), types.NewSlice(types.Typ[types.Byte])) &&
+		types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type())
+}
+
+// evalConstTexts statically evaluates the given method for each constant. It only succeeds if
+// every constant could be evaluated.
+func (g *JSONSchemaGenerator) evalConstTexts(fn *types.Func, consts []*enumConst) ([]string, bool) {
+	texts := make([]string, 0, len(consts))
+
+	for _, c := range consts {
+		val, ok := g.evalMethod(fn, c.value, 0)
+		if !ok || val.Kind() != constant.String {
+			return nil, false
+		}
+
+		texts = append(texts, constant.StringVal(val))
+	}
+
+	return texts, true
+}
+
+// constEvaluator evaluates a method body with the receiver bound to a constant value. Only the
+// constructs commonly found in String methods are supported: returns, switches and ifs on the
+// receiver, constant expressions, conversions, slicing and lookups in package level array, slice
+// and map literals.
+type constEvaluator struct {
+	g     *JSONSchemaGenerator
+	pkg   *packages.Package
+	recv  types.Object
+	val   constant.Value
+	depth int
+}
+
+func (g *JSONSchemaGenerator) evalMethod(fn *types.Func, val constant.Value, depth int) (result constant.Value, ok bool) {
+	if depth > maxEvalDepth {
+		return nil, false
+	}
+
+	// methods that can't be loaded just aren't evaluated
+	pkg, err := g.program.PackageForTypes(fn.Pkg())
+	if err != nil {
+		return nil, false
+	}
+
+	fd := findFuncDecl(pkg, fn)
+	if fd == nil || fd.Body == nil || fd.Recv == nil || len(fd.Recv.List) < 1 {
+		return nil, false
+	}
+
+	ev := &constEvaluator{
+		g:     g,
+		pkg:   pkg,
+		val:   val,
+		depth: depth,
+	}
+
+	if len(fd.Recv.List[0].Names) > 0 {
+		ev.recv = pkg.TypesInfo.Defs[fd.Recv.List[0].Names[0]]
+	}
+
+	// go/constant panics on operations between mismatched kinds, which just means we can't
+	// evaluate this method.
+	defer func() {
+		if r := recover(); r != nil {
+			result, ok = nil, false
+		}
+	}()
+
+	result, returned, ok := ev.evalStmts(fd.Body.List)
+
+	return result, ok && returned
+}
+
+func findFuncDecl(pkg *packages.Package, fn *types.Func) *ast.FuncDecl {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && fd.Name.Pos() == fn.Pos() {
+				return fd
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ev *constEvaluator) evalStmts(stmts []ast.Stmt) (constant.Value, bool, bool) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.ReturnStmt:
+			if len(s.Results) < 1 {
+				return nil, false, false
+			}
+
+			val, ok := ev.evalExpr(s.Results[0])
+			return val, true, ok
+
+		case *ast.SwitchStmt:
+			val, returned, ok := ev.evalSwitch(s)
+			if !ok || returned {
+				return val, returned, ok
+			}
+
+		case *ast.IfStmt:
+			val, returned, ok := ev.evalIf(s)
+			if !ok || returned {
+				return val, returned, ok
+			}
+
+		case *ast.BlockStmt:
+			val, returned, ok := ev.evalStmts(s.List)
+			if !ok || returned {
+				return val, returned, ok
+			}
+
+		default:
+			return nil, false, false
+		}
+	}
+
+	return nil, false, true
+}
+
+func (ev *constEvaluator) evalSwitch(s *ast.SwitchStmt) (constant.Value, bool, bool) {
+	if s.Init != nil {
+		return nil, false, false
+	}
+
+	var tag constant.Value
+	if s.Tag != nil {
+		var ok bool
+		if tag, ok = ev.evalExpr(s.Tag); !ok {
+			return nil, false, false
+		}
+	}
+
+	var defaultClause *ast.CaseClause
+	for _, stmt := range s.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		if clause.List == nil {
+			defaultClause = clause
+			continue
+		}
+
+		for _, expr := range clause.List {
+			caseVal, ok := ev.evalExpr(expr)
+			if !ok {
+				return nil, false, false
+			}
+
+			matched := caseVal.Kind() == constant.Bool && constant.BoolVal(caseVal)
+			if tag != nil {
+				matched = constant.Compare(tag, token.EQL, caseVal)
+			}
+
+			if matched {
+				return ev.evalStmts(clause.Body)
+			}
+		}
+	}
+
+	if defaultClause != nil {
+		return ev.evalStmts(defaultClause.Body)
+	}
+
+	return nil, false, true
+}
+
+func (ev *constEvaluator) evalIf(s *ast.IfStmt) (constant.Value, bool, bool) {
+	if s.Init != nil {
+		return nil, false, false
+	}
+
+	cond, ok := ev.evalExpr(s.Cond)
+	if !ok || cond.Kind() != constant.Bool {
+		return nil, false, false
+	}
+
+	if constant.BoolVal(cond) {
+		return ev.evalStmts(s.Body.List)
+	}
+
+	if s.Else != nil {
+		return ev.evalStmts([]ast.Stmt{s.Else})
+	}
+
+	return nil, false, true
+}
+
+func (ev *constEvaluator) evalExpr(expr ast.Expr) (constant.Value, bool) {
+	expr = ast.Unparen(expr)
+	info := ev.pkg.TypesInfo
+
+	if tv, found := info.Types[expr]; found && tv.Value != nil {
+		return tv.Value, true
+	}
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if ev.recv != nil && info.Uses[e] == ev.recv {
+			return ev.val, true
+		}
+
+	case *ast.BinaryExpr:
+		x, ok := ev.evalExpr(e.X)
+		if !ok {
+			return nil, false
+		}
+
+		y, ok := ev.evalExpr(e.Y)
+		if !ok {
+			return nil, false
+		}
+
+		switch e.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, e.Op, y)), true
+		case token.SHL, token.SHR:
+			shift, _ := constant.Uint64Val(y)
+			return constant.Shift(x, e.Op, uint(shift)), true
+		case token.QUO:
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y), true
+			}
+		}
+
+		return constant.BinaryOp(x, e.Op, y), true
+
+	case *ast.UnaryExpr:
+		x, ok := ev.evalExpr(e.X)
+		if !ok {
+			return nil, false
+		}
+
+		return constant.UnaryOp(e.Op, x, 0), true
+
+	case *ast.CallExpr:
+		// conversions such as string(v) or []byte("text")
+		if tv, found := info.Types[e.Fun]; found && tv.IsType() && len(e.Args) == 1 {
+			arg, ok := ev.evalExpr(e.Args[0])
+			if !ok {
+				return nil, false
+			}
+
+			// string(int) produces a rune which is never what a String method wants
+			if basic, isBasic := tv.Type.Underlying().(*types.Basic); isBasic && basic.Info()&types.IsString != 0 && arg.Kind() != constant.String {
+				return nil, false
+			}
+
+			return arg, true
+		}
+
+		// calls to other methods on the receiver such as []byte(v.String())
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok && len(e.Args) == 0 {
+			if ident, ok := ast.Unparen(sel.X).(*ast.Ident); ok && ev.recv != nil && info.Uses[ident] == ev.recv {
+				if fn, ok := info.Uses[sel.Sel].(*types.Func); ok {
+					return ev.g.evalMethod(fn, ev.val, ev.depth+1)
+				}
+			}
+		}
+
+	case *ast.SliceExpr:
+		x, ok := ev.evalExpr(e.X)
+		if !ok || x.Kind() != constant.String || e.Slice3 {
+			return nil, false
+		}
+
+		str := constant.StringVal(x)
+		low, high := int64(0), int64(len(str))
+
+		if e.Low != nil {
+			if low, ok = ev.evalInt(e.Low); !ok {
+				return nil, false
+			}
+		}
+
+		if e.High != nil {
+			if high, ok = ev.evalInt(e.High); !ok {
+				return nil, false
+			}
+		}
+
+		if low < 0 || high > int64(len(str)) || low > high {
+			return nil, false
+		}
+
+		return constant.MakeString(str[low:high]), true
+
+	case *ast.IndexExpr:
+		index, ok := ev.evalExpr(e.Index)
+		if !ok {
+			return nil, false
+		}
+
+		return ev.evalLookup(e.X, index)
+	}
+
+	return nil, false
+}
+
+func (ev *constEvaluator) evalInt(expr ast.Expr) (int64, bool) {
+	val, ok := ev.evalExpr(expr)
+	if !ok || val.Kind() != constant.Int {
+		return 0, false
+	}
+
+	return constant.Int64Val(val)
+}
+
+// evalLookup finds the element for the given index in an array, slice or map literal which is
+// either inline or the value of a package level variable.
+func (ev *constEvaluator) evalLookup(expr ast.Expr, index constant.Value) (constant.Value, bool) {
+	lit := ev.compositeLit(expr)
+	if lit == nil {
+		return nil, false
+	}
+
+	litType := ev.pkg.TypesInfo.TypeOf(lit)
+	if litType == nil {
+		return nil, false
+	}
+
+	if _, isMap := litType.Underlying().(*types.Map); isMap {
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return nil, false
+			}
+
+			key, ok := ev.evalExpr(kv.Key)
+			if !ok {
+				return nil, false
+			}
+
+			if constant.Compare(key, token.EQL, index) {
+				return ev.evalExpr(kv.Value)
+			}
+		}
+
+		return nil, false
+	}
+
+	idx, exact := constant.Int64Val(constant.ToInt(index))
+	if !exact {
+		return nil, false
+	}
+
+	pos := int64(0)
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if pos, ok = ev.evalInt(kv.Key); !ok {
+				return nil, false
+			}
+			elt = kv.Value
+		}
+
+		if pos == idx {
+			return ev.evalExpr(elt)
+		}
+
+		pos++
+	}
+
+	return nil, false
+}
+
+func (ev *constEvaluator) compositeLit(expr ast.Expr) *ast.CompositeLit {
+	expr = ast.Unparen(expr)
+
+	if lit, ok := expr.(*ast.CompositeLit); ok {
+		return lit
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	obj, ok := ev.pkg.TypesInfo.Uses[ident].(*types.Var)
+	if !ok || obj.Pkg() != ev.pkg.Types || obj.Parent() != ev.pkg.Types.Scope() {
+		return nil
+	}
+
+	for _, file := range ev.pkg.Syntax {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if name.Pos() != obj.Pos() || i >= len(vs.Values) {
+						continue
+					}
+
+					lit, _ := ast.Unparen(vs.Values[i]).(*ast.CompositeLit)
+					return lit
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type EnumColor string
+
+const (
+	// EnumRed is the color of fire
+	EnumRed EnumColor = "red"
+	// EnumGreen is the color of grass
+	EnumGreen EnumColor = "green"
+	EnumBlue  EnumColor = "blue" // the color of the sky
+)
+
+type EnumLevel int
+
+const (
+	EnumLevelLow EnumLevel = iota
+	EnumLevelMedium
+	EnumLevelHigh
+
+	enumLevelMax
+)
+
+func (l EnumLevel) String() string {
+	switch l {
+	case EnumLevelLow:
+		return "low"
+	case EnumLevelMedium:
+		return "medium"
+	case EnumLevelHigh:
+		return "high"
+	}
+
+	return "unknown"
+}
+
+type EnumDay int
+
+const (
+	EnumMonday EnumDay = iota + 1
+	EnumTuesday
+)
+
+var enumDayNames = [...]string{EnumMonday: "mon", EnumTuesday: "tue"}
+
+func (d EnumDay) String() string {
+	if d < EnumMonday || d > EnumTuesday {
+		return ""
+	}
+
+	return enumDayNames[d]
+}
+
+func (d EnumDay) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+const _EnumStage_name = "draftpublishedarchived"
+
+var _EnumStage_index = [...]uint8{0, 5, 14, 22}
+
+type EnumStage int
+
+const (
+	EnumStageDraft EnumStage = iota
+	EnumStagePublished
+	EnumStageArchived
+)
+
+func (i EnumStage) String() string {
+	if i < 0 || i >= EnumStage(len(_EnumStage_index)-1) {
+		return "EnumStage(?)"
+	}
+	return _EnumStage_name[_EnumStage_index[i]:_EnumStage_index[i+1]]
+}
+
+type EnumHolder struct {
+	Color EnumColor `json:"color"`
+	Level EnumLevel `json:"level"`
+	Day   EnumDay   `json:"day"`
+	Stage EnumStage `json:"stage"`
+}
+
+func TestEnumDetection(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "EnumHolder", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	color := props["color"]
+	assert.Equal(t, []interface{}{"red", "green", "blue"}, color.GetEnum())
+	assert.Equal(t, []string{"EnumRed", "EnumGreen", "EnumBlue"}, color.GetEnumNames())
+	assert.Equal(t, []string{"EnumRed is the color of fire", "EnumGreen is the color of grass", "the color of the sky"}, color.GetEnumDescriptions())
+
+	level := props["level"]
+	assert.Equal(t, schema.SchemaTypeInteger, level.GetType().String)
+	assert.Equal(t, []interface{}{int64(0), int64(1), int64(2)}, level.GetEnum())
+	assert.Equal(t, []string{"low", "medium", "high"}, level.GetEnumNames())
+	assert.Nil(t, level.GetEnumDescriptions())
+
+	day := props["day"]
+	assert.Equal(t, schema.SchemaTypeString, day.GetType().String)
+	assert.Equal(t, []interface{}{"mon", "tue"}, day.GetEnum())
+	assert.Equal(t, []string{"EnumMonday", "EnumTuesday"}, day.GetEnumNames())
+
+	stage := props["stage"]
+	assert.Equal(t, []string{"draft", "published", "archived"}, stage.GetEnumNames())
+}
+
+func TestEnumRootSuppressXAttrs(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.SupressXAttrs = true
+
+	jsonSchema, err := GenerateIt(pkg, "EnumColor", opts)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"red", "green", "blue"}, jsonSchema.GetEnum())
+	assert.Nil(t, jsonSchema.GetEnumNames())
+	assert.Nil(t, jsonSchema.GetEnumDescriptions())
+}
+
+type ExplicitEnumHolder struct {
+	// @jsonSchema(enum=["small","large"])
+	Size string `json:"size"`
+
+	// @jsonSchema(enum=[1, 2, 3])
+	Count int `json:"count"`
+
+	// @jsonSchema(enum=["0.5", "null"])
+	Ratio float64 `json:"ratio"`
+
+	// @jsonSchema(const="v1")
+	Version string `json:"version"`
+
+	// @jsonSchema(const=false)
+	Deprecated bool `json:"deprecated"`
+
+	// @jsonSchema(enum=["red"])
+	Color EnumColor `json:"color"`
+}
+
+func TestExplicitEnumAndConst(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "ExplicitEnumHolder", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	assert.Equal(t, []interface{}{"small", "large"}, props["size"].GetEnum())
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(3)}, props["count"].GetEnum())
+	assert.Equal(t, []interface{}{0.5, nil}, props["ratio"].GetEnum())
+
+	// draft-04 has no const
+	_, hasConst := props["version"].GetConst()
+	assert.False(t, hasConst)
+	assert.Equal(t, []interface{}{"v1"}, props["version"].GetEnum())
+	assert.Equal(t, []interface{}{false}, props["deprecated"].GetEnum())
+
+	assert.Equal(t, []interface{}{"red"}, props["color"].GetEnum())
+	assert.Nil(t, props["color"].GetEnumNames())
+}
+
+func TestConstDraft07(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.SpecVersion = schema.SpecVersionDraft07
+
+	jsonSchema, err := GenerateIt(pkg, "ExplicitEnumHolder", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	version, hasConst := props["version"].GetConst()
+	assert.True(t, hasConst)
+	assert.Equal(t, "v1", version)
+	assert.Nil(t, props["version"].GetEnum())
+
+	deprecated, hasConst := props["deprecated"].GetConst()
+	assert.True(t, hasConst)
+	assert.Equal(t, false, deprecated)
+
+	b, err := json.Marshal(props["deprecated"])
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"const":false`)
+}
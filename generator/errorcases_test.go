@@ -9,12 +9,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
-	"golang.org/x/tools/go/loader"
 )
 
 type ErrorCaseTestSuite struct {
 	suite.Suite
-	program     *loader.Program
+	program     *program
 	options     Options
 	basePackage string
 }
@@ -102,6 +101,14 @@ type BadRequired struct {
 	SomeStrings []string
 }
 
+type BadNullable struct {
+	// @jsonSchema(nullable=sometimes)
+	Name *string
+}
+
+// @jsonSchema(root=true, filename="schemas/bad.json")
+type BadFilename struct{}
+
 // @jsonSchema(maxProperties=two)
 type BadMaxProps struct{}
 
@@ -111,6 +118,17 @@ type BadMinProps struct{}
 // @jsonSchema(additionalProperties=yup)
 type BadAdditionalProps struct{}
 
+// @jsonSchema(patternProperties=["^x-"])
+type BadPatternProps struct{}
+
+// @jsonSchema(dependencies=["noEqualsSign"])
+type BadDependencies struct{}
+
+type BadEnumValue struct {
+	// @jsonSchema(enum=["one", "two"])
+	Count int
+}
+
 // @jsonSchema(thisIsNotARealAttr=yup)
 type BadAnnoAttr struct{}
 
@@ -126,6 +144,12 @@ type BadAnyOf interface{}
 // @jsonSchema(oneOf=["!this is not a package type"])
 type BadOneOf interface{}
 
+// @jsonSchema(additionalProperties="github.com/brainicorn/jsonschemagen/generator/NoSuchType")
+type UnknownAdditionalPropsType struct{}
+
+// @jsonSchema(oneOf=["github.com/brainicorn/jsonschemagen/generator/NoSuchType"])
+type UnknownOneOfType interface{}
+
 type StringArray struct {
 	Aliases []string
 }
@@ -265,6 +289,26 @@ func (suite *ErrorCaseTestSuite) TestRequiredError() {
 	assert.Error(suite.T(), err)
 }
 
+func (suite *ErrorCaseTestSuite) TestNullableError() {
+	suite.T().Parallel()
+
+	generator := NewJSONSchemaGenerator(suite.basePackage, "BadNullable", suite.options)
+	generator.program = suite.program
+
+	_, err := generator.Generate()
+	assert.EqualError(suite.T(), err, `error parsing annotation for field Name: error setting @jsonSchema 'nullable': strconv.ParseBool: parsing "sometimes": invalid syntax`)
+}
+
+func (suite *ErrorCaseTestSuite) TestFilenameError() {
+	suite.T().Parallel()
+
+	generator := NewJSONSchemaGenerator(suite.basePackage, "BadFilename", suite.options)
+	generator.program = suite.program
+
+	_, err := generator.Generate()
+	assert.EqualError(suite.T(), err, "error parsing annotation for object BadFilename: error setting @jsonSchema 'filename': 'schemas/bad.json' is not a plain file name")
+}
+
 func (suite *ErrorCaseTestSuite) TestMaxPropsError() {
 	suite.T().Parallel()
 
@@ -295,6 +339,36 @@ func (suite *ErrorCaseTestSuite) TestAdditionalPropsError() {
 	assert.Error(suite.T(), err)
 }
 
+func (suite *ErrorCaseTestSuite) TestPatternPropsError() {
+	suite.T().Parallel()
+
+	generator := NewJSONSchemaGenerator(suite.basePackage, "BadPatternProps", suite.options)
+	generator.program = suite.program
+
+	_, err := generator.Generate()
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ErrorCaseTestSuite) TestDependenciesError() {
+	suite.T().Parallel()
+
+	generator := NewJSONSchemaGenerator(suite.basePackage, "BadDependencies", suite.options)
+	generator.program = suite.program
+
+	_, err := generator.Generate()
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ErrorCaseTestSuite) TestEnumValueError() {
+	suite.T().Parallel()
+
+	generator := NewJSONSchemaGenerator(suite.basePackage, "BadEnumValue", suite.options)
+	generator.program = suite.program
+
+	_, err := generator.Generate()
+	assert.Error(suite.T(), err)
+}
+
 func (suite *ErrorCaseTestSuite) TestAnnoAttrError() {
 	suite.T().Parallel()
 
@@ -345,6 +419,26 @@ func (suite *ErrorCaseTestSuite) TestOneOfError() {
 	assert.Error(suite.T(), err)
 }
 
+func (suite *ErrorCaseTestSuite) TestUnknownAdditionalPropsTypeError() {
+	suite.T().Parallel()
+
+	generator := NewJSONSchemaGenerator(suite.basePackage, "UnknownAdditionalPropsType", suite.options)
+	generator.program = suite.program
+
+	_, err := generator.Generate()
+	assert.EqualError(suite.T(), err, "could not find type 'NoSuchType' in package github.com/brainicorn/jsonschemagen/generator")
+}
+
+func (suite *ErrorCaseTestSuite) TestUnknownOneOfTypeError() {
+	suite.T().Parallel()
+
+	generator := NewJSONSchemaGenerator(suite.basePackage, "UnknownOneOfType", suite.options)
+	generator.program = suite.program
+
+	_, err := generator.Generate()
+	assert.EqualError(suite.T(), err, "error setting 'oneOf' for UnknownOneOfType: unable to generate schema for path 'github.com/brainicorn/jsonschemagen/generator/NoSuchType': could not find type 'NoSuchType' in package github.com/brainicorn/jsonschemagen/generator")
+}
+
 func (suite *ErrorCaseTestSuite) TestBadRootError() {
 	suite.T().Parallel()
 
@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+	"github.com/brainicorn/jsonschemagen/validate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ExampleAddress struct {
+	// @jsonSchema(required=true, pattern="^[0-9]{5}(-[0-9]{4})?$")
+	Zip string `json:"zip"`
+	// @jsonSchema(minLength=3, maxLength=5)
+	City string `json:"city"`
+}
+
+type ExampleCat struct {
+	// @jsonSchema(required=true)
+	Meow bool `json:"meow"`
+}
+
+type ExampleDog struct {
+	// @jsonSchema(required=true)
+	Bark bool `json:"bark"`
+}
+
+// @jsonSchema(oneOf=["github.com/brainicorn/jsonschemagen/generator/ExampleCat", "github.com/brainicorn/jsonschemagen/generator/ExampleDog"])
+type ExamplePet interface{}
+
+type ExampleHolder struct {
+	// @jsonSchema(required=true, default="rex")
+	Name string `json:"name"`
+	// @jsonSchema(required=true, format="email")
+	Email string `json:"email"`
+	// @jsonSchema(format="ipv4")
+	Address string    `json:"address"`
+	Born    time.Time `json:"born"`
+	// @jsonSchema(required=true, minimum=10, maximum=20, exclusiveMinimum=true)
+	Age int `json:"age"`
+	// @jsonSchema(minimum=0.5, maximum=1.5, multipleOf=0.25)
+	Ratio float64 `json:"ratio"`
+	// @jsonSchema(minItems=2, maxItems=4, uniqueItems=true)
+	Tags  []EnumColor       `json:"tags"`
+	Pet   ExamplePet        `json:"pet"`
+	Home  *ExampleAddress   `json:"home"`
+	Notes map[string]string `json:"notes"`
+	Next  *ExampleHolder    `json:"next"`
+}
+
+func generateExampleHolder(t *testing.T) schema.JSONSchema {
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "ExampleHolder", opts)
+	assert.NoError(t, err)
+
+	return jsonSchema
+}
+
+func TestExamplesAreValid(t *testing.T) {
+	t.Parallel()
+
+	jsonSchema := generateExampleHolder(t)
+
+	validator, err := validate.Compile(jsonSchema)
+	assert.NoError(t, err)
+
+	for seed := int64(0); seed < 25; seed++ {
+		for _, minimal := range []bool{false, true} {
+			example, err := schema.MarshalExample(jsonSchema, schema.ExampleOptions{Seed: seed, Minimal: minimal})
+			assert.NoError(t, err)
+
+			errs, err := validator.Validate(example)
+			assert.NoError(t, err)
+			assert.Empty(t, errs, string(example))
+		}
+	}
+}
+
+func TestExampleIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	jsonSchema := generateExampleHolder(t)
+
+	first, err := schema.MarshalExample(jsonSchema, schema.ExampleOptions{Seed: 7})
+	assert.NoError(t, err)
+
+	second, err := schema.MarshalExample(jsonSchema, schema.ExampleOptions{Seed: 7})
+	assert.NoError(t, err)
+
+	other, err := schema.MarshalExample(jsonSchema, schema.ExampleOptions{Seed: 8})
+	assert.NoError(t, err)
+
+	assert.Equal(t, string(first), string(second))
+	assert.NotEqual(t, string(first), string(other))
+}
+
+func TestExampleHonorsSchema(t *testing.T) {
+	t.Parallel()
+
+	jsonSchema := generateExampleHolder(t)
+
+	exampleBytes, err := schema.MarshalExample(jsonSchema, schema.ExampleOptions{})
+	assert.NoError(t, err)
+
+	var example map[string]interface{}
+	assert.NoError(t, json.Unmarshal(exampleBytes, &example))
+
+	assert.Equal(t, "rex", example["name"])
+	assert.Regexp(t, `^[a-z]+@example\.com$`, example["email"])
+	assert.Regexp(t, `^192\.0\.2\.[0-9]+$`, example["address"])
+
+	_, err = time.Parse(time.RFC3339, example["born"].(string))
+	assert.NoError(t, err)
+
+	// the first oneOf branch is ExampleCat
+	assert.Contains(t, example["pet"], "meow")
+
+	assert.Len(t, example["notes"], 1)
+
+	// next refers back to the root so it's left out
+	assert.NotContains(t, example, "next")
+
+	home := example["home"].(map[string]interface{})
+	assert.Regexp(t, `^[0-9]{5}(-[0-9]{4})?$`, home["zip"])
+}
+
+func TestMinimalExample(t *testing.T) {
+	t.Parallel()
+
+	jsonSchema := generateExampleHolder(t)
+
+	exampleBytes, err := schema.MarshalExample(jsonSchema, schema.ExampleOptions{Minimal: true})
+	assert.NoError(t, err)
+
+	var example map[string]interface{}
+	assert.NoError(t, json.Unmarshal(exampleBytes, &example))
+
+	assert.Len(t, example, 3)
+	assert.Contains(t, example, "name")
+	assert.Contains(t, example, "email")
+	assert.Contains(t, example, "age")
+}
@@ -3,14 +3,13 @@ package generator
 import (
 	"fmt"
 	"go/ast"
-	goparser "go/parser"
 	"go/types"
 	"strings"
 	"time"
 
 	"github.com/brainicorn/ganno"
 	"github.com/brainicorn/jsonschemagen/schema"
-	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/packages"
 )
 
 // Options holds the configuration options for the schema generator instance
@@ -28,25 +27,81 @@ type Options struct {
 	DefinitionPrefix string
 	// SupressXAttrs is a flag ti supress non-standard schema properties like x-*
 	SupressXAttrs bool
+	// RequiredPolicy determines which struct fields are marked as required. A required attribute in
+	// a field's @jsonSchema annotation always overrides the policy.
+	RequiredPolicy RequiredPolicy
+	// TypeMappings maps fully-qualified type paths like github.com/shopspring/decimal/Decimal to the
+	// schema to use wherever that type appears. Mappings take precedence over the type's own
+	// declaration and over the built-in handling of types like time.Time.
+	TypeMappings map[string]schema.JSONSchema
+	// NullablePointers determines if and how pointer fields are marked as accepting null. A nullable
+	// attribute in a field's @jsonSchema annotation always overrides it.
+	NullablePointers NullableStyle
+	// Parallelism is the number of roots GenerateAll generates at the same time. Values less than 2
+	// generate one root at a time.
+	Parallelism int
+	// PropertyOrder adds the position of each property within its struct to the property's schema
+	// for tools that ignore the order of keys. Properties are always written in declaration order.
+	PropertyOrder PropertyOrderStyle
 }
 
+// RequiredPolicy is an enum specifying how required fields are inferred
+type RequiredPolicy uint8
+
+const (
+	// RequiredAnnotationOnly only marks fields annotated with required=true as required.
+	RequiredAnnotationOnly RequiredPolicy = iota
+	// RequiredNotOmitEmpty marks fields as required unless their json tag has omitempty or omitzero.
+	RequiredNotOmitEmpty
+	// RequiredNotOmitEmptyOrPointer marks fields as required unless their json tag has omitempty or
+	// omitzero or they are pointers.
+	RequiredNotOmitEmptyOrPointer
+)
+
+// NullableStyle is an enum specifying how nullable fields are described
+type NullableStyle uint8
+
+const (
+	// NullableNone doesn't mark pointer fields as nullable.
+	NullableNone NullableStyle = iota
+	// NullableTypeArray adds "null" to the type of simple schemas, e.g. "type": ["string", "null"],
+	// and wraps refs in an anyOf with a null schema.
+	NullableTypeArray
+	// NullableOpenAPI uses the OpenAPI 3.0 "nullable": true keyword. Refs are wrapped in an allOf
+	// since OpenAPI ignores keywords next to a $ref.
+	NullableOpenAPI
+)
+
+// PropertyOrderStyle is an enum specifying which keyword holds the position of a property
+type PropertyOrderStyle uint8
+
+const (
+	// PropertyOrderNone doesn't add positions to properties.
+	PropertyOrderNone PropertyOrderStyle = iota
+	// PropertyOrderKeyword adds the propertyOrder keyword used by form generators like json-editor.
+	PropertyOrderKeyword
+	// PropertyOrderXOrder adds the x-order extension. It's left out if x- attributes are suppressed.
+	PropertyOrderXOrder
+)
+
 // JSONSchemaGenerator is the thing that generates schemas.
 // This should not be created manually, instead use NewJSONSchemaGenerator(...)
 type JSONSchemaGenerator struct {
-	basePackage      string
-	rootPackage      string
-	rootType         string
-	options          Options
-	program          *loader.Program
-	annoParser       ganno.AnnotationParser
-	globalDefCache   map[string]*definition
-	embeddedDefCache map[string]*definition
-	simpleTypeCache  map[string]*definition
-	fieldAnnoCache   map[*ast.Field]*schemaAnno
+	basePackage     string
+	rootPackage     string
+	rootType        string
+	options         Options
+	program         *program
+	annoParser      ganno.AnnotationParser
+	globalDefCache  map[string]*definition
+	simpleTypeCache map[string]*definition
+	fieldAnnoCache  map[*ast.Field]*schemaAnno
+	instanceDecls   map[string]*declInfo
+	typeMappings    map[string]schema.JSONSchema
 }
 
 type declInfo struct {
-	pkg              *loader.PackageInfo
+	pkg              *packages.Package
 	file             *ast.File
 	decl             *ast.GenDecl
 	typeSpec         *ast.TypeSpec
@@ -54,6 +109,14 @@ type declInfo struct {
 	schemaAnnotation *schemaAnno
 	parsedAnnos      bool
 	isRoot           bool
+	typeArgs         map[string]*typeArg
+}
+
+// typeArg is a type argument of a generic instantiation along with the decl whose scope the
+// argument expression needs to be resolved in.
+type typeArg struct {
+	expr  ast.Expr
+	owner *declInfo
 }
 
 type definition struct {
@@ -72,7 +135,7 @@ func NewOptions() Options {
 	}
 }
 
-func (g *JSONSchemaGenerator) newDeclInfo(pkg *loader.PackageInfo, file *ast.File, decl *ast.GenDecl, spec *ast.TypeSpec) *declInfo {
+func (g *JSONSchemaGenerator) newDeclInfo(pkg *packages.Package, file *ast.File, decl *ast.GenDecl, spec *ast.TypeSpec) *declInfo {
 	di := &declInfo{
 		pkg:      pkg,
 		file:     file,
@@ -90,30 +153,22 @@ func (g *JSONSchemaGenerator) defKeyFromPath(path string) string {
 		return ""
 	}
 
-	defKey := g.options.DefinitionPrefix + path
-	defKey = strings.Replace(defKey, ".", "_", -1)
-	defKey = strings.Replace(defKey, "/", "-", -1)
+	return pathToKey(g.options.DefinitionPrefix + path)
+}
+
+func pathToKey(path string) string {
+	key := strings.Replace(path, ".", "_", -1)
+	key = strings.Replace(key, "/", "-", -1)
 
-	return defKey
+	return key
 }
 
-func (g *JSONSchemaGenerator) loadProgram(basePackage string, options Options) (*loader.Program, error) {
+func (g *JSONSchemaGenerator) loadProgram(basePackage string, options Options) (*program, error) {
 	if g.program != nil {
 		return g.program, nil
 	}
 
-	var scan loader.Config
-
-	scan.ParserMode = goparser.ParseComments
-	scan.TypeCheckFuncBodies = func(path string) bool { return false }
-
-	if options.IncludeTests {
-		scan.ImportWithTests(basePackage)
-	} else {
-		scan.Import(basePackage)
-	}
-
-	return scan.Load()
+	return loadPackages(newPackagesConfig(options), basePackage)
 }
 
 // NewJSONSchemaGenerator creates an instance of the generator.
@@ -123,29 +178,35 @@ func NewJSONSchemaGenerator(basePackage, rootType string, options Options) *JSON
 	aparser := ganno.NewAnnotationParser()
 	aparser.RegisterFactory("jsonSchema", &schemaAnnoFactory{})
 
+	typeMappings := defaultTypeMappings(options)
+	for path, mapped := range options.TypeMappings {
+		typeMappings[path] = mapped
+	}
+
 	return &JSONSchemaGenerator{
-		basePackage:      basePackage,
-		rootType:         rootType,
-		options:          options,
-		annoParser:       aparser,
-		globalDefCache:   make(map[string]*definition),
-		embeddedDefCache: make(map[string]*definition),
-		simpleTypeCache:  make(map[string]*definition),
-		fieldAnnoCache:   make(map[*ast.Field]*schemaAnno),
+		basePackage:     basePackage,
+		rootType:        rootType,
+		options:         options,
+		annoParser:      aparser,
+		globalDefCache:  make(map[string]*definition),
+		simpleTypeCache: make(map[string]*definition),
+		fieldAnnoCache:  make(map[*ast.Field]*schemaAnno),
+		instanceDecls:   make(map[string]*declInfo),
+		typeMappings:    typeMappings,
 	}
 }
 
 // Generate is the main function that is used to generate a JSONSchema.
 func (g *JSONSchemaGenerator) Generate() (schema.JSONSchema, error) {
-	var program *loader.Program
+	var prog *program
 	var err error
 	var rootSchema schema.JSONSchema
 
 	start := time.Now()
-	program, err = g.loadProgram(g.basePackage, g.options)
+	prog, err = g.loadProgram(g.basePackage, g.options)
 
 	if err == nil {
-		g.program = program
+		g.program = prog
 
 		rootSchema, err = g.doGenerate()
 	}
@@ -162,7 +223,6 @@ func (g *JSONSchemaGenerator) SubGenerate(basePackage, rootType string) (schema.
 	g.basePackage = basePackage
 	g.rootType = rootType
 	g.globalDefCache = make(map[string]*definition)
-	g.embeddedDefCache = make(map[string]*definition)
 
 	start := time.Now()
 	rootSchema, err = g.doGenerate()
@@ -197,72 +257,62 @@ func (g *JSONSchemaGenerator) doGenerate() (schema.JSONSchema, error) {
 	return rootSchema, err
 }
 
-func (g *JSONSchemaGenerator) findRootDecl(program *loader.Program) (*declInfo, error) {
-	var searchPackages map[*types.Package]*loader.PackageInfo
-
+func (g *JSONSchemaGenerator) findRootDecl(prog *program) (*declInfo, error) {
 	g.LogDebug("looking for root object")
 
-	if baseInfo, ok := program.Imported[g.basePackage]; ok {
-		searchPackages = make(map[*types.Package]*loader.PackageInfo)
-		searchPackages[baseInfo.Pkg] = baseInfo
-	}
-
-	if searchPackages == nil {
-		for _, pi := range program.AllPackages {
-			if pi.Pkg.Path() == g.basePackage {
-				searchPackages = make(map[*types.Package]*loader.PackageInfo)
-				searchPackages[pi.Pkg] = pi
+	// instantiations of generic types can only be found once they've been encountered
+	if strings.Contains(g.rootType, "[") {
+		rootKey := pathToKey(g.basePackage + "/" + g.rootType)
+		for _, inst := range g.instanceDecls {
+			if pathToKey(g.typePathForDecl(inst)) == rootKey {
+				rd := *inst
+				rd.isRoot = true
+				return &rd, nil
 			}
 		}
 	}
 
+	var rootDecl *declInfo
+	pkg, err := prog.Package(g.basePackage)
+
+	if err != nil {
+		return nil, err
+	}
+
 	//let's find the file with the root object in it
-	for pkg, pkgInfo := range searchPackages {
-		g.LogVerbose("analyzing package: ", pkg.Path())
-
-		for _, file := range pkgInfo.Files {
-			for _, decl := range file.Decls {
-				gd, ok := decl.(*ast.GenDecl)
-				if !ok {
-					continue
-				}
-				for _, spc := range gd.Specs {
-					if ts, ok := spc.(*ast.TypeSpec); ok {
-						if ts.Name.Name == g.rootType {
-							g.LogVerboseF("found root decl %s: %#v\n", ts.Name.Name, gd)
-							rd := g.newDeclInfo(pkgInfo, file, gd, ts)
-							rd.isRoot = true
-							return rd, nil
-						}
-					}
-				}
-			}
+	g.LogVerbose("analyzing package: ", pkg.PkgPath)
+
+	forEachTypeSpec(pkg, func(file *ast.File, gd *ast.GenDecl, ts *ast.TypeSpec) bool {
+		if ts.Name.Name != g.rootType {
+			return true
 		}
+
+		g.LogVerboseF("found root decl %s: %#v\n", ts.Name.Name, gd)
+		rootDecl = g.newDeclInfo(pkg, file, gd, ts)
+		rootDecl.isRoot = true
+		return false
+	})
+
+	if rootDecl != nil {
+		return rootDecl, nil
 	}
 
 	return nil, fmt.Errorf("root not found")
 
 }
 
-func (g *JSONSchemaGenerator) generateObjectSchema(declInfo *declInfo, field *ast.Field, embedded bool, parentKey string) (schema.JSONSchema, error) {
+func (g *JSONSchemaGenerator) generateObjectSchema(declInfo *declInfo, field *ast.Field, parentKey string) (schema.JSONSchema, error) {
 	g.LogDebugF("processing object schema for struct %s\n", declInfo.defKey)
 
 	var err error
-	var defCache map[string]*definition
 
 	objectSchema := schema.NewObjectSchema(g.options.SupressXAttrs)
 
-	if !embedded {
-		defCache = g.globalDefCache
-	} else {
-		defCache = g.embeddedDefCache
-	}
-
 	// if we already have the schema...
-	if objDef, found := defCache[declInfo.defKey]; found {
+	if objDef, found := g.globalDefCache[declInfo.defKey]; found {
 
 		g.LogDebug("returning cached object schema for ", declInfo.defKey)
-		if !embedded && g.shouldReturnRef(declInfo) {
+		if g.shouldReturnRef(declInfo) {
 			refSchema := schema.NewBasicSchema("")
 			refSchema.SetRef(schema.DefinitionRoot + declInfo.defKey)
 			return refSchema, nil
@@ -274,7 +324,7 @@ func (g *JSONSchemaGenerator) generateObjectSchema(declInfo *declInfo, field *as
 
 	g.LogDebug("creating new object schema for struct ", declInfo.defKey)
 
-	objectSchema.SetGoPath(declInfo.pkg.Pkg.Path() + "/" + declInfo.typeSpec.Name.Name)
+	objectSchema.SetGoPath(g.typePathForDecl(declInfo))
 	if err != nil {
 		return nil, err
 	}
@@ -286,51 +336,48 @@ func (g *JSONSchemaGenerator) generateObjectSchema(declInfo *declInfo, field *as
 	}
 
 	props := make(map[string]schema.JSONSchema)
+	var order []string
 
-	for _, propField := range declInfo.typeSpec.Type.(*ast.StructType).Fields.List {
-
-		if len(propField.Names) == 0 {
-			g.LogVerbose("processing field without a name, must be embedded...")
-			embeddedSchema, e := g.generateEmbeddedSchema(declInfo, propField.Type, parentKey)
-
-			if e != nil {
-				err = e
-				break
-			}
-			for k, v := range embeddedSchema.(schema.ObjectSchema).GetProperties() {
-				props[k] = v
-			}
+	fields, err := g.collectStructFields(declInfo)
+	if err != nil {
+		return nil, err
+	}
 
-			for _, r := range embeddedSchema.(schema.ObjectSchema).GetRequired() {
-				objectSchema.AddRequiredField(r)
-			}
+	for _, sf := range g.dominantFields(fields, declInfo.typeSpec.Name.Name) {
+		g.LogVerboseF("processing field '%s' on struct %s\n", sf.field.Names[0].Name, sf.owner.typeSpec.Name.Name)
 
-		} else if propField.Names[0] != nil && propField.Names[0].IsExported() {
-			g.LogVerboseF("processing field '%s' on struct %s\n", propField.Names[0].Name, declInfo.typeSpec.Name.Name)
+		fschema, e := g.generateSchemaForExpr(sf.owner, sf.field.Type, sf.field, sf.owner.defKey)
 
-			propName, propIgnore := jsonTagInfo(propField)
+		if e != nil {
+			err = e
+			break
+		}
 
-			if propIgnore {
-				continue
-			}
+		if sf.tag.asString {
+			fschema = g.generateStringEncodedSchema(sf.owner, sf.field, fschema)
+		}
 
-			fschema, e := g.generateSchemaForExpr(declInfo, propField.Type, propField, declInfo.defKey)
+		if style := g.nullableStyleForField(sf.field); style != NullableNone {
+			fschema = makeNullable(fschema, style)
+		}
 
-			if e != nil {
-				err = e
-				break
-			}
+		if keyword := g.propertyOrderKeyword(); keyword != "" {
+			// property schemas can be shared, e.g. cached simple types, so the index goes on a copy
+			fschema = fschema.Clone()
+			fschema.SetExtension(keyword, len(order)+1)
+		}
 
-			props[propName] = fschema
+		props[sf.tag.name] = fschema
+		order = append(order, sf.tag.name)
 
-			if g.fieldIsRequired(propField) {
-				objectSchema.AddRequiredField(propName)
-			}
+		if g.fieldIsRequired(sf.field, sf.tag) {
+			objectSchema.AddRequiredField(sf.tag.name)
 		}
 	}
 
 	if err == nil {
 		objectSchema.SetProperties(props)
+		objectSchema.SetPropertyOrder(order)
 
 		g.LogDebug("adding def to cache: ", declInfo.defKey)
 		def := &definition{
@@ -338,11 +385,11 @@ func (g *JSONSchemaGenerator) generateObjectSchema(declInfo *declInfo, field *as
 			schema: objectSchema,
 		}
 
-		defCache[declInfo.defKey] = def
+		g.globalDefCache[declInfo.defKey] = def
 
 	}
 
-	if g.shouldReturnRef(declInfo) && !embedded {
+	if g.shouldReturnRef(declInfo) {
 		refSchema := schema.NewBasicSchema("")
 		refSchema.SetRef(schema.DefinitionRoot + declInfo.defKey)
 		return refSchema, err
@@ -350,6 +397,21 @@ func (g *JSONSchemaGenerator) generateObjectSchema(declInfo *declInfo, field *as
 	return objectSchema, err
 }
 
+// propertyOrderKeyword returns the keyword that holds the position of a property, or "" if
+// positions aren't added.
+func (g *JSONSchemaGenerator) propertyOrderKeyword() string {
+	switch g.options.PropertyOrder {
+	case PropertyOrderKeyword:
+		return "propertyOrder"
+	case PropertyOrderXOrder:
+		if !g.options.SupressXAttrs {
+			return "x-order"
+		}
+	}
+
+	return ""
+}
+
 func (g *JSONSchemaGenerator) generateSchemaForExpr(ownerDecl *declInfo, fieldExpr ast.Expr, field *ast.Field, parentKey string) (schema.JSONSchema, error) {
 	var foundDecl *declInfo
 	var err error
@@ -364,18 +426,46 @@ func (g *JSONSchemaGenerator) generateSchemaForExpr(ownerDecl *declInfo, fieldEx
 		generatedSchema = simpleDef.schema
 	}
 
+	// the decl's own type, e.g. for the root or a named type found via an ident or selector
+	if generatedSchema == nil && fieldExpr == ownerDecl.typeSpec.Type {
+		simpleSchema, ok, err = g.typeMappingForPath(g.typePathForDecl(ownerDecl), field)
+
+		if !ok && err == nil {
+			simpleSchema, ok, err = g.generateSchemaForMarshaler(ownerDecl, parentKey)
+		}
+
+		if !ok && err == nil {
+			simpleSchema, ok, err = g.generateSchemaForNamedSimpleType(ownerDecl, field, parentKey)
+		}
+
+		if ok {
+			generatedSchema = simpleSchema
+		}
+	}
+
 	//	fmt.Println(ownerDecl)
-	if generatedSchema == nil {
+	if generatedSchema == nil && err == nil {
 
 		switch fieldType := fieldExpr.(type) {
 		case *ast.StructType:
 			g.LogVerbose("field type is struct: ")
 
-			generatedSchema, err = g.generateObjectSchema(ownerDecl, field, false, parentKey)
+			generatedSchema, err = g.generateObjectSchema(ownerDecl, field, parentKey)
 
 		case *ast.Ident:
 			g.LogVerbose(fmt.Sprintf("field type is ident: %s, %s", fieldType.Name, ownerDecl.defKey))
 
+			if arg, found := ownerDecl.typeArgs[fieldType.Name]; found {
+				g.LogVerboseF("substituting type argument %s for %s\n", types.ExprString(arg.expr), fieldType.Name)
+				generatedSchema, err = g.generateSchemaForExpr(arg.owner, arg.expr, field, parentKey)
+				break
+			}
+
+			if isTypeParam(ownerDecl.typeSpec, fieldType.Name) {
+				err = fmt.Errorf("type parameter %s of %s is not instantiated", fieldType.Name, ownerDecl.typeSpec.Name.Name)
+				break
+			}
+
 			if simpleSchema, ok, err = g.generateSchemaForBuiltIn(fieldType.Name, field, parentKey); ok {
 				generatedSchema = simpleSchema
 				break
@@ -387,45 +477,6 @@ func (g *JSONSchemaGenerator) generateSchemaForExpr(ownerDecl *declInfo, fieldEx
 
 			if err == nil {
 				g.LogDebug("found decl ", foundDecl.typeSpec.Name.Name)
-				//if we already have the schema...
-				if simpleDef, found := g.simpleTypeCache[foundDecl.defKey]; found {
-					g.LogDebug("returning cached simple schema for ", foundDecl.defKey)
-					generatedSchema = simpleDef.schema
-					break
-				}
-
-				g.LogVerboseF("checking if %s type %s is a simple type\n", fieldType.Name, types.ExprString(foundDecl.typeSpec.Type))
-				// if the declared type is a built-in, we need to fill in any schema attrs on the base type
-				if jsonType, found := builtinTypes[types.ExprString(foundDecl.typeSpec.Type)]; found {
-					g.LogVerbose("found decl is a simple type: ", jsonType)
-
-					if simpleSchema, ok, err = g.generateSchemaForBuiltIn(types.ExprString(foundDecl.typeSpec.Type), field, parentKey); ok {
-
-						g.LogVerbose("got a simpleSchema for ", fieldType.Name)
-						anno, simpleErr := g.findJSONSchemaAnnotationForDecl(foundDecl)
-						if simpleErr != nil {
-							err = simpleErr
-							break
-						}
-
-						switch jsonType {
-						case "string":
-							g.populateStringAttrs(simpleSchema.(schema.StringSchema), anno)
-
-						case "number", "integer":
-							g.populateNumericAttrs(simpleSchema.(schema.NumericSchema), anno)
-						}
-
-						g.simpleTypeCache[foundDecl.defKey] = &definition{
-							decl:   foundDecl,
-							schema: simpleSchema,
-						}
-
-						generatedSchema = simpleSchema
-						break
-					}
-
-				}
 				generatedSchema, err = g.generateSchemaForExpr(foundDecl, foundDecl.typeSpec.Type, field, parentKey)
 			}
 
@@ -447,8 +498,8 @@ func (g *JSONSchemaGenerator) generateSchemaForExpr(ownerDecl *declInfo, fieldEx
 			g.LogVerboseF("got selector expression type %s.%s\n", fieldType.X, fieldType.Sel.Name)
 			fullSelectorName := fmt.Sprintf("%s.%s", fieldType.X, fieldType.Sel.Name)
 
-			if "json.RawMessage" == fullSelectorName {
-				generatedSchema = schema.NewMapSchema(g.options.SupressXAttrs)
+			if simpleSchema, ok, err = g.typeMappingForSelector(ownerDecl, fieldType, field); ok || err != nil {
+				generatedSchema = simpleSchema
 				break
 			}
 
@@ -465,9 +516,23 @@ func (g *JSONSchemaGenerator) generateSchemaForExpr(ownerDecl *declInfo, fieldEx
 				generatedSchema, err = g.generateSchemaForExpr(foundDecl, foundDecl.typeSpec.Type, field, parentKey)
 			}
 
+		case *ast.IndexExpr:
+			g.LogVerbose("got generic instantiation ", types.ExprString(fieldType))
+			generatedSchema, err = g.generateSchemaForInstance(ownerDecl, fieldType.X, []ast.Expr{fieldType.Index}, field, parentKey)
+
+		case *ast.IndexListExpr:
+			g.LogVerbose("got generic instantiation ", types.ExprString(fieldType))
+			generatedSchema, err = g.generateSchemaForInstance(ownerDecl, fieldType.X, fieldType.Indices, field, parentKey)
+
 		case *ast.ArrayType:
 			g.LogVerbose("got array type ")
-			generatedSchema, err = g.generateArraySchema(ownerDecl, fieldType.Elt, field, parentKey)
+
+			if isByteSlice(ownerDecl, fieldType) {
+				generatedSchema, _, err = g.generateSchemaForBuiltIn("[]byte", field, parentKey)
+				break
+			}
+
+			generatedSchema, err = g.generateArraySchema(ownerDecl, fieldType, field, parentKey)
 
 		case *ast.InterfaceType:
 			g.LogVerbose("got interface type ", field)
@@ -481,19 +546,14 @@ func (g *JSONSchemaGenerator) generateSchemaForExpr(ownerDecl *declInfo, fieldEx
 
 		case *ast.MapType:
 			g.LogVerbose("got map type ")
-			if field != nil {
-				generatedSchema, err = g.generateInterfaceSchemaForField(ownerDecl, field, parentKey)
-				break
-			}
-
-			generatedSchema, err = g.generateInterfaceSchemaForDecl(ownerDecl, parentKey)
+			generatedSchema, err = g.generateMapSchema(ownerDecl, fieldType, field, parentKey)
 		}
 	}
 
 	if err == nil {
 		fieldSchema = generatedSchema.Clone()
 		if field != nil {
-			g.addCommonAttrsForField(fieldSchema, field, parentKey)
+			err = g.addCommonAttrsForField(fieldSchema, field, parentKey)
 		}
 
 		g.addDocsForField(fieldSchema, foundDecl, field)
@@ -502,30 +562,18 @@ func (g *JSONSchemaGenerator) generateSchemaForExpr(ownerDecl *declInfo, fieldEx
 	return fieldSchema, err
 }
 
-func (g *JSONSchemaGenerator) generateEmbeddedSchema(ownerDecl *declInfo, expr ast.Expr, parentKey string) (schema.JSONSchema, error) {
-	var embeddedDecl *declInfo
-
-	switch embeddedType := expr.(type) {
-	case *ast.Ident:
-		g.LogVerbose("embedded type is ident")
-
-		embeddedDecl, _ = g.findDeclInfoForPackage(ownerDecl.pkg, ownerDecl.file, embeddedType.Name)
+func (g *JSONSchemaGenerator) generateSchemaForInstance(ownerDecl *declInfo, genericExpr ast.Expr, argExprs []ast.Expr, field *ast.Field, parentKey string) (schema.JSONSchema, error) {
+	instDecl, err := g.findDeclInfoForInstance(ownerDecl, genericExpr, argExprs)
 
-	case *ast.SelectorExpr:
-		g.LogVerbose("embedded type is SelectorExpr")
-
-		embeddedDecl, _ = g.findDeclInfoForSelector(ownerDecl, embeddedType)
-
-	case *ast.StarExpr:
-		return g.generateEmbeddedSchema(ownerDecl, embeddedType.X, parentKey)
+	if err != nil {
+		return nil, err
 	}
 
-	switch embeddedDecl.typeSpec.Type.(type) {
-	case *ast.StructType:
-		return g.generateObjectSchema(embeddedDecl, nil, true, parentKey)
-
+	if instDecl.defKey == ownerDecl.defKey {
+		return generateSelfRef(), nil
 	}
-	return nil, fmt.Errorf("unable to resolve embedded struct for: %#v", expr)
+
+	return g.generateSchemaForExpr(instDecl, instDecl.typeSpec.Type, field, parentKey)
 }
 
 func (g *JSONSchemaGenerator) generateSchemaForBuiltIn(name string, field *ast.Field, parentKey string) (schema.JSONSchema, bool, error) {
@@ -554,6 +602,57 @@ func (g *JSONSchemaGenerator) generateSchemaForBuiltIn(name string, field *ast.F
 	return nil, false, err
 }
 
+// generateSchemaForNamedSimpleType returns the schema for a decl whose type is a built-in such as
+// `type Color string`, filling in any schema attrs and enum values declared for the named type.
+func (g *JSONSchemaGenerator) generateSchemaForNamedSimpleType(decl *declInfo, field *ast.Field, parentKey string) (schema.JSONSchema, bool, error) {
+	if simpleDef, found := g.simpleTypeCache[decl.defKey]; found {
+		g.LogDebug("returning cached simple schema for ", decl.defKey)
+		return simpleDef.schema, true, nil
+	}
+
+	baseType := types.ExprString(decl.typeSpec.Type)
+	g.LogVerboseF("checking if %s type %s is a simple type\n", decl.typeSpec.Name.Name, baseType)
+
+	jsonType, found := builtinTypes[baseType]
+	if !found {
+		return nil, false, nil
+	}
+
+	g.LogVerbose("found decl is a simple type: ", jsonType)
+	simpleSchema, ok, err := g.generateSchemaForBuiltIn(baseType, field, parentKey)
+	if !ok {
+		return nil, false, err
+	}
+
+	anno, err := g.findJSONSchemaAnnotationForDecl(decl)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch jsonType {
+	case "string":
+		g.populateStringAttrs(simpleSchema.(schema.StringSchema), anno)
+
+	case "number", "integer":
+		g.populateNumericAttrs(simpleSchema.(schema.NumericSchema), anno)
+	}
+
+	simpleSchema = g.addEnumForDecl(simpleSchema, decl)
+
+	if anno != nil {
+		if err = g.addEnumAndConst(simpleSchema, anno, decl.typeSpec.Name.Name); err != nil {
+			return nil, false, err
+		}
+	}
+
+	g.simpleTypeCache[decl.defKey] = &definition{
+		decl:   decl,
+		schema: simpleSchema,
+	}
+
+	return simpleSchema, true, nil
+}
+
 func (g *JSONSchemaGenerator) generateSimpleSchema(goType, jsonType string, field *ast.Field, parentKey string) (schema.JSONSchema, error) {
 	var err error
 
@@ -581,16 +680,108 @@ func (g *JSONSchemaGenerator) generateSimpleSchema(goType, jsonType string, fiel
 	return ss, err
 }
 
-func (g *JSONSchemaGenerator) generateArraySchema(ownerDecl *declInfo, elemExpr ast.Expr, field *ast.Field, parentKey string) (schema.JSONSchema, error) {
+// generateStringEncodedSchema converts the schema of a number or boolean field with the ",string"
+// json tag option into a string schema that matches the quoted value encoding/json produces.
+func (g *JSONSchemaGenerator) generateStringEncodedSchema(ownerDecl *declInfo, field *ast.Field, sch schema.JSONSchema) schema.JSONSchema {
+	if sch.GetType() == nil {
+		return sch
+	}
+
+	var encoded schema.StringSchema
+
+	switch sch.GetType().String {
+	case schema.SchemaTypeInteger, schema.SchemaTypeNumber:
+		ns := schema.NewNumericStringSchema()
+		encoded = ns
+
+		if numeric, ok := sch.(schema.NumericSchema); ok && !g.options.SupressXAttrs {
+			ns.SetMaximum(numeric.GetMaximum())
+			ns.SetMinimum(numeric.GetMinimum())
+			ns.SetMultipleOf(numeric.GetMultipleOf())
+			ns.SetExclusiveMaximum(numeric.GetExclusiveMaximum())
+			ns.SetExclusiveMinimum(numeric.GetExclusiveMinimum())
+		}
+
+		var basic *types.Basic
+		if fieldType := ownerDecl.pkg.TypesInfo.TypeOf(field.Type); fieldType != nil {
+			if ptr, isPtr := fieldType.Underlying().(*types.Pointer); isPtr {
+				fieldType = ptr.Elem()
+			}
+			basic, _ = fieldType.Underlying().(*types.Basic)
+		}
+
+		switch {
+		case sch.GetType().String == schema.SchemaTypeNumber:
+			ns.SetPattern(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+		case basic != nil && basic.Info()&types.IsUnsigned != 0:
+			ns.SetPattern("^[0-9]+$")
+		default:
+			ns.SetPattern("^-?[0-9]+$")
+		}
+
+		if basic != nil {
+			switch basic.Kind() {
+			case types.Int32:
+				ns.SetFormat("int32")
+			case types.Int64:
+				ns.SetFormat("int64")
+			case types.Float32:
+				ns.SetFormat("float")
+			case types.Float64:
+				ns.SetFormat("double")
+			}
+		}
+
+	case schema.SchemaTypeBoolean:
+		encoded = schema.NewStringSchema()
+		encoded.SetEnum([]interface{}{"true", "false"})
+
+	default:
+		return sch
+	}
+
+	encoded.SetTitle(sch.GetTitle())
+	encoded.SetDescription(sch.GetDescription())
+
+	if sch.GetDefault() != nil {
+		encoded.SetDefault(fmt.Sprint(sch.GetDefault()))
+	}
+
+	if len(sch.GetEnum()) > 0 {
+		values := make([]interface{}, 0, len(sch.GetEnum()))
+		for _, v := range sch.GetEnum() {
+			values = append(values, fmt.Sprint(v))
+		}
+		encoded.SetEnum(values)
+		encoded.SetEnumNames(sch.GetEnumNames())
+		encoded.SetEnumDescriptions(sch.GetEnumDescriptions())
+	}
+
+	if constValue, hasConst := sch.GetConst(); hasConst {
+		encoded.SetConst(fmt.Sprint(constValue))
+	}
+
+	return encoded
+}
+
+// generateArraySchema generates the schema for a slice or fixed-size array. Fixed-size arrays always
+// encode exactly N items so they get minItems and maxItems of N, which annotations can still override.
+func (g *JSONSchemaGenerator) generateArraySchema(ownerDecl *declInfo, arrayType *ast.ArrayType, field *ast.Field, parentKey string) (schema.JSONSchema, error) {
 	var err error
 	var elemSchema schema.JSONSchema
 
 	arraySchema := schema.NewArraySchema()
 
+	if length, fixed := arrayLength(ownerDecl, arrayType); fixed {
+		g.LogVerboseF("array %s has a fixed length of %d\n", types.ExprString(arrayType), length)
+		arraySchema.SetMinItems(length)
+		arraySchema.SetMaxItems(length)
+	}
+
 	err = g.addArrayAttrsForField(arraySchema, field)
-	g.LogDebug("generating schema for array elem expr: ", elemExpr)
+	g.LogDebug("generating schema for array elem expr: ", arrayType.Elt)
 	if err == nil {
-		elemSchema, err = g.generateSchemaForExpr(ownerDecl, elemExpr, nil, parentKey)
+		elemSchema, err = g.generateSchemaForExpr(ownerDecl, arrayType.Elt, nil, parentKey)
 	}
 
 	if err == nil {
@@ -647,10 +838,150 @@ func (g *JSONSchemaGenerator) generateInterfaceSchemaForDecl(decl *declInfo, par
 	return iSchema, err
 }
 
-func (g *JSONSchemaGenerator) generateMapSchema(field *ast.Field, parentKey string) (schema.JSONSchema, error) {
-	mSchema := schema.NewMapSchema(g.options.SupressXAttrs)
+func (g *JSONSchemaGenerator) generateMapSchema(ownerDecl *declInfo, mapType *ast.MapType, field *ast.Field, parentKey string) (schema.JSONSchema, error) {
+	var err error
+	var mSchema schema.JSONSchema
+	var aprops *boolOrPath
+	var valueSchema schema.JSONSchema
+	var keySchema schema.JSONSchema
+
+	if field != nil {
+		mSchema, err = g.generateInterfaceSchemaForField(ownerDecl, field, parentKey)
+	} else {
+		mSchema, err = g.generateInterfaceSchemaForDecl(ownerDecl, parentKey)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	objSchema, isObj := mSchema.(schema.ObjectSchema)
+	if !isObj {
+		return mSchema, nil
+	}
+
+	// only use the decl's annotation if the decl is the map type itself and not an enclosing struct
+	var mapDecl *declInfo
+	if ownerDecl.typeSpec.Type == ast.Expr(mapType) {
+		mapDecl = ownerDecl
+	}
+
+	aprops, err = g.findAdditionalPropertiesAnno(mapDecl, field)
+
+	// an explicit additionalProperties annotation always wins over the map's value type
+	if err == nil && aprops != nil {
+		var bos *schema.BoolOrSchema
+		bos, err = g.additionalPropertiesFromAnno(aprops, parentKey)
+		if err == nil {
+			objSchema.SetAdditionalProperties(bos)
+		}
+	} else if err == nil && !isEmptyInterface(mapType.Value) {
+		g.LogVerbose("generating schema for map value ", types.ExprString(mapType.Value))
+		valueSchema, err = g.generateSchemaForExpr(ownerDecl, mapType.Value, nil, parentKey)
+		if err == nil {
+			objSchema.SetAdditionalProperties(schema.NewBoolOrSchema(valueSchema))
+		}
+	} else if err == nil {
+		objSchema.SetAdditionalProperties(schema.NewBoolOrSchema(true))
+	}
+
+	if err == nil && field != nil {
+		var fieldAnno *schemaAnno
+		fieldAnno, err = g.findJSONSchemaAnnotationForField(field)
+		if err == nil && fieldAnno != nil {
+			err = g.addPatternProperties(objSchema, fieldAnno, field.Names[0].Name, parentKey)
+		}
+	}
+
+	if err == nil {
+		keySchema, err = g.generateMapKeySchema(ownerDecl, mapType.Key, parentKey)
+	}
+
+	if err == nil && keySchema != nil {
+		objSchema.SetPropertyNames(keySchema)
+	}
+
+	return objSchema, err
+}
+
+// generateMapKeySchema returns a schema for the property names of a map when the key type
+// constrains them beyond being a plain string.
+func (g *JSONSchemaGenerator) generateMapKeySchema(ownerDecl *declInfo, keyExpr ast.Expr, parentKey string) (schema.JSONSchema, error) {
+	keyType := ownerDecl.pkg.TypesInfo.TypeOf(keyExpr)
+
+	if keyType == nil || keyType.Underlying() == nil {
+		return nil, nil
+	}
+
+	basic, ok := keyType.Underlying().(*types.Basic)
+	if !ok {
+		return nil, nil
+	}
+
+	named, isNamed := keyType.(*types.Named)
+
+	// encoding/json writes string keys as they are and prefers MarshalText over the decimal form
+	// of integer keys
+	if basic.Info()&types.IsString == 0 && !(isNamed && isTextMarshaler(named)) {
+		if basic.Info()&types.IsInteger == 0 {
+			return nil, nil
+		}
+
+		return g.generateIntegerKeySchema(ownerDecl, keyExpr, basic, isNamed, parentKey)
+	}
+
+	ks, err := g.generateSchemaForExpr(ownerDecl, keyExpr, nil, parentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if stringSchema, ok := ks.(schema.StringSchema); ok && hasStringConstraints(stringSchema) {
+		return stringSchema, nil
+	}
+
+	return nil, nil
+}
+
+// generateIntegerKeySchema returns the schema for the decimal strings encoding/json writes for
+// integer map keys. Keys of a named type with constants can only be one of the constants.
+func (g *JSONSchemaGenerator) generateIntegerKeySchema(ownerDecl *declInfo, keyExpr ast.Expr, basic *types.Basic, isNamed bool, parentKey string) (schema.JSONSchema, error) {
+	ks := schema.NewStringSchema()
+
+	if basic.Info()&types.IsUnsigned != 0 {
+		ks.SetPattern("^[0-9]+$")
+	} else {
+		ks.SetPattern("^-?[0-9]+$")
+	}
+
+	if !isNamed {
+		return ks, nil
+	}
+
+	valueSchema, err := g.generateSchemaForExpr(ownerDecl, keyExpr, nil, parentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	enum := valueSchema.GetEnum()
+	if len(enum) < 1 {
+		return ks, nil
+	}
+
+	values := make([]interface{}, 0, len(enum))
+	for _, v := range enum {
+		values = append(values, fmt.Sprint(v))
+	}
+
+	es := schema.NewStringSchema()
+	es.SetEnum(values)
+	es.SetEnumNames(valueSchema.GetEnumNames())
+	es.SetEnumDescriptions(valueSchema.GetEnumDescriptions())
+
+	return es, nil
+}
 
-	return mSchema, nil
+func hasStringConstraints(ss schema.StringSchema) bool {
+	return ss.GetPattern() != "" || ss.GetFormat() != "" || ss.GetMaxLength() > 0 || ss.GetMinLength() > 0 || len(ss.GetEnum()) > 0
 }
 
 func generateSelfRef() schema.JSONSchema {
@@ -280,3 +280,28 @@ func (suite *GeneratorTestSuite) TestNot() {
 	assert.Equal(suite.T(), "#/definitions/github_com-brainicorn-schematestobjects-xof-AnotherThing", objSchema.GetNot().GetRef(), "got %s", objSchema.GetNot().GetRef())
 
 }
+
+func (suite *GeneratorTestSuite) TestLoadPackageOnDemand() {
+	suite.T().Parallel()
+
+	opts := NewOptions()
+	opts.LogLevel = QuietLevel
+
+	prog, err := NewJSONSchemaGenerator("", "", opts).loadProgram("github.com/brainicorn/jsonschemagen/schema", opts)
+
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), prog.byPath["encoding/csv"], "encoding/csv should not be loaded up front")
+
+	pkg, err := prog.Package("encoding/csv")
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), pkg, "encoding/csv should be loaded on demand")
+	assert.Equal(suite.T(), "encoding/csv", pkg.PkgPath)
+
+	// packages loaded on demand are dependencies, not roots
+	assert.False(suite.T(), prog.isRootPackage(pkg), "encoding/csv should not be a root package")
+
+	_, err = prog.Package("github.com/brainicorn/jsonschemagen/nosuchpackage")
+
+	assert.Error(suite.T(), err, "loading a missing package should fail")
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const genericsDefPrefix = "github_com-brainicorn-jsonschemagen-generator-"
+
+type GenericUser struct {
+	Name string `json:"name"`
+}
+
+type Paged[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+type Result[T any, E any] struct {
+	Value T `json:"value"`
+	Err   E `json:"err"`
+}
+
+type GenericEnvelope[T any] struct {
+	Page Paged[T] `json:"page"`
+}
+
+type GenericHolder struct {
+	Users   Paged[GenericUser]           `json:"users"`
+	Names   Paged[string]                `json:"names"`
+	Outcome Result[GenericUser, string]  `json:"outcome"`
+	Wrapped GenericEnvelope[GenericUser] `json:"wrapped"`
+}
+
+type GenericShapeHolder struct {
+	Pairs   Paged[[2]int]       `json:"pairs"`
+	Numbers Paged[[]int]        `json:"numbers"`
+	Refs    Paged[*GenericUser] `json:"refs"`
+	Values  Paged[GenericUser]  `json:"values"`
+}
+
+type UninstantiatedRoot[T any] struct {
+	Value T
+}
+
+func TestGenericInstantiations(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "GenericHolder", opts)
+
+	assert.NoError(t, err)
+
+	pagedUserKey := genericsDefPrefix + "Paged[" + genericsDefPrefix + "GenericUser]"
+	pagedStringKey := genericsDefPrefix + "Paged[string]"
+	resultKey := genericsDefPrefix + "Result[" + genericsDefPrefix + "GenericUser,string]"
+	envelopeKey := genericsDefPrefix + "GenericEnvelope[" + genericsDefPrefix + "GenericUser]"
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+	assert.Equal(t, schema.DefinitionRoot+pagedUserKey, props["users"].GetRef())
+	assert.Equal(t, schema.DefinitionRoot+pagedStringKey, props["names"].GetRef())
+	assert.Equal(t, schema.DefinitionRoot+resultKey, props["outcome"].GetRef())
+	assert.Equal(t, schema.DefinitionRoot+envelopeKey, props["wrapped"].GetRef())
+
+	defs := jsonSchema.GetDefinitions()
+
+	pagedUser := defs[pagedUserKey].(schema.ObjectSchema)
+	userItems := pagedUser.GetProperties()["items"].(schema.ArraySchema).GetItems()
+	assert.Equal(t, schema.DefinitionRoot+genericsDefPrefix+"GenericUser", userItems.GetRef())
+	assert.Equal(t, "github.com/brainicorn/jsonschemagen/generator/Paged[github.com/brainicorn/jsonschemagen/generator/GenericUser]", pagedUser.GetGoPath())
+
+	pagedString := defs[pagedStringKey].(schema.ObjectSchema)
+	stringItems := pagedString.GetProperties()["items"].(schema.ArraySchema).GetItems()
+	assert.Equal(t, schema.SchemaTypeString, stringItems.GetType().String)
+
+	result := defs[resultKey].(schema.ObjectSchema)
+	assert.Equal(t, schema.DefinitionRoot+genericsDefPrefix+"GenericUser", result.GetProperties()["value"].GetRef())
+	assert.Equal(t, schema.SchemaTypeString, result.GetProperties()["err"].GetType().String)
+
+	envelope := defs[envelopeKey].(schema.ObjectSchema)
+	assert.Equal(t, schema.DefinitionRoot+pagedUserKey, envelope.GetProperties()["page"].GetRef())
+}
+
+func TestGenericInstantiationsKeepPointersAndArrayLengths(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "GenericShapeHolder", opts)
+
+	assert.NoError(t, err)
+
+	pairsKey := genericsDefPrefix + "Paged[[2]int]"
+	numbersKey := genericsDefPrefix + "Paged[[]int]"
+	refsKey := genericsDefPrefix + "Paged[*" + genericsDefPrefix + "GenericUser]"
+	valuesKey := genericsDefPrefix + "Paged[" + genericsDefPrefix + "GenericUser]"
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+	assert.Equal(t, schema.DefinitionRoot+pairsKey, props["pairs"].GetRef())
+	assert.Equal(t, schema.DefinitionRoot+numbersKey, props["numbers"].GetRef())
+	assert.Equal(t, schema.DefinitionRoot+refsKey, props["refs"].GetRef())
+	assert.Equal(t, schema.DefinitionRoot+valuesKey, props["values"].GetRef())
+
+	defs := jsonSchema.GetDefinitions()
+
+	pairItems := defs[pairsKey].(schema.ObjectSchema).GetProperties()["items"].(schema.ArraySchema).GetItems().(schema.ArraySchema)
+	assert.Equal(t, int64(2), pairItems.GetMinItems())
+	assert.Equal(t, int64(2), pairItems.GetMaxItems())
+
+	numberItems := defs[numbersKey].(schema.ObjectSchema).GetProperties()["items"].(schema.ArraySchema).GetItems().(schema.ArraySchema)
+	assert.Equal(t, int64(0), numberItems.GetMinItems())
+	assert.Equal(t, int64(0), numberItems.GetMaxItems())
+}
+
+func TestUninstantiatedGenericRoot(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	_, err := GenerateIt(pkg, "UninstantiatedRoot", opts)
+
+	assert.EqualError(t, err, "type parameter T of UninstantiatedRoot is not instantiated")
+}
@@ -30,6 +30,13 @@ func (g *JSONSchemaGenerator) LogInfoF(format string, args ...interface{}) {
 	}
 }
 
+// LogWarnF writes a formatted warning to std out
+func (g *JSONSchemaGenerator) LogWarnF(format string, args ...interface{}) {
+	if g.options.LogLevel >= InfoLevel {
+		fmt.Printf("WARNING: "+format, args...)
+	}
+}
+
 // LogDebug writes a debug message to std out
 func (g *JSONSchemaGenerator) LogDebug(args ...interface{}) {
 	if g.options.LogLevel >= DebugLevel {
@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// @jsonSchema(pattern="^[a-z]+$")
+type MapKeyName string
+
+type MapKeyLevel int
+
+const (
+	MapKeyLow MapKeyLevel = iota + 1
+	MapKeyHigh
+)
+
+type MapKeyCode uint16
+
+type MapAddress struct {
+	Street string `json:"street"`
+}
+
+type MapHolder struct {
+	Addresses map[string]MapAddress  `json:"addresses"`
+	Counts    map[string]int         `json:"counts"`
+	Anything  map[string]interface{} `json:"anything"`
+	Named     map[MapKeyName]string  `json:"named"`
+	ByID      map[int64]MapAddress   `json:"byId"`
+	ByLevel   map[MapKeyLevel]string `json:"byLevel"`
+	ByCode    map[MapKeyCode]string  `json:"byCode"`
+
+	// @jsonSchema(additionalProperties=false)
+	Closed map[string]string `json:"closed"`
+}
+
+type PatternExtension struct {
+	Value string `json:"value"`
+}
+
+// @jsonSchema(patternProperties=["^x-=github.com/brainicorn/jsonschemagen/generator/PatternExtension"])
+type PatternStruct struct {
+	Name string `json:"name"`
+
+	// @jsonSchema(patternProperties=["^[0-9]+$=integer", "{\"^id-\": \"string\"}"])
+	Scores map[string]int `json:"scores"`
+}
+
+func TestMapValueAndKeySchemas(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "MapHolder", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	addresses := props["addresses"].(schema.ObjectSchema)
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-MapAddress", addresses.GetAdditionalProperties().Schema.GetRef())
+	assert.Nil(t, addresses.GetPropertyNames())
+
+	counts := props["counts"].(schema.ObjectSchema)
+	assert.Equal(t, schema.SchemaTypeInteger, counts.GetAdditionalProperties().Schema.GetType().String)
+
+	anything := props["anything"].(schema.ObjectSchema)
+	assert.Nil(t, anything.GetAdditionalProperties().Schema)
+	assert.True(t, anything.GetAdditionalProperties().Boolean)
+
+	named := props["named"].(schema.ObjectSchema)
+	assert.Equal(t, "^[a-z]+$", named.GetPropertyNames().(schema.StringSchema).GetPattern())
+
+	byID := props["byId"].(schema.ObjectSchema)
+	assert.Equal(t, "^-?[0-9]+$", byID.GetPropertyNames().(schema.StringSchema).GetPattern())
+
+	// named integer keys are still written as decimal strings
+	byLevel := props["byLevel"].(schema.ObjectSchema)
+	assert.Equal(t, []interface{}{"1", "2"}, byLevel.GetPropertyNames().GetEnum())
+	assert.Equal(t, []string{"MapKeyLow", "MapKeyHigh"}, byLevel.GetPropertyNames().GetEnumNames())
+
+	byCode := props["byCode"].(schema.ObjectSchema)
+	assert.Equal(t, "^[0-9]+$", byCode.GetPropertyNames().(schema.StringSchema).GetPattern())
+
+	closed := props["closed"].(schema.ObjectSchema)
+	assert.Nil(t, closed.GetAdditionalProperties().Schema)
+	assert.False(t, closed.GetAdditionalProperties().Boolean)
+}
+
+func TestPropertyNamesDroppedForDraft04(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "MapHolder", opts)
+	assert.NoError(t, err)
+
+	draft04, err := schema.MarshalForVersion(jsonSchema, schema.SpecVersionDraftV4)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(draft04), "propertyNames")
+
+	draft07, err := schema.MarshalForVersion(jsonSchema, schema.SpecVersionDraft07)
+	assert.NoError(t, err)
+	assert.Contains(t, string(draft07), "propertyNames")
+}
+
+func TestPatternProperties(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "PatternStruct", opts)
+
+	assert.NoError(t, err)
+
+	objSchema := jsonSchema.(schema.ObjectSchema)
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-PatternExtension", objSchema.GetPatternProperties()["^x-"].GetRef())
+
+	scores := objSchema.GetProperties()["scores"].(schema.ObjectSchema)
+	assert.Equal(t, schema.SchemaTypeInteger, scores.GetPatternProperties()["^[0-9]+$"].GetType().String)
+	assert.Equal(t, schema.SchemaTypeString, scores.GetPatternProperties()["^id-"].GetType().String)
+
+	roundTrip, err := schema.FromJSON([]byte(schemaAsString(jsonSchema)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-PatternExtension", roundTrip.(schema.ObjectSchema).GetPatternProperties()["^x-"].GetRef())
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// generateSchemaForMarshaler returns the schema for a decl whose JSON form is produced by a
+// MarshalJSON or MarshalText method rather than its Go fields. The bool is false if the decl
+// implements neither json.Marshaler nor encoding.TextMarshaler.
+func (g *JSONSchemaGenerator) generateSchemaForMarshaler(decl *declInfo, parentKey string) (schema.JSONSchema, bool, error) {
+	if _, isInterface := decl.typeSpec.Type.(*ast.InterfaceType); isInterface {
+		return nil, false, nil
+	}
+
+	named := namedTypeForDecl(decl)
+	if named == nil {
+		return nil, false, nil
+	}
+
+	var sch schema.JSONSchema
+	var err error
+
+	jsonMarshaler := isJSONMarshaler(named)
+	if !jsonMarshaler && !isTextMarshaler(named) {
+		return nil, false, nil
+	}
+
+	anno, err := g.findJSONSchemaAnnotationForDecl(decl)
+	if err != nil {
+		return nil, false, err
+	}
+
+	name := decl.typeSpec.Name.Name
+
+	// encoding/json prefers MarshalJSON over MarshalText
+	if jsonMarshaler {
+		g.LogVerboseF("%s implements json.Marshaler\n", name)
+		sch, err = g.generateSchemaForJSONMarshaler(decl, anno, parentKey)
+	} else {
+		g.LogVerboseF("%s implements encoding.TextMarshaler\n", name)
+		ss := schema.NewStringSchema()
+		g.populateStringAttrs(ss, anno)
+		sch = g.addEnumForDecl(ss, decl)
+
+		if anno != nil {
+			err = g.addCommonAttrs(sch, anno, name, parentKey)
+		}
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	g.simpleTypeCache[decl.defKey] = &definition{
+		decl:   decl,
+		schema: sch,
+	}
+
+	return sch, true, nil
+}
+
+// generateSchemaForJSONMarshaler builds the schema for a json.Marshaler from the type attribute
+// and other attributes of its annotation. Without one there's no way to know the JSON form so an
+// open schema is used.
+func (g *JSONSchemaGenerator) generateSchemaForJSONMarshaler(decl *declInfo, anno *schemaAnno, parentKey string) (schema.JSONSchema, error) {
+	name := decl.typeSpec.Name.Name
+
+	if anno == nil || (len(anno.schemaType) < 1 && !g.hasXofAnnotation(anno)) {
+		g.LogWarnF("%s implements json.Marshaler but has no @jsonSchema type, using an open schema\n", g.typePathForDecl(decl))
+		sch := schema.NewBasicSchema("")
+
+		if anno != nil {
+			return sch, g.addCommonAttrs(sch, anno, name, parentKey)
+		}
+
+		return sch, nil
+	}
+
+	jsonType := strings.Join(anno.schemaType, ",")
+
+	switch jsonType {
+	case schema.SchemaTypeObject:
+		os := schema.NewObjectSchema(g.options.SupressXAttrs)
+		return os, g.addObjectAttrsForDecl(os, decl, parentKey)
+
+	case schema.SchemaTypeString:
+		ss := schema.NewStringSchema()
+		g.populateStringAttrs(ss, anno)
+		return ss, g.addCommonAttrs(ss, anno, name, parentKey)
+
+	case schema.SchemaTypeInteger, schema.SchemaTypeNumber:
+		ns := schema.NewNumericSchema(jsonType)
+		g.populateNumericAttrs(ns, anno)
+		return ns, g.addCommonAttrs(ns, anno, name, parentKey)
+
+	case schema.SchemaTypeArray:
+		as := schema.NewArraySchema()
+		g.populateArrayAttrs(as, anno)
+		return as, g.addCommonAttrs(as, anno, name, parentKey)
+	}
+
+	sch := schema.NewBasicSchema(jsonType)
+
+	return sch, g.addCommonAttrs(sch, anno, name, parentKey)
+}
+
+func isJSONMarshaler(named *types.Named) bool {
+	fn := lookupMethod(named, "MarshalJSON")
+
+	return fn != nil && isMarshalMethod(fn)
+}
+
+func isTextMarshaler(named *types.Named) bool {
+	fn := lookupMethod(named, "MarshalText")
+
+	return fn != nil && isMarshalMethod(fn)
+}
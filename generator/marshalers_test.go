@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Money is an amount in cents.
+//
+// @jsonSchema(type="string", pattern="^[0-9]+\\.[0-9]{2}$")
+type Money struct {
+	Cents int64
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(m.Cents/100, 10) + "." + strconv.FormatInt(m.Cents%100, 10))
+}
+
+type MarshalDuration int64
+
+func (d MarshalDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// @jsonSchema(format="uuid")
+type MarshalUUID [16]byte
+
+func (u *MarshalUUID) MarshalText() ([]byte, error) {
+	return []byte("00000000-0000-0000-0000-000000000000"), nil
+}
+
+type MarshalPoint struct {
+	X int
+	Y int
+}
+
+func (p MarshalPoint) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)), nil
+}
+
+type MarshalHolder struct {
+	Price    Money           `json:"price"`
+	Timeout  MarshalDuration `json:"timeout"`
+	ID       MarshalUUID     `json:"id"`
+	Location *MarshalPoint   `json:"location"`
+	Created  time.Time       `json:"created"`
+}
+
+func TestMarshalers(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "MarshalHolder", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	price := props["price"].(schema.StringSchema)
+	assert.Equal(t, schema.SchemaTypeString, price.GetType().String)
+	assert.Equal(t, `^[0-9]+\.[0-9]{2}$`, price.GetPattern())
+	assert.Equal(t, "Money is an amount in cents.", price.GetTitle())
+
+	// no annotation so we can't know the shape
+	assert.Nil(t, props["timeout"].GetType())
+
+	id := props["id"].(schema.StringSchema)
+	assert.Equal(t, schema.SchemaTypeString, id.GetType().String)
+	assert.Equal(t, "uuid", id.GetFormat())
+
+	location := props["location"]
+	assert.Equal(t, schema.SchemaTypeString, location.GetType().String)
+	_, isObject := location.(schema.ObjectSchema)
+	assert.False(t, isObject)
+
+	assert.Equal(t, "date-time", props["created"].(schema.StringSchema).GetFormat())
+
+	for key := range jsonSchema.GetDefinitions() {
+		assert.NotContains(t, key, "Marshal")
+		assert.NotContains(t, key, "Money")
+	}
+}
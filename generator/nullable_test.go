@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type NullableChild struct {
+	Name string `json:"name"`
+}
+
+type NullableHolder struct {
+	Name  *string        `json:"name"`
+	Child *NullableChild `json:"child"`
+	Color *EnumColor     `json:"color"`
+	Plain string         `json:"plain"`
+	// @jsonSchema(nullable=false)
+	NotNull *int `json:"notNull"`
+	// @jsonSchema(nullable=true)
+	Note string `json:"note"`
+}
+
+func generateNullableHolder(t *testing.T, style NullableStyle) map[string]schema.JSONSchema {
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.NullablePointers = style
+
+	jsonSchema, err := GenerateIt(pkg, "NullableHolder", opts)
+
+	assert.NoError(t, err)
+
+	return jsonSchema.(schema.ObjectSchema).GetProperties()
+}
+
+func TestNullablePointersTypeArray(t *testing.T) {
+	t.Parallel()
+
+	props := generateNullableHolder(t, NullableTypeArray)
+
+	assert.Equal(t, []string{"string", "null"}, props["name"].GetType().Array)
+
+	child := props["child"]
+	assert.Nil(t, child.GetType())
+	assert.Len(t, child.GetAnyOf(), 2)
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-NullableChild", child.GetAnyOf()[0].GetRef())
+	assert.Equal(t, "null", child.GetAnyOf()[1].GetType().String)
+
+	// null has to be in the enum too
+	color := props["color"]
+	assert.Equal(t, []string{"string", "null"}, color.GetType().Array)
+	assert.Equal(t, []interface{}{"red", "green", "blue", nil}, color.GetEnum())
+
+	assert.Equal(t, "string", props["plain"].GetType().String)
+	assert.Equal(t, "integer", props["notNull"].GetType().String)
+	assert.Equal(t, []string{"string", "null"}, props["note"].GetType().Array)
+}
+
+func TestNullablePointersOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	props := generateNullableHolder(t, NullableOpenAPI)
+
+	assert.Equal(t, "string", props["name"].GetType().String)
+	assert.True(t, props["name"].GetNullable())
+
+	child := props["child"]
+	assert.True(t, child.GetNullable())
+	assert.Len(t, child.GetAllOf(), 1)
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-NullableChild", child.GetAllOf()[0].GetRef())
+
+	assert.False(t, props["plain"].GetNullable())
+	assert.False(t, props["notNull"].GetNullable())
+	assert.True(t, props["note"].GetNullable())
+}
+
+func TestNullablePointersNone(t *testing.T) {
+	t.Parallel()
+
+	props := generateNullableHolder(t, NullableNone)
+
+	assert.Equal(t, "string", props["name"].GetType().String)
+	assert.False(t, props["name"].GetNullable())
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-NullableChild", props["child"].GetRef())
+
+	// an explicit nullable attribute still applies
+	assert.Equal(t, []string{"string", "null"}, props["note"].GetType().Array)
+}
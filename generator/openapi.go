@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// GenerateComponents generates the roots like GenerateAll and collects the root schemas along with
+// all of their definitions into a single map keyed by definition key. The result can be written as
+// OpenAPI components with schema.MarshalOpenAPIComponents, which rewrites the refs between them.
+//
+// Definitions shared by several roots are only included once.
+func (g *JSONSchemaGenerator) GenerateComponents(roots []RootSpec) (map[string]schema.JSONSchema, error) {
+	rootSchemas, err := g.GenerateAll(roots)
+
+	if err != nil {
+		return nil, err
+	}
+
+	components := make(map[string]schema.JSONSchema)
+
+	for _, root := range roots {
+		rootSchema := rootSchemas[root]
+
+		for key, def := range rootSchema.GetDefinitions() {
+			if _, found := components[key]; !found {
+				components[key] = def
+			}
+		}
+	}
+
+	// a root wins over a definition of the same type, its own definitions are dropped when marshaled
+	for _, root := range roots {
+		components[g.defKeyFromPath(root.String())] = rootSchemas[root]
+	}
+
+	return components, nil
+}
@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+const openAPIKeyPrefix = "github_com-brainicorn-jsonschemagen-generator-"
+
+func generateComponents(t *testing.T, version schema.SpecVersion) map[string]schema.JSONSchema {
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.NullablePointers = NullableTypeArray
+	opts.SpecVersion = version
+
+	components, err := NewJSONSchemaGenerator(pkg, "NullableHolder", opts).GenerateComponents([]RootSpec{
+		{Package: pkg, Type: "NullableHolder"},
+		{Package: pkg, Type: "VersionHolder"},
+	})
+
+	assert.NoError(t, err)
+
+	return components
+}
+
+func marshalComponents(t *testing.T, version schema.OpenAPIVersion) map[string]interface{} {
+	var specVersion schema.SpecVersion = schema.SpecVersionDraftV4
+	if version == schema.OpenAPIVersion31 {
+		specVersion = schema.SpecVersionDraft202012
+	}
+
+	componentBytes, err := schema.MarshalOpenAPIComponents(generateComponents(t, specVersion), version)
+	assert.NoError(t, err)
+
+	var out map[string]interface{}
+	assert.NoError(t, json.Unmarshal(componentBytes, &out))
+
+	return out["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+}
+
+func TestGenerateComponents(t *testing.T) {
+	t.Parallel()
+
+	components := generateComponents(t, schema.SpecVersionDraftV4)
+
+	assert.Contains(t, components, openAPIKeyPrefix+"NullableHolder")
+	assert.Contains(t, components, openAPIKeyPrefix+"NullableChild")
+	assert.Contains(t, components, openAPIKeyPrefix+"VersionHolder")
+	assert.Contains(t, components, openAPIKeyPrefix+"VersionChild")
+}
+
+func TestOpenAPI30Components(t *testing.T) {
+	t.Parallel()
+
+	schemas := marshalComponents(t, schema.OpenAPIVersion30)
+	holder := schemas[openAPIKeyPrefix+"NullableHolder"].(map[string]interface{})
+	props := holder["properties"].(map[string]interface{})
+
+	assert.NotContains(t, holder, "$schema")
+	assert.NotContains(t, holder, "definitions")
+
+	name := props["name"].(map[string]interface{})
+	assert.Equal(t, "string", name["type"])
+	assert.Equal(t, true, name["nullable"])
+
+	// a nullable ref can't have siblings so it's wrapped in an allOf
+	child := props["child"].(map[string]interface{})
+	assert.Equal(t, true, child["nullable"])
+	assert.NotContains(t, child, "anyOf")
+	assert.Equal(t, "#/components/schemas/"+openAPIKeyPrefix+"NullableChild", child["allOf"].([]interface{})[0].(map[string]interface{})["$ref"])
+
+	version := schemas[openAPIKeyPrefix+"VersionHolder"].(map[string]interface{})
+	versionProps := version["properties"].(map[string]interface{})
+
+	assert.NotContains(t, version, "id")
+	assert.Equal(t, []interface{}{"v1"}, versionProps["kind"].(map[string]interface{})["enum"])
+	assert.Equal(t, true, versionProps["count"].(map[string]interface{})["exclusiveMaximum"])
+}
+
+func TestOpenAPI31Components(t *testing.T) {
+	t.Parallel()
+
+	schemas := marshalComponents(t, schema.OpenAPIVersion31)
+	holder := schemas[openAPIKeyPrefix+"NullableHolder"].(map[string]interface{})
+	props := holder["properties"].(map[string]interface{})
+
+	assert.NotContains(t, holder, "$defs")
+	assert.Equal(t, []interface{}{"string", "null"}, props["name"].(map[string]interface{})["type"])
+	assert.NotContains(t, props["name"], "nullable")
+
+	child := props["child"].(map[string]interface{})
+	assert.Equal(t, "#/components/schemas/"+openAPIKeyPrefix+"NullableChild", child["anyOf"].([]interface{})[0].(map[string]interface{})["$ref"])
+
+	// null is only in the enum once
+	color := props["color"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"red", "green", "blue", nil}, color["enum"])
+
+	version := schemas[openAPIKeyPrefix+"VersionHolder"].(map[string]interface{})
+	versionProps := version["properties"].(map[string]interface{})
+
+	assert.Equal(t, "v1", versionProps["kind"].(map[string]interface{})["const"])
+	assert.Equal(t, 10.0, versionProps["count"].(map[string]interface{})["exclusiveMaximum"])
+}
+
+func TestOpenAPIComponentsYAML(t *testing.T) {
+	t.Parallel()
+
+	componentBytes, err := schema.MarshalOpenAPIComponentsYAML(generateComponents(t, schema.SpecVersionDraftV4), schema.OpenAPIVersion30)
+	assert.NoError(t, err)
+
+	out := string(componentBytes)
+
+	assert.True(t, strings.HasPrefix(out, "components:\n  schemas:\n"))
+	assert.Contains(t, out, "$ref: '#/components/schemas/"+openAPIKeyPrefix+"NullableChild'")
+	assert.Contains(t, out, "nullable: true")
+
+	// components are sorted by name
+	assert.True(t, strings.Index(out, openAPIKeyPrefix+"NullableChild:") < strings.Index(out, openAPIKeyPrefix+"VersionHolder:"))
+}
+
+// collectRefs returns every $ref in a decoded JSON document.
+func collectRefs(node interface{}) []string {
+	var refs []string
+
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				refs = append(refs, ref)
+				continue
+			}
+			refs = append(refs, collectRefs(value)...)
+		}
+	case []interface{}:
+		for _, value := range typed {
+			refs = append(refs, collectRefs(value)...)
+		}
+	}
+
+	return refs
+}
+
+func TestOpenAPIGenericComponentNames(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	components, err := NewJSONSchemaGenerator(pkg, "GenericHolder", opts).GenerateComponents([]RootSpec{
+		{Package: pkg, Type: "GenericHolder"},
+		{Package: pkg, Type: "GenericShapeHolder"},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, components, openAPIKeyPrefix+"Paged["+openAPIKeyPrefix+"GenericUser]")
+
+	for _, version := range []schema.OpenAPIVersion{schema.OpenAPIVersion30, schema.OpenAPIVersion31} {
+		componentBytes, err := schema.MarshalOpenAPIComponents(components, version)
+		assert.NoError(t, err)
+
+		var out map[string]interface{}
+		assert.NoError(t, json.Unmarshal(componentBytes, &out))
+
+		schemas := out["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+		assert.Len(t, schemas, len(components))
+
+		validName := regexp.MustCompile(`^[a-zA-Z0-9.\-_]+$`)
+		for name := range schemas {
+			assert.Regexp(t, validName, name)
+		}
+
+		// every ref points at a component
+		for _, ref := range collectRefs(schemas) {
+			assert.True(t, strings.HasPrefix(ref, schema.ComponentsRoot), ref)
+			assert.Contains(t, schemas, strings.TrimPrefix(ref, schema.ComponentsRoot))
+		}
+
+		assert.Contains(t, schemas, openAPIKeyPrefix+"Paged.ptr."+openAPIKeyPrefix+"GenericUser")
+		assert.Contains(t, schemas, openAPIKeyPrefix+"Paged.2.int")
+		assert.Contains(t, schemas, openAPIKeyPrefix+"Paged.slice.int")
+
+		users := schemas[openAPIKeyPrefix+"GenericHolder"].(map[string]interface{})["properties"].(map[string]interface{})["users"]
+		assert.Equal(t, schema.ComponentsRoot+openAPIKeyPrefix+"Paged."+openAPIKeyPrefix+"GenericUser", users.(map[string]interface{})["$ref"])
+	}
+}
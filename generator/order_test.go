@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type OrderAudit struct {
+	Updated string `json:"updated"`
+	Created string `json:"created"`
+}
+
+type OrderHolder struct {
+	Zebra string `json:"zebra"`
+	OrderAudit
+	Apple  string `json:"apple"`
+	Mango  int    `json:"mango"`
+	Banana bool   `json:"banana"`
+}
+
+type OrderWrapper struct {
+	Inner  OrderHolder  `json:"inner"`
+	Audits []OrderAudit `json:"audits"`
+}
+
+func TestPropertiesKeepDeclarationOrder(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "OrderHolder", opts)
+	assert.NoError(t, err)
+
+	expected := []string{"zebra", "updated", "created", "apple", "mango", "banana"}
+	assert.Equal(t, expected, jsonSchema.(schema.ObjectSchema).GetPropertyOrder())
+
+	generated := schemaAsString(jsonSchema)
+	assert.Equal(t, expected, propertyKeyOrder(generated))
+
+	parsed, err := schema.FromJSON([]byte(generated))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, parsed.(schema.ObjectSchema).GetPropertyOrder())
+}
+
+func TestParsedDefinitionsKeepDeclarationOrder(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "OrderWrapper", opts)
+	assert.NoError(t, err)
+
+	generated := schemaAsString(jsonSchema)
+
+	parsed, err := schema.FromJSON([]byte(generated))
+	assert.NoError(t, err)
+	assert.Equal(t, generated, schemaAsString(parsed))
+
+	// definitions are parsed from nested JSON rather than the top-level object
+	defs := parsed.GetDefinitions()
+	holder := defs["github_com-brainicorn-jsonschemagen-generator-OrderHolder"]
+	assert.Equal(t, []string{"zebra", "updated", "created", "apple", "mango", "banana"}, holder.(schema.ObjectSchema).GetPropertyOrder())
+
+	audit := defs["github_com-brainicorn-jsonschemagen-generator-OrderAudit"]
+	assert.Equal(t, []string{"updated", "created"}, audit.(schema.ObjectSchema).GetPropertyOrder())
+}
+
+func TestPropertiesKeepEmbeddedPositions(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "EmbeddedOwner", opts)
+	assert.NoError(t, err)
+
+	// name on the outer struct hides EmbeddedBase.Name so it's in the outer struct's position
+	assert.Equal(t, []string{"id", "updated", "tagged", "secret", "EmbeddedPet", "EmbeddedLabel", "name"}, jsonSchema.(schema.ObjectSchema).GetPropertyOrder())
+}
+
+func TestPropertyOrderKeywords(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "OrderHolder", opts)
+	assert.NoError(t, err)
+	assert.NotContains(t, schemaAsString(jsonSchema), "propertyOrder")
+
+	opts.PropertyOrder = PropertyOrderKeyword
+	jsonSchema, err = GenerateIt(pkg, "OrderHolder", opts)
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+	assert.Equal(t, 1, props["zebra"].GetExtensions()["propertyOrder"])
+	assert.Equal(t, 6, props["banana"].GetExtensions()["propertyOrder"])
+	assert.Contains(t, schemaAsString(jsonSchema), `"propertyOrder": 4`)
+
+	opts.PropertyOrder = PropertyOrderXOrder
+	jsonSchema, err = GenerateIt(pkg, "OrderHolder", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, jsonSchema.(schema.ObjectSchema).GetProperties()["updated"].GetExtensions()["x-order"])
+
+	opts.SupressXAttrs = true
+	jsonSchema, err = GenerateIt(pkg, "OrderHolder", opts)
+	assert.NoError(t, err)
+	assert.NotContains(t, schemaAsString(jsonSchema), "x-order")
+}
+
+// propertyKeyOrder returns the keys of the top-level properties in the order they appear in the JSON.
+func propertyKeyOrder(schemaJSON string) []string {
+	var raw struct {
+		Properties json.RawMessage `json:"properties"`
+	}
+	json.Unmarshal([]byte(schemaJSON), &raw)
+
+	dec := json.NewDecoder(strings.NewReader(string(raw.Properties)))
+	dec.Token()
+
+	var keys []string
+	for dec.More() {
+		key, _ := dec.Token()
+		keys = append(keys, key.(string))
+
+		var skip json.RawMessage
+		dec.Decode(&skip)
+	}
+
+	return keys
+}
@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const loadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedCompiledGoFiles |
+	packages.NeedImports |
+	packages.NeedDeps |
+	packages.NeedTypes |
+	packages.NeedTypesSizes |
+	packages.NeedSyntax |
+	packages.NeedTypesInfo
+
+// program holds every package reachable from the loaded roots, indexed for the lookups the
+// generator needs while walking the AST. Lookups are safe for concurrent use so that several
+// generators can share a program.
+type program struct {
+	mu       sync.Mutex
+	config   *packages.Config
+	roots    []*packages.Package
+	rootDirs map[string]bool
+	byPath   map[string]*packages.Package
+	byTypes  map[*types.Package]*packages.Package
+}
+
+func newPackagesConfig(options Options) *packages.Config {
+	return &packages.Config{
+		Mode:  loadMode,
+		Tests: options.IncludeTests,
+	}
+}
+
+func loadPackages(cfg *packages.Config, patterns ...string) (*program, error) {
+	prog := &program{
+		config:   cfg,
+		rootDirs: make(map[string]bool),
+		byPath:   make(map[string]*packages.Package),
+		byTypes:  make(map[*types.Package]*packages.Package),
+	}
+
+	cfg.ParseFile = prog.parseFile
+
+	// only the requested packages are roots, packages loaded on demand later are dependencies
+	err := prog.findRootDirs(patterns...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = prog.load(patterns...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return prog, nil
+}
+
+func (p *program) load(patterns ...string) error {
+	roots, err := packages.Load(p.config, patterns...)
+
+	if err != nil {
+		return err
+	}
+
+	if len(roots) < 1 {
+		return fmt.Errorf("no packages found for %s", strings.Join(patterns, ", "))
+	}
+
+	var loadErr error
+	packages.Visit(roots, nil, func(pkg *packages.Package) {
+		for _, pkgErr := range pkg.Errors {
+			// function bodies are dropped outside of the root packages, so type errors such as
+			// unused imports are expected there and are not a problem for generation.
+			if loadErr == nil && (pkgErr.Kind != packages.TypeError || p.isRootPackage(pkg)) {
+				loadErr = pkgErr
+			}
+		}
+
+		p.index(pkg)
+	})
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	p.roots = append(p.roots, roots...)
+
+	return nil
+}
+
+// findRootDirs does a cheap listing of the requested packages so that parseFile knows which files
+// need their function bodies.
+func (p *program) findRootDirs(patterns ...string) error {
+	listCfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles,
+		Tests: p.config.Tests,
+		Dir:   p.config.Dir,
+		Env:   p.config.Env,
+	}
+
+	listed, err := packages.Load(listCfg, patterns...)
+
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range listed {
+		for _, f := range pkg.GoFiles {
+			p.rootDirs[filepath.Dir(f)] = true
+		}
+	}
+
+	return nil
+}
+
+func (p *program) isRootPackage(pkg *packages.Package) bool {
+	for _, f := range pkg.GoFiles {
+		if p.rootDirs[filepath.Dir(f)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseFile parses with comments so annotations are available and drops function bodies in
+// dependency packages since we only ever need their declarations. String and MarshalText methods
+// keep their bodies so enum values can be evaluated.
+func (p *program) parseFile(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+	file, err := goparser.ParseFile(fset, filename, src, goparser.AllErrors|goparser.ParseComments)
+
+	if file != nil && !p.rootDirs[filepath.Dir(filename)] {
+		for _, decl := range file.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && !keepFuncBody(fd) {
+				fd.Body = nil
+			}
+		}
+	}
+
+	return file, err
+}
+
+func keepFuncBody(fd *ast.FuncDecl) bool {
+	return fd.Recv != nil && (fd.Name.Name == "String" || fd.Name.Name == "MarshalText")
+}
+
+func (p *program) index(pkg *packages.Package) {
+	if pkg.Types != nil {
+		p.byTypes[pkg.Types] = pkg
+	}
+
+	// the test variant of a package contains all of the files of the plain variant plus the
+	// _test files, so we prefer whichever has the most files.
+	if existing, found := p.byPath[pkg.PkgPath]; found && len(existing.Syntax) >= len(pkg.Syntax) {
+		return
+	}
+
+	p.byPath[pkg.PkgPath] = pkg
+}
+
+// Package returns the loaded package for the given import path, loading it on demand if it was
+// not part of the dependency graph of the roots.
+func (p *program) Package(path string) (*packages.Package, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pkg, found := p.byPath[path]; found {
+		return pkg, nil
+	}
+
+	if err := p.load(path); err != nil {
+		return nil, fmt.Errorf("error loading package %s: %s", path, err)
+	}
+
+	if pkg, found := p.byPath[path]; found {
+		return pkg, nil
+	}
+
+	return nil, fmt.Errorf("package %s not found", path)
+}
+
+// PackageForTypes returns the loaded package for the given type checker package.
+func (p *program) PackageForTypes(pkg *types.Package) (*packages.Package, error) {
+	if pkg == nil {
+		return nil, fmt.Errorf("no package for types")
+	}
+
+	p.mu.Lock()
+	found, ok := p.byTypes[pkg]
+	p.mu.Unlock()
+
+	if ok {
+		return found, nil
+	}
+
+	return p.Package(pkg.Path())
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type RequiredPolicyHolder struct {
+	Plain     string `json:"plain"`
+	Untagged  string
+	OmitEmpty string  `json:"omitEmpty,omitempty"`
+	OmitZero  string  `json:"omitZero,omitzero"`
+	Pointer   *string `json:"pointer"`
+
+	// @jsonSchema(required=true)
+	ForcedOn string `json:"forcedOn,omitempty"`
+
+	// @jsonSchema(required=false)
+	ForcedOff string `json:"forcedOff"`
+}
+
+func generateRequired(t *testing.T, policy RequiredPolicy) []string {
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.RequiredPolicy = policy
+
+	jsonSchema, err := GenerateIt(pkg, "RequiredPolicyHolder", opts)
+
+	assert.NoError(t, err)
+
+	return jsonSchema.(schema.ObjectSchema).GetRequired()
+}
+
+func TestRequiredAnnotationOnly(t *testing.T) {
+	t.Parallel()
+
+	assert.ElementsMatch(t, []string{"forcedOn"}, generateRequired(t, RequiredAnnotationOnly))
+}
+
+func TestRequiredNotOmitEmpty(t *testing.T) {
+	t.Parallel()
+
+	assert.ElementsMatch(t, []string{"plain", "Untagged", "pointer", "forcedOn"}, generateRequired(t, RequiredNotOmitEmpty))
+}
+
+func TestRequiredNotOmitEmptyOrPointer(t *testing.T) {
+	t.Parallel()
+
+	assert.ElementsMatch(t, []string{"plain", "Untagged", "forcedOn"}, generateRequired(t, RequiredNotOmitEmptyOrPointer))
+}
+
+func TestJSONTagInfo(t *testing.T) {
+	t.Parallel()
+
+	expr, err := parser.ParseExpr("struct{ A int `json:\",omitempty,string\"`; B int `json:\"b,omitzero\"`; C int `json:\"-\"` }")
+	assert.NoError(t, err)
+
+	fields := expr.(*ast.StructType).Fields.List
+
+	a := jsonTagInfo(fields[0])
+	assert.Equal(t, "A", a.name)
+	assert.True(t, a.omitEmpty)
+	assert.True(t, a.asString)
+	assert.False(t, a.omitZero)
+
+	b := jsonTagInfo(fields[1])
+	assert.Equal(t, "b", b.name)
+	assert.True(t, b.omitZero)
+	assert.False(t, b.omitEmpty)
+
+	assert.True(t, jsonTagInfo(fields[2]).ignore)
+}
@@ -0,0 +1,195 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// RootSpec identifies a type to generate a root schema for.
+type RootSpec struct {
+	// Package is the import path of the package the type lives in.
+	Package string
+	// Type is the name of the type within the package.
+	Type string
+}
+
+func (r RootSpec) String() string {
+	return r.Package + "/" + r.Type
+}
+
+// AnnotatedRoot is a root found by FindAnnotatedRoots along with the filename from its annotation,
+// if it had one.
+type AnnotatedRoot struct {
+	RootSpec
+	Filename string
+}
+
+// FindAnnotatedRoots loads the packages matching the patterns, e.g. ./..., and returns every type
+// annotated with @jsonSchema(root=true), sorted by package and type. The loaded packages are kept so
+// a following call to GenerateAll doesn't need to load them again.
+func (g *JSONSchemaGenerator) FindAnnotatedRoots(patterns ...string) ([]AnnotatedRoot, error) {
+	var err error
+	var roots []AnnotatedRoot
+
+	g.program, err = loadPackages(newPackagesConfig(g.options), patterns...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[RootSpec]bool)
+
+	// test variants repeat the decls of the plain package so each root is only kept once
+	for _, pkg := range g.program.roots {
+		forEachTypeSpec(pkg, func(file *ast.File, gd *ast.GenDecl, ts *ast.TypeSpec) bool {
+			spec := RootSpec{Package: pkg.PkgPath, Type: ts.Name.Name}
+			if found[spec] {
+				return true
+			}
+
+			anno, annoErr := g.findJSONSchemaAnnotationForDecl(g.newDeclInfo(pkg, file, gd, ts))
+
+			// types that aren't roots might never be generated, so a bad annotation isn't fatal here
+			if annoErr != nil {
+				g.LogWarnF("skipping %s while looking for roots: %s\n", spec, annoErr)
+				return true
+			}
+
+			if anno != nil && anno.root {
+				if ts.TypeParams != nil {
+					g.LogWarnF("skipping %s, generic types can't be roots\n", spec)
+					return true
+				}
+
+				g.LogVerbose("found annotated root ", spec)
+				found[spec] = true
+				roots = append(roots, AnnotatedRoot{RootSpec: spec, Filename: anno.filename})
+			}
+
+			return true
+		})
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].String() < roots[j].String()
+	})
+
+	return roots, nil
+}
+
+// forEachTypeSpec calls fn with each type declared in the package until fn returns false.
+func forEachTypeSpec(pkg *packages.Package, fn func(file *ast.File, gd *ast.GenDecl, ts *ast.TypeSpec) bool) {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spc := range gd.Specs {
+				if ts, ok := spc.(*ast.TypeSpec); ok && !fn(file, gd, ts) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GenerateAll generates a schema for each of the roots. The packages of all of the roots are
+// loaded and type-checked once and shared by every root, which is much faster than generating
+// each root separately. Each root still gets its own definitions.
+//
+// Roots are generated concurrently when Options.Parallelism is greater than 1. If any root fails,
+// the error for the first failing root in the given order is returned along with the schemas
+// that were generated.
+func (g *JSONSchemaGenerator) GenerateAll(roots []RootSpec) (map[RootSpec]schema.JSONSchema, error) {
+	var err error
+
+	if len(roots) < 1 {
+		return nil, fmt.Errorf("no roots to generate")
+	}
+
+	start := time.Now()
+
+	if g.program == nil {
+		var patterns []string
+		seen := make(map[string]bool)
+
+		for _, root := range roots {
+			if !seen[root.Package] {
+				seen[root.Package] = true
+				patterns = append(patterns, root.Package)
+			}
+		}
+
+		g.program, err = loadPackages(newPackagesConfig(g.options), patterns...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		g.LogInfoF("loaded packages for %d roots in %s\n", len(roots), time.Since(start))
+	}
+
+	schemas := make([]schema.JSONSchema, len(roots))
+	errs := make([]error, len(roots))
+	rootGens := make([]*JSONSchemaGenerator, len(roots))
+
+	workers := g.options.Parallelism
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+
+	for i, root := range roots {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, root RootSpec) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			// a generator per root keeps the definitions of each root separate
+			rootGen := NewJSONSchemaGenerator(root.Package, root.Type, g.options)
+			rootGen.program = g.program
+			rootGens[i] = rootGen
+
+			schemas[i], errs[i] = rootGen.Generate()
+		}(i, root)
+	}
+
+	wg.Wait()
+
+	results := make(map[RootSpec]schema.JSONSchema)
+
+	for i, root := range roots {
+		// instantiations are only found while generating, so SubGenerate needs the ones every root
+		// encountered to find instance definitions
+		for key, inst := range rootGens[i].instanceDecls {
+			g.instanceDecls[key] = inst
+		}
+
+		if errs[i] != nil {
+			if err == nil {
+				err = fmt.Errorf("error generating %s: %s", root, errs[i])
+			}
+			continue
+		}
+
+		results[root] = schemas[i]
+	}
+
+	g.LogInfoF("generated %d roots in %s\n", len(results), time.Since(start))
+
+	return results, err
+}
@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAll(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.Parallelism = 2
+
+	roots := []RootSpec{
+		{Package: pkg, Type: "NullableHolder"},
+		{Package: pkg, Type: "ArrayHolder"},
+		{Package: "net/mail", Type: "Address"},
+	}
+
+	g := NewJSONSchemaGenerator(pkg, "NullableHolder", opts)
+	schemas, err := g.GenerateAll(roots)
+
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 3)
+
+	nullable := schemas[roots[0]].(schema.ObjectSchema)
+	assert.Contains(t, nullable.GetProperties(), "child")
+	assert.Contains(t, nullable.GetDefinitions(), "github_com-brainicorn-jsonschemagen-generator-NullableChild")
+
+	// each root only gets its own definitions
+	arrays := schemas[roots[1]].(schema.ObjectSchema)
+	assert.Contains(t, arrays.GetProperties(), "coordinates")
+	assert.NotContains(t, arrays.GetDefinitions(), "github_com-brainicorn-jsonschemagen-generator-NullableChild")
+
+	address := schemas[roots[2]].(schema.ObjectSchema)
+	assert.Contains(t, address.GetProperties(), "Address")
+
+	// the program is kept for sub generation
+	child, err := g.SubGenerate(pkg, "NullableChild")
+	assert.NoError(t, err)
+	assert.Contains(t, child.(schema.ObjectSchema).GetProperties(), "name")
+}
+
+func TestSubGenerateInstanceAfterGenerateAll(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.Parallelism = 2
+
+	roots := []RootSpec{
+		{Package: pkg, Type: "GenericHolder"},
+		{Package: pkg, Type: "NullableHolder"},
+	}
+
+	g := NewJSONSchemaGenerator(pkg, "GenericHolder", opts)
+	schemas, err := g.GenerateAll(roots)
+
+	assert.NoError(t, err)
+	assert.Contains(t, schemas[roots[0]].GetDefinitions(), genericsDefPrefix+"Paged["+genericsDefPrefix+"GenericUser]")
+
+	// instantiations are only known to the generators of the roots that used them
+	paged, err := g.SubGenerate(pkg, "Paged[github.com/brainicorn/jsonschemagen/generator/GenericUser]")
+	assert.NoError(t, err)
+	assert.Contains(t, paged.(schema.ObjectSchema).GetProperties(), "items")
+}
+
+func TestGenerateAllErrors(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	roots := []RootSpec{
+		{Package: pkg, Type: "ArrayHolder"},
+		{Package: pkg, Type: "NoSuchRoot"},
+	}
+
+	schemas, err := NewJSONSchemaGenerator(pkg, "ArrayHolder", opts).GenerateAll(roots)
+
+	assert.EqualError(t, err, "error generating github.com/brainicorn/jsonschemagen/generator/NoSuchRoot: root not found")
+	assert.Contains(t, schemas, roots[0])
+
+	_, err = NewJSONSchemaGenerator(pkg, "ArrayHolder", opts).GenerateAll(nil)
+	assert.EqualError(t, err, "no roots to generate")
+}
+
+// RootOrder is an order.
+//
+// @jsonSchema(root=true, filename="order.schema.json")
+type RootOrder struct {
+	ID       string        `json:"id"`
+	Customer *RootCustomer `json:"customer"`
+}
+
+// @jsonSchema(root=true)
+type RootCustomer struct {
+	Name string `json:"name"`
+}
+
+// @jsonSchema(root=true)
+type RootGeneric[T any] struct {
+	Value T `json:"value"`
+}
+
+// @jsonSchema(root=false)
+type RootNot struct{}
+
+func TestFindAnnotatedRoots(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	g := NewJSONSchemaGenerator("", "", opts)
+	roots, err := g.FindAnnotatedRoots(pkg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []AnnotatedRoot{
+		{RootSpec: RootSpec{Package: pkg, Type: "RootCustomer"}},
+		{RootSpec: RootSpec{Package: pkg, Type: "RootOrder"}, Filename: "order.schema.json"},
+	}, roots)
+
+	schemas, err := g.GenerateAll([]RootSpec{roots[0].RootSpec, roots[1].RootSpec})
+
+	assert.NoError(t, err)
+
+	order := schemas[roots[1].RootSpec].(schema.ObjectSchema)
+	assert.Equal(t, "RootOrder is an order.", order.GetTitle())
+	assert.Contains(t, order.GetDefinitions(), "github_com-brainicorn-jsonschemagen-generator-RootCustomer")
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type RoundTripCat struct {
+	Meow bool `json:"meow"`
+}
+
+type RoundTripDog struct {
+	Bark bool `json:"bark"`
+}
+
+// @jsonSchema(oneOf=["github.com/brainicorn/jsonschemagen/generator/RoundTripCat", "github.com/brainicorn/jsonschemagen/generator/RoundTripDog"])
+type RoundTripPet interface{}
+
+// RoundTripHolder has a bit of everything the generator emits.
+type RoundTripHolder struct {
+	// @jsonSchema(required=true, minLength=1, maxLength=20, pattern="^[a-z]+$", default="rex")
+	Name string `json:"name"`
+	// @jsonSchema(minimum=0, maximum=10, exclusiveMaximum=true, multipleOf=0.5)
+	Score float64 `json:"score"`
+	// @jsonSchema(nullable=true)
+	Nick    *string            `json:"nick"`
+	Born    time.Time          `json:"born"`
+	Pet     RoundTripPet       `json:"pet"`
+	Counts  map[string]int     `json:"counts"`
+	Friends []RoundTripCat     `json:"friends"`
+	Color   EnumColor          `json:"color"`
+	Lookup  map[string]float64 `json:"lookup,string"`
+	// @jsonSchema(const="v1")
+	Kind string `json:"kind"`
+}
+
+func TestParseIsLosslessForGeneratedSchemas(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	versions := []schema.SpecVersion{
+		schema.SpecVersionDraftV4,
+		schema.SpecVersionDraft06,
+		schema.SpecVersionDraft07,
+		schema.SpecVersionDraft201909,
+		schema.SpecVersionDraft202012,
+	}
+
+	for _, root := range []string{"RoundTripHolder", "EnumHolder", "DependencyPayment", "ArrayHolder", "VersionHolder", "EmbeddedOwner", "NullableHolder"} {
+		jsonSchema, err := GenerateIt(pkg, root, opts)
+		assert.NoError(t, err, root)
+
+		generated := schemaAsString(jsonSchema)
+
+		parsed, err := schema.FromJSON([]byte(generated))
+		assert.NoError(t, err, root)
+
+		assert.Equal(t, generated, schemaAsString(parsed), root)
+
+		// newer drafts rename keywords, the parse keeps them but may move unmodelled ones to the end
+		for _, version := range versions {
+			versioned, err := schema.MarshalForVersion(jsonSchema, version)
+			assert.NoError(t, err, root)
+
+			parsed, err := schema.FromJSON(versioned)
+			assert.NoError(t, err, root)
+
+			marshalled, err := json.Marshal(parsed)
+			assert.NoError(t, err, root)
+
+			assert.JSONEq(t, string(versioned), string(marshalled), "%s as %s", root, version)
+		}
+	}
+}
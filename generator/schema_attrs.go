@@ -1,14 +1,18 @@
 package generator
 
 import (
+	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/doc"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/brainicorn/jsonschemagen/schema"
+
+	"golang.org/x/tools/go/packages"
 )
 
 func (g *JSONSchemaGenerator) shouldReturnRef(decl *declInfo) bool {
@@ -46,7 +50,9 @@ func (g *JSONSchemaGenerator) getDefinitionKey(decl *declInfo) string {
 	}
 
 	if defKey == "" {
-		defKey = g.options.DefinitionPrefix + decl.pkg.Pkg.Path() + "/" + decl.typeSpec.Name.Name
+		defKey = g.options.DefinitionPrefix + g.typePathForDecl(decl)
+	} else {
+		defKey += g.typeArgsSuffix(decl)
 	}
 
 	if strings.Contains(defKey, "/vendor/") {
@@ -229,6 +235,10 @@ func (g *JSONSchemaGenerator) addCommonAttrs(schema schema.JSONSchema, anno *sch
 		schema.SetDescription(anno.description)
 	}
 
+	if err := g.addEnumAndConst(schema, anno, name); err != nil {
+		return err
+	}
+
 	if len(anno.allOf) > 0 {
 		schemas, err := g.generateSchemasFromTypePaths(anno.allOf, parentKey)
 		if err != nil {
@@ -264,6 +274,75 @@ func (g *JSONSchemaGenerator) addCommonAttrs(schema schema.JSONSchema, anno *sch
 	return nil
 }
 
+// addEnumAndConst sets explicit enum and const values, converting them to the schema's JSON type.
+// Draft-04 has no const keyword so it's emitted as a single value enum instead.
+func (g *JSONSchemaGenerator) addEnumAndConst(sch schema.JSONSchema, anno *schemaAnno, name string) error {
+	if len(anno.enum) > 0 {
+		values := make([]interface{}, 0, len(anno.enum))
+		for _, raw := range anno.enum {
+			val, err := typedValueForSchema(sch, raw)
+			if err != nil {
+				return fmt.Errorf("error setting 'enum' for %s: %s", name, err.Error())
+			}
+			values = append(values, val)
+		}
+
+		sch.SetEnum(values)
+		// any detected enum names no longer line up with the values
+		sch.SetEnumNames(nil)
+		sch.SetEnumDescriptions(nil)
+	}
+
+	if anno.constValue != nil {
+		val, err := typedValueForSchema(sch, *anno.constValue)
+		if err != nil {
+			return fmt.Errorf("error setting 'const' for %s: %s", name, err.Error())
+		}
+
+		if g.options.SpecVersion.AtLeast(schema.SpecVersionDraft06) {
+			sch.SetConst(val)
+		} else {
+			sch.SetEnum([]interface{}{val})
+			sch.SetEnumNames(nil)
+			sch.SetEnumDescriptions(nil)
+		}
+	}
+
+	return nil
+}
+
+// typedValueForSchema converts an annotation value to the JSON type of the schema. The literal
+// null is always a JSON null. When the schema doesn't have a single type, the value is decoded
+// as JSON if possible and left as a string otherwise.
+func typedValueForSchema(sch schema.JSONSchema, raw string) (interface{}, error) {
+	if raw == "null" {
+		return nil, nil
+	}
+
+	var jsonType string
+	if sch.GetType() != nil {
+		jsonType = sch.GetType().String
+	}
+
+	switch jsonType {
+	case schema.SchemaTypeString:
+		return raw, nil
+	case schema.SchemaTypeInteger:
+		return strconv.ParseInt(raw, 10, 64)
+	case schema.SchemaTypeNumber:
+		return strconv.ParseFloat(raw, 64)
+	case schema.SchemaTypeBoolean:
+		return strconv.ParseBool(raw)
+	}
+
+	var val interface{}
+	if err := json.Unmarshal([]byte(raw), &val); err == nil {
+		return val, nil
+	}
+
+	return raw, nil
+}
+
 func (g *JSONSchemaGenerator) addStringAttrsForField(schema schema.StringSchema, field *ast.Field) error {
 	if field == nil {
 		return nil
@@ -366,22 +445,27 @@ func (g *JSONSchemaGenerator) addArrayAttrsForField(schema schema.ArraySchema, f
 		return err
 	}
 
-	if schemaAnno == nil {
-		return nil
-	}
+	g.populateArrayAttrs(schema, schemaAnno)
 
-	if schemaAnno.maxItems > 0 {
-		schema.SetMaxItems(schemaAnno.maxItems)
+	return nil
+}
+
+func (g *JSONSchemaGenerator) populateArrayAttrs(schema schema.ArraySchema, anno *schemaAnno) {
+
+	if schema == nil || anno == nil {
+		return
 	}
 
-	if schemaAnno.minItems > -1 {
-		schema.SetMinItems(schemaAnno.minItems)
+	if anno.maxItems > 0 {
+		schema.SetMaxItems(anno.maxItems)
 	}
 
-	schema.SetAdditionalItems(schemaAnno.additionalItems)
-	schema.SetUniqueItems(schemaAnno.uniqueItems)
+	if anno.minItems > -1 {
+		schema.SetMinItems(anno.minItems)
+	}
 
-	return nil
+	schema.SetAdditionalItems(anno.additionalItems)
+	schema.SetUniqueItems(anno.uniqueItems)
 }
 
 func (g *JSONSchemaGenerator) ensureProperTypeForInterfaceField(sch schema.JSONSchema, field *ast.Field) error {
@@ -445,32 +529,235 @@ func (g *JSONSchemaGenerator) addObjectAttrsForDecl(sch schema.ObjectSchema, dec
 	}
 
 	if aprops := schemaAnno.additionalProperties; aprops != nil {
-		if aprops.isBool {
-			sch.SetAdditionalProperties(schema.NewBoolOrSchema(aprops.boolean))
-		} else {
-			schemaItem, err := g.generateSchemaFromTypePath(aprops.path, parentKey)
-			if err != nil {
-				return err
-			}
-			sch.SetAdditionalProperties(schema.NewBoolOrSchema(schemaItem))
+		bos, err := g.additionalPropertiesFromAnno(aprops, parentKey)
+		if err != nil {
+			return err
 		}
+		sch.SetAdditionalProperties(bos)
+	}
+
+	err = g.addPatternProperties(sch, schemaAnno, decl.typeSpec.Name.Name, parentKey)
+	if err != nil {
+		return err
+	}
+
+	err = g.addDependencies(sch, schemaAnno, decl.typeSpec.Name.Name, parentKey)
+	if err != nil {
+		return err
 	}
+
 	return g.addCommonAttrs(sch, schemaAnno, decl.typeSpec.Name.Name, parentKey)
 
 }
 
-func (g *JSONSchemaGenerator) fieldIsRequired(field *ast.Field) bool {
+// addDependencies emits the draft-04 dependencies keyword or the split dependentRequired and
+// dependentSchemas keywords depending on the spec version.
+func (g *JSONSchemaGenerator) addDependencies(sch schema.ObjectSchema, anno *schemaAnno, name string, parentKey string) error {
+	if len(anno.dependencies) < 1 {
+		return nil
+	}
+
+	split := g.options.SpecVersion.AtLeast(schema.SpecVersionDraft201909)
+
+	deps := make(map[string]*schema.StringArrayOrSchema)
+	depRequired := make(map[string][]string)
+	depSchemas := make(map[string]schema.JSONSchema)
+
+	for prop, dep := range anno.dependencies {
+		if dep.path == "" {
+			depRequired[prop] = dep.properties
+			deps[prop] = schema.NewStringArrayOrSchema(dep.properties)
+			continue
+		}
+
+		schemas, err := g.generateSchemasFromTypePaths([]string{dep.path}, parentKey)
+		if err != nil {
+			return fmt.Errorf("error setting 'dependencies' for %s: %s", name, err.Error())
+		}
+		depSchemas[prop] = schemas[0]
+		deps[prop] = schema.NewStringArrayOrSchema(schemas[0])
+	}
+
+	if !split {
+		sch.SetDependencies(deps)
+		return nil
+	}
+
+	if len(depRequired) > 0 {
+		sch.SetDependentRequired(depRequired)
+	}
+
+	if len(depSchemas) > 0 {
+		sch.SetDependentSchemas(depSchemas)
+	}
+
+	return nil
+}
+
+func (g *JSONSchemaGenerator) addPatternProperties(sch schema.ObjectSchema, anno *schemaAnno, name string, parentKey string) error {
+	if len(anno.patternProperties) < 1 {
+		return nil
+	}
+
+	patternProps := sch.GetPatternProperties()
+	if patternProps == nil {
+		patternProps = make(map[string]schema.JSONSchema)
+	}
+
+	for pattern, path := range anno.patternProperties {
+		schemas, err := g.generateSchemasFromTypePaths([]string{path}, parentKey)
+		if err != nil {
+			return fmt.Errorf("error setting 'patternProperties' for %s: %s", name, err.Error())
+		}
+		patternProps[pattern] = schemas[0]
+	}
+
+	sch.SetPatternProperties(patternProps)
+
+	return nil
+}
+
+func (g *JSONSchemaGenerator) additionalPropertiesFromAnno(aprops *boolOrPath, parentKey string) (*schema.BoolOrSchema, error) {
+	if aprops.isBool {
+		return schema.NewBoolOrSchema(aprops.boolean), nil
+	}
+
+	schemaItem, err := g.generateSchemaFromTypePath(aprops.path, parentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.NewBoolOrSchema(schemaItem), nil
+}
+
+// findAdditionalPropertiesAnno returns the additionalProperties annotation value for a map field,
+// falling back to the one on the declared type.
+func (g *JSONSchemaGenerator) findAdditionalPropertiesAnno(decl *declInfo, field *ast.Field) (*boolOrPath, error) {
+	if field != nil {
+		fieldAnno, err := g.findJSONSchemaAnnotationForField(field)
+		if err != nil {
+			return nil, err
+		}
+
+		if fieldAnno != nil && fieldAnno.additionalProperties != nil {
+			return fieldAnno.additionalProperties, nil
+		}
+	}
+
+	if decl == nil {
+		return nil, nil
+	}
+
+	declAnno, err := g.findJSONSchemaAnnotationForDecl(decl)
+	if err != nil {
+		return nil, err
+	}
+
+	if declAnno != nil {
+		return declAnno.additionalProperties, nil
+	}
+
+	return nil, nil
+}
+
+// fieldIsRequired checks the field's annotation first and falls back to the RequiredPolicy.
+func (g *JSONSchemaGenerator) fieldIsRequired(field *ast.Field, tag jsonTag) bool {
 	anno, err := g.findJSONSchemaAnnotationForField(field)
 
 	if err != nil {
 		return false
 	}
 
-	if anno == nil {
-		return false
+	if anno != nil && anno.required != nil {
+		return *anno.required
 	}
 
-	return anno.required
+	switch g.options.RequiredPolicy {
+	case RequiredNotOmitEmpty:
+		return !tag.omitEmpty && !tag.omitZero
+
+	case RequiredNotOmitEmptyOrPointer:
+		_, isPointer := field.Type.(*ast.StarExpr)
+		return !tag.omitEmpty && !tag.omitZero && !isPointer
+	}
+
+	return false
+}
+
+// nullableStyleForField returns the style to use if the field accepts null. The field's annotation
+// is checked first and falls back to marking pointers nullable using the NullablePointers option.
+func (g *JSONSchemaGenerator) nullableStyleForField(field *ast.Field) NullableStyle {
+	anno, err := g.findJSONSchemaAnnotationForField(field)
+
+	if err != nil {
+		return NullableNone
+	}
+
+	_, isPointer := field.Type.(*ast.StarExpr)
+	nullable := isPointer
+
+	if anno != nil && anno.nullable != nil {
+		nullable = *anno.nullable
+	}
+
+	switch {
+	case !nullable:
+		return NullableNone
+	case g.options.NullablePointers != NullableNone:
+		return g.options.NullablePointers
+	case anno != nil && anno.nullable != nil:
+		// explicitly nullable fields still need a style when pointers aren't nullable
+		return NullableTypeArray
+	}
+
+	return NullableNone
+}
+
+// makeNullable returns a copy of the schema that also accepts null. Schemas that have a $ref or no
+// single type are wrapped since adding null to them isn't possible.
+func makeNullable(sch schema.JSONSchema, style NullableStyle) schema.JSONSchema {
+	_, hasConst := sch.GetConst()
+	canAddNull := sch.GetRef() == "" && sch.GetType() != nil && !hasConst
+
+	if !canAddNull {
+		wrapper := schema.NewBasicSchema("")
+
+		if style == NullableOpenAPI {
+			wrapper.SetAllOf([]schema.JSONSchema{sch})
+			wrapper.SetNullable(true)
+			return wrapper
+		}
+
+		wrapper.SetAnyOf([]schema.JSONSchema{sch, schema.NewBasicSchema(schema.SchemaTypeNull)})
+		return wrapper
+	}
+
+	nullable := sch.Clone()
+
+	// null has to be one of the enum values too or it would still be rejected
+	if enum := nullable.GetEnum(); len(enum) > 0 {
+		nullable.SetEnum(append(append([]interface{}{}, enum...), nil))
+	}
+
+	if style == NullableOpenAPI {
+		nullable.SetNullable(true)
+		return nullable
+	}
+
+	types := nullable.GetType().Array
+	if len(types) == 0 {
+		types = []string{nullable.GetType().String}
+	}
+
+	for _, t := range types {
+		if t == schema.SchemaTypeNull {
+			return nullable
+		}
+	}
+
+	nullable.SetType(strings.Join(append(types, schema.SchemaTypeNull), ","))
+
+	return nullable
 }
 
 func (g *JSONSchemaGenerator) findJSONSchemaAnnotationForField(field *ast.Field) (*schemaAnno, error) {
@@ -567,17 +854,23 @@ func (g *JSONSchemaGenerator) generateSchemaFromTypePath(path string, parentKey
 	if isIdent(path) {
 		if tmpSchema, ok, err = g.generateSchemaForBuiltIn(path, nil, parentKey); ok {
 			schemaItem = tmpSchema
+		} else if err == nil {
+			err = fmt.Errorf("'%s' is not a built-in type", path)
 		}
 	} else {
+		var pkgInfo *packages.Package
+
 		pkgPath, typeName := splitPackageTypePath(path)
-		pkgInfo := g.program.Package(pkgPath)
-		if pkgInfo != nil {
-			typeDecl, err = g.findDeclInfoForPackage(pkgInfo, nil, typeName)
+		pkgInfo, err = g.program.Package(pkgPath)
+		if err != nil {
+			return nil, err
+		}
+
+		typeDecl, err = g.findDeclInfoForPackage(pkgInfo, nil, typeName)
+		if err == nil {
+			tmpSchema, err = g.generateSchemaForExpr(typeDecl, typeDecl.typeSpec.Type, nil, parentKey)
 			if err == nil {
-				tmpSchema, err = g.generateSchemaForExpr(typeDecl, typeDecl.typeSpec.Type, nil, parentKey)
-				if err == nil {
-					schemaItem = tmpSchema
-				}
+				schemaItem = tmpSchema
 			}
 		}
 	}
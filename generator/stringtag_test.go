@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type StringTagHolder struct {
+	// @jsonSchema(minimum=1, maximum=1000)
+	ID      int64     `json:"id,string"`
+	Count   uint      `json:"count,string"`
+	Ratio   *float64  `json:"ratio,string"`
+	Enabled bool      `json:"enabled,string"`
+	Level   EnumLevel `json:"level,string"`
+	Name    string    `json:"name,string"`
+	Plain   int64     `json:"plain"`
+}
+
+func TestStringTagOption(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "StringTagHolder", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	id := props["id"].(schema.NumericStringSchema)
+	assert.Equal(t, schema.SchemaTypeString, id.GetType().String)
+	assert.Equal(t, "^-?[0-9]+$", id.GetPattern())
+	assert.Equal(t, "int64", id.GetFormat())
+	assert.Equal(t, float64(1), id.GetMinimum())
+	assert.Equal(t, float64(1000), id.GetMaximum())
+
+	count := props["count"].(schema.StringSchema)
+	assert.Equal(t, "^[0-9]+$", count.GetPattern())
+
+	ratio := props["ratio"].(schema.StringSchema)
+	assert.Equal(t, schema.SchemaTypeString, ratio.GetType().String)
+	assert.Equal(t, "double", ratio.GetFormat())
+
+	enabled := props["enabled"]
+	assert.Equal(t, schema.SchemaTypeString, enabled.GetType().String)
+	assert.Equal(t, []interface{}{"true", "false"}, enabled.GetEnum())
+
+	level := props["level"]
+	assert.Equal(t, schema.SchemaTypeString, level.GetType().String)
+	assert.Equal(t, []interface{}{"0", "1", "2"}, level.GetEnum())
+
+	assert.Equal(t, schema.SchemaTypeString, props["name"].GetType().String)
+	assert.Equal(t, schema.SchemaTypeInteger, props["plain"].GetType().String)
+}
@@ -0,0 +1,277 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+)
+
+// structField is a property candidate found while walking a struct and its embedded structs.
+type structField struct {
+	owner *declInfo
+	field *ast.Field
+	tag   jsonTag
+	depth int
+	// index is the position of the field in its struct, preceded by the positions of the embedded
+	// fields that lead to it. Sorting by index gives declaration order.
+	index []int
+}
+
+// collectStructFields walks the struct for the decl and its embedded structs breadth first,
+// following the same visibility rules as encoding/json. Untagged embedded structs are flattened
+// one level deeper, while tagged embedded structs and embedded non-struct types become regular
+// properties named after their type.
+func (g *JSONSchemaGenerator) collectStructFields(decl *declInfo) ([]*structField, error) {
+	var fields []*structField
+
+	visited := make(map[string]bool)
+	current := []*declInfo{decl}
+	count := map[string]int{decl.defKey: 1}
+	indexes := make(map[string][]int)
+
+	for depth := 0; len(current) > 0; depth++ {
+		var next []*declInfo
+		nextCount := make(map[string]int)
+
+		for _, structDecl := range current {
+			if visited[structDecl.defKey] {
+				continue
+			}
+			visited[structDecl.defKey] = true
+
+			for i, field := range structDecl.typeSpec.Type.(*ast.StructType).Fields.List {
+				var sf *structField
+
+				index := make([]int, len(indexes[structDecl.defKey]), len(indexes[structDecl.defKey])+1)
+				copy(index, indexes[structDecl.defKey])
+				index = append(index, i)
+
+				if len(field.Names) == 0 {
+					g.LogVerbose("processing field without a name, must be embedded...")
+					embeddedField, embeddedDecl, err := g.embeddedStructField(structDecl, field)
+
+					if err != nil {
+						return nil, err
+					}
+
+					if embeddedDecl != nil {
+						nextCount[embeddedDecl.defKey]++
+						if nextCount[embeddedDecl.defKey] == 1 {
+							next = append(next, embeddedDecl)
+						}
+						if _, found := indexes[embeddedDecl.defKey]; !found {
+							indexes[embeddedDecl.defKey] = index
+						}
+						continue
+					}
+
+					sf = embeddedField
+				} else if field.Names[0] != nil && field.Names[0].IsExported() {
+					tag := jsonTagInfo(field)
+					if !tag.ignore {
+						sf = &structField{owner: structDecl, field: field, tag: tag}
+					}
+				}
+
+				if sf == nil {
+					continue
+				}
+
+				sf.depth = depth
+				sf.index = index
+				fields = append(fields, sf)
+
+				// a struct embedded more than once at the same depth makes all of its fields
+				// ambiguous, so add a duplicate to make sure they get dropped.
+				if count[structDecl.defKey] > 1 {
+					fields = append(fields, sf)
+				}
+			}
+		}
+
+		current = next
+		count = nextCount
+	}
+
+	return fields, nil
+}
+
+// embeddedStructField returns either the decl of an embedded struct that should be flattened or a
+// structField for an embedded type that is treated as a regular property. Both are nil if the
+// embedded field is ignored.
+func (g *JSONSchemaGenerator) embeddedStructField(ownerDecl *declInfo, field *ast.Field) (*structField, *declInfo, error) {
+	typeName := embeddedTypeName(field.Type)
+	if typeName == "" {
+		return nil, nil, fmt.Errorf("unable to resolve embedded type for: %s", types.ExprString(field.Type))
+	}
+
+	// give the field the name of its type so it can be treated like any other field
+	namedField := *field
+	namedField.Names = []*ast.Ident{ast.NewIdent(typeName)}
+
+	tag := jsonTagInfo(&namedField)
+	if tag.ignore {
+		return nil, nil, nil
+	}
+
+	isStruct := g.isStructExpr(ownerDecl, field.Type)
+
+	if isStruct && !tag.tagged {
+		embeddedDecl, err := g.findEmbeddedDecl(ownerDecl, field.Type)
+		return nil, embeddedDecl, err
+	}
+
+	if !isStruct && !ast.IsExported(typeName) {
+		g.LogVerboseF("ignoring embedded field of unexported non-struct type %s\n", typeName)
+		return nil, nil, nil
+	}
+
+	return &structField{owner: ownerDecl, field: &namedField, tag: tag}, nil, nil
+}
+
+func embeddedTypeName(expr ast.Expr) string {
+	switch embeddedType := expr.(type) {
+	case *ast.Ident:
+		return embeddedType.Name
+	case *ast.SelectorExpr:
+		return embeddedType.Sel.Name
+	case *ast.StarExpr:
+		return embeddedTypeName(embeddedType.X)
+	case *ast.IndexExpr:
+		return embeddedTypeName(embeddedType.X)
+	case *ast.IndexListExpr:
+		return embeddedTypeName(embeddedType.X)
+	}
+
+	return ""
+}
+
+// isStructExpr returns true if the expression is a struct type or a pointer to one.
+func (g *JSONSchemaGenerator) isStructExpr(ownerDecl *declInfo, expr ast.Expr) bool {
+	exprType := ownerDecl.pkg.TypesInfo.TypeOf(expr)
+	if exprType == nil {
+		// fall back to the declaration if the type checker didn't record the type
+		embeddedDecl, err := g.findEmbeddedDecl(ownerDecl, expr)
+		return err == nil && embeddedDecl != nil
+	}
+
+	if ptr, isPtr := exprType.Underlying().(*types.Pointer); isPtr {
+		exprType = ptr.Elem()
+	}
+
+	_, isStruct := exprType.Underlying().(*types.Struct)
+
+	return isStruct
+}
+
+func (g *JSONSchemaGenerator) findEmbeddedDecl(ownerDecl *declInfo, expr ast.Expr) (*declInfo, error) {
+	var embeddedDecl *declInfo
+	var err error
+
+	switch embeddedType := expr.(type) {
+	case *ast.Ident:
+		g.LogVerbose("embedded type is ident")
+
+		embeddedDecl, err = g.findDeclInfoForPackage(ownerDecl.pkg, ownerDecl.file, embeddedType.Name)
+
+	case *ast.SelectorExpr:
+		g.LogVerbose("embedded type is SelectorExpr")
+
+		embeddedDecl, err = g.findDeclInfoForSelector(ownerDecl, embeddedType)
+
+	case *ast.IndexExpr:
+		g.LogVerbose("embedded type is a generic instantiation")
+
+		embeddedDecl, err = g.findDeclInfoForInstance(ownerDecl, embeddedType.X, []ast.Expr{embeddedType.Index})
+
+	case *ast.IndexListExpr:
+		g.LogVerbose("embedded type is a generic instantiation")
+
+		embeddedDecl, err = g.findDeclInfoForInstance(ownerDecl, embeddedType.X, embeddedType.Indices)
+
+	case *ast.StarExpr:
+		return g.findEmbeddedDecl(ownerDecl, embeddedType.X)
+	}
+
+	if err == nil && (embeddedDecl == nil || !isStructDecl(embeddedDecl)) {
+		err = fmt.Errorf("unable to resolve embedded struct for: %s", types.ExprString(expr))
+	}
+
+	return embeddedDecl, err
+}
+
+func isStructDecl(decl *declInfo) bool {
+	_, ok := decl.typeSpec.Type.(*ast.StructType)
+
+	return ok
+}
+
+// dominantFields applies encoding/json's precedence rules to the collected fields. For each json
+// name the shallowest field wins. If there are several at that depth, a single tagged field wins,
+// otherwise the name is ambiguous and dropped entirely. The dominant fields are returned in
+// declaration order, with the fields of embedded structs at the position of the embedded field.
+func (g *JSONSchemaGenerator) dominantFields(fields []*structField, structName string) []*structField {
+	var names []string
+	byName := make(map[string][]*structField)
+
+	for _, sf := range fields {
+		if _, found := byName[sf.tag.name]; !found {
+			names = append(names, sf.tag.name)
+		}
+		byName[sf.tag.name] = append(byName[sf.tag.name], sf)
+	}
+
+	dominant := make([]*structField, 0, len(names))
+
+	for _, name := range names {
+		candidates := byName[name]
+
+		// fields are collected breadth first so the first one is always the shallowest
+		var shallowest []*structField
+		for _, sf := range candidates {
+			if sf.depth == candidates[0].depth {
+				shallowest = append(shallowest, sf)
+			}
+		}
+
+		if len(shallowest) == 1 {
+			if len(candidates) > 1 {
+				g.LogVerboseF("%s: field %s hides %d deeper fields named '%s'\n", structName, shallowest[0].field.Names[0].Name, len(candidates)-1, name)
+			}
+			dominant = append(dominant, shallowest[0])
+			continue
+		}
+
+		var tagged []*structField
+		for _, sf := range shallowest {
+			if sf.tag.tagged {
+				tagged = append(tagged, sf)
+			}
+		}
+
+		if len(tagged) == 1 {
+			g.LogWarnF("%s: %d fields use the json name '%s', using the tagged field %s\n", structName, len(shallowest), name, tagged[0].field.Names[0].Name)
+			dominant = append(dominant, tagged[0])
+			continue
+		}
+
+		g.LogWarnF("%s: %d fields use the json name '%s' at the same depth, the property will be dropped\n", structName, len(shallowest), name)
+	}
+
+	sort.SliceStable(dominant, func(i, j int) bool {
+		return indexLess(dominant[i].index, dominant[j].index)
+	})
+
+	return dominant
+}
+
+func indexLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
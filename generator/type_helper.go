@@ -3,13 +3,15 @@ package generator
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
+	"go/types"
 	"reflect"
 	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
-	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/packages"
 )
 
 var builtinTypes = map[string]string{
@@ -27,6 +29,8 @@ var builtinTypes = map[string]string{
 	"uint16":    "integer",
 	"uint32":    "integer",
 	"uint64":    "integer",
+	"byte":      "integer",
+	"rune":      "integer",
 	"time.Time": "string",
 	"net.IP":    "string",
 	"url.URL":   "string",
@@ -37,11 +41,11 @@ var jsonTypes = map[string][]string{
 	"string":  []string{"string", "time.Time", "net.IP", "url.URL", "[]byte"},
 	"boolean": []string{"bool"},
 	"number":  []string{"float32", "float64"},
-	"integer": []string{"int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64"},
+	"integer": []string{"int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune"},
 	"array":   []string{},
 }
 
-func (g *JSONSchemaGenerator) findDeclInfoForPackage(pkg *loader.PackageInfo, file *ast.File, typeToFind string) (*declInfo, error) {
+func (g *JSONSchemaGenerator) findDeclInfoForPackage(pkg *packages.Package, file *ast.File, typeToFind string) (*declInfo, error) {
 	g.LogDebug("looking for Decl Info...")
 	var dInfo *declInfo
 
@@ -56,7 +60,7 @@ func (g *JSONSchemaGenerator) findDeclInfoForPackage(pkg *loader.PackageInfo, fi
 	}
 
 	// didn't find it in the current file, look through the rest of the current Package files
-	for _, packageFile := range pkg.Files {
+	for _, packageFile := range pkg.Syntax {
 		if packageFile == file {
 			continue
 		}
@@ -69,15 +73,235 @@ func (g *JSONSchemaGenerator) findDeclInfoForPackage(pkg *loader.PackageInfo, fi
 		}
 	}
 
-	return nil, fmt.Errorf("could not find type '%s' in package %s", typeToFind, pkg.Pkg.Path())
+	return nil, fmt.Errorf("could not find type '%s' in package %s", typeToFind, pkg.PkgPath)
 }
 
 func (g *JSONSchemaGenerator) findDeclInfoForSelector(ownerDecl *declInfo, selector *ast.SelectorExpr) (*declInfo, error) {
+	obj, found := ownerDecl.pkg.TypesInfo.Uses[selector.Sel]
 
-	return g.findDeclInfoForPackage(g.program.AllPackages[ownerDecl.pkg.Uses[selector.Sel].Pkg()], nil, selector.Sel.Name)
+	if !found || obj.Pkg() == nil {
+		return nil, fmt.Errorf("could not resolve package for selector %s", types.ExprString(selector))
+	}
+
+	pkg, err := g.program.PackageForTypes(obj.Pkg())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return g.findDeclInfoForPackage(pkg, nil, selector.Sel.Name)
+}
+
+// findDeclInfoForInstance resolves a generic instantiation like Page[User] to a decl for the generic
+// type that carries the type arguments so they can be substituted when walking its fields.
+func (g *JSONSchemaGenerator) findDeclInfoForInstance(ownerDecl *declInfo, genericExpr ast.Expr, argExprs []ast.Expr) (*declInfo, error) {
+	var ident *ast.Ident
+
+	switch genericType := genericExpr.(type) {
+	case *ast.Ident:
+		ident = genericType
+	case *ast.SelectorExpr:
+		ident = genericType.Sel
+	default:
+		return nil, fmt.Errorf("unsupported generic type expression %s", types.ExprString(genericExpr))
+	}
+
+	instance, found := ownerDecl.pkg.TypesInfo.Instances[ident]
+	if !found {
+		return nil, fmt.Errorf("could not resolve generic instantiation of %s", types.ExprString(genericExpr))
+	}
+
+	named, ok := instance.Type.(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a generic named type", types.ExprString(genericExpr))
+	}
+
+	origin := named.Origin()
+	tparams := origin.TypeParams()
+
+	if tparams.Len() != len(argExprs) {
+		return nil, fmt.Errorf("wrong number of type arguments for %s: expected %d, got %d", origin.Obj().Name(), tparams.Len(), len(argExprs))
+	}
+
+	pkg, err := g.program.PackageForTypes(origin.Obj().Pkg())
+	if err != nil {
+		return nil, err
+	}
+
+	genericDecl, err := g.findDeclInfoForPackage(pkg, nil, origin.Obj().Name())
+	if err != nil {
+		return nil, err
+	}
+
+	instDecl := &declInfo{
+		pkg:      genericDecl.pkg,
+		file:     genericDecl.file,
+		decl:     genericDecl.decl,
+		typeSpec: genericDecl.typeSpec,
+		typeArgs: make(map[string]*typeArg),
+	}
+
+	for i, argExpr := range argExprs {
+		instDecl.typeArgs[tparams.At(i).Obj().Name()] = &typeArg{
+			expr:  argExpr,
+			owner: ownerDecl,
+		}
+	}
+
+	instDecl.defKey = g.getDefinitionKey(instDecl)
+
+	if cached, found := g.instanceDecls[instDecl.defKey]; found {
+		return cached, nil
+	}
+
+	g.instanceDecls[instDecl.defKey] = instDecl
+
+	return instDecl, nil
+}
+
+// typePathForDecl returns the fully-qualified type path for the decl including any type arguments.
+func (g *JSONSchemaGenerator) typePathForDecl(decl *declInfo) string {
+	return decl.pkg.PkgPath + "/" + decl.typeSpec.Name.Name + g.typeArgsSuffix(decl)
+}
+
+func (g *JSONSchemaGenerator) typeArgsSuffix(decl *declInfo) string {
+	if len(decl.typeArgs) == 0 {
+		return ""
+	}
+
+	var args []string
+	for _, name := range typeParamNames(decl.typeSpec) {
+		arg := decl.typeArgs[name]
+		args = append(args, g.typeArgPath(arg.expr, arg.owner))
+	}
+
+	return "[" + strings.Join(args, ",") + "]"
 }
 
-func (g *JSONSchemaGenerator) findDeclInFile(pkgInfo *loader.PackageInfo, gofile *ast.File, typeToFind string) *declInfo {
+// typeArgPath returns a stable path for a type argument, substituting any type parameters of the
+// owner with their own arguments.
+func (g *JSONSchemaGenerator) typeArgPath(expr ast.Expr, owner *declInfo) string {
+	switch argType := expr.(type) {
+	case *ast.Ident:
+		if arg, found := owner.typeArgs[argType.Name]; found {
+			return g.typeArgPath(arg.expr, arg.owner)
+		}
+
+		return typeNamePath(owner.pkg.TypesInfo.Uses[argType], argType.Name)
+
+	case *ast.SelectorExpr:
+		return typeNamePath(owner.pkg.TypesInfo.Uses[argType.Sel], types.ExprString(argType))
+
+	case *ast.StarExpr:
+		return "*" + g.typeArgPath(argType.X, owner)
+
+	case *ast.ArrayType:
+		if argType.Len == nil {
+			return "[]" + g.typeArgPath(argType.Elt, owner)
+		}
+
+		// fixed-size arrays are constrained to their length so it's part of the instance
+		if length, ok := arrayLength(owner, argType); ok {
+			return fmt.Sprintf("[%d]", length) + g.typeArgPath(argType.Elt, owner)
+		}
+
+		return "[" + types.ExprString(argType.Len) + "]" + g.typeArgPath(argType.Elt, owner)
+
+	case *ast.MapType:
+		return "map[" + g.typeArgPath(argType.Key, owner) + "]" + g.typeArgPath(argType.Value, owner)
+
+	case *ast.IndexExpr:
+		return g.typeArgPath(argType.X, owner) + "[" + g.typeArgPath(argType.Index, owner) + "]"
+
+	case *ast.IndexListExpr:
+		var args []string
+		for _, index := range argType.Indices {
+			args = append(args, g.typeArgPath(index, owner))
+		}
+
+		return g.typeArgPath(argType.X, owner) + "[" + strings.Join(args, ",") + "]"
+	}
+
+	return types.ExprString(expr)
+}
+
+// arrayLength returns the constant length of a fixed-size array type. Slices return false.
+func arrayLength(ownerDecl *declInfo, arrayType *ast.ArrayType) (int64, bool) {
+	if arrayType.Len == nil {
+		return 0, false
+	}
+
+	if arr, ok := ownerDecl.pkg.TypesInfo.TypeOf(arrayType).(*types.Array); ok {
+		return arr.Len(), true
+	}
+
+	// fall back to the length expression if the type checker didn't record the array type
+	if tv, ok := ownerDecl.pkg.TypesInfo.Types[arrayType.Len]; ok && tv.Value != nil {
+		return constant.Int64Val(constant.ToInt(tv.Value))
+	}
+
+	return 0, false
+}
+
+// isByteSlice checks if the type is a slice of bytes which encoding/json writes as a base64 string.
+// Fixed-size byte arrays are not included since encoding/json writes them as arrays of numbers.
+// Like encoding/json, byte types that implement a marshaler keep the array encoding.
+func isByteSlice(ownerDecl *declInfo, arrayType *ast.ArrayType) bool {
+	if arrayType.Len != nil {
+		return false
+	}
+
+	elemType := ownerDecl.pkg.TypesInfo.TypeOf(arrayType.Elt)
+	if elemType == nil {
+		return types.ExprString(arrayType.Elt) == "byte"
+	}
+
+	if basic, ok := elemType.Underlying().(*types.Basic); !ok || basic.Kind() != types.Uint8 {
+		return false
+	}
+
+	if named, ok := elemType.(*types.Named); ok {
+		return !isJSONMarshaler(named) && !isTextMarshaler(named)
+	}
+
+	return true
+}
+
+func typeNamePath(obj types.Object, fallback string) string {
+	if tn, ok := obj.(*types.TypeName); ok && tn.Pkg() != nil {
+		return tn.Pkg().Path() + "/" + tn.Name()
+	}
+
+	return fallback
+}
+
+func typeParamNames(spec *ast.TypeSpec) []string {
+	var names []string
+
+	if spec.TypeParams == nil {
+		return names
+	}
+
+	for _, param := range spec.TypeParams.List {
+		for _, name := range param.Names {
+			names = append(names, name.Name)
+		}
+	}
+
+	return names
+}
+
+func isTypeParam(spec *ast.TypeSpec, name string) bool {
+	for _, paramName := range typeParamNames(spec) {
+		if paramName == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *JSONSchemaGenerator) findDeclInFile(pkgInfo *packages.Package, gofile *ast.File, typeToFind string) *declInfo {
 	for _, decl := range gofile.Decls {
 		gd, ok := decl.(*ast.GenDecl)
 		if !ok {
@@ -96,6 +320,34 @@ func (g *JSONSchemaGenerator) findDeclInFile(pkgInfo *loader.PackageInfo, gofile
 	return nil
 }
 
+// namedTypeForDecl returns the type checker's named type for the decl. For generic types this is
+// the uninstantiated origin type.
+func namedTypeForDecl(decl *declInfo) *types.Named {
+	if decl.pkg == nil || decl.pkg.Types == nil {
+		return nil
+	}
+
+	typeName, ok := decl.pkg.Types.Scope().Lookup(decl.typeSpec.Name.Name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+
+	named, _ := typeName.Type().(*types.Named)
+
+	return named
+}
+
+func isEmptyInterface(expr ast.Expr) bool {
+	switch exprType := expr.(type) {
+	case *ast.InterfaceType:
+		return exprType.Methods == nil || len(exprType.Methods.List) == 0
+	case *ast.Ident:
+		return exprType.Name == "any"
+	}
+
+	return false
+}
+
 func isJSONType(name string) bool {
 	_, ok := jsonTypes[name]
 
@@ -148,36 +400,55 @@ func splitPackageTypePath(path string) (string, string) {
 	return path[:strings.LastIndex(path, "/")], path[strings.LastIndex(path, "/")+1:]
 }
 
-func jsonTagInfo(field *ast.Field) (string, bool) {
-	var jsonTag, jsonName string
-	name := field.Names[0].Name
+// jsonTag holds the name and options from a field's json struct tag.
+type jsonTag struct {
+	name      string
+	tagged    bool
+	ignore    bool
+	omitEmpty bool
+	omitZero  bool
+	asString  bool
+}
+
+func jsonTagInfo(field *ast.Field) jsonTag {
+	var jsonTagValue, jsonName string
+	tag := jsonTag{name: field.Names[0].Name}
 
 	if field.Tag != nil && len(strings.TrimSpace(field.Tag.Value)) > 0 {
 		tagLiteral, err := strconv.Unquote(field.Tag.Value)
 		if err != nil {
-			return name, false
+			return tag
 		}
 
 		if strings.TrimSpace(tagLiteral) != "" {
-			jsonTag = reflect.StructTag(tagLiteral).Get("json")
+			jsonTagValue = reflect.StructTag(tagLiteral).Get("json")
 
 			// ignore the field entirely
-			if jsonTag == "-" {
-				return "", true
+			if jsonTagValue == "-" {
+				return jsonTag{ignore: true}
 			}
 
-			if idx := strings.Index(jsonTag, ","); idx != -1 {
-				jsonName = jsonTag[:idx]
-			} else {
-				jsonName = jsonTag
+			parts := strings.Split(jsonTagValue, ",")
+			jsonName = parts[0]
+
+			for _, opt := range parts[1:] {
+				switch strings.TrimSpace(opt) {
+				case "omitempty":
+					tag.omitEmpty = true
+				case "omitzero":
+					tag.omitZero = true
+				case "string":
+					tag.asString = true
+				}
 			}
 
 			if jsonName != "" {
-				name = jsonName
+				tag.name = jsonName
+				tag.tagged = true
 			}
 
 		}
 	}
 
-	return name, false
+	return tag
 }
@@ -0,0 +1,77 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// defaultTypeMappings returns the schemas for types whose JSON form can't be derived from their
+// GO declaration. Entries in Options.TypeMappings take precedence over these.
+func defaultTypeMappings(options Options) map[string]schema.JSONSchema {
+	return map[string]schema.JSONSchema{
+		"encoding/json/RawMessage": schema.NewMapSchema(options.SupressXAttrs),
+	}
+}
+
+// ParseTypeMappings parses a JSON object that maps fully-qualified GO type paths such as
+// github.com/shopspring/decimal/Decimal to the schema to use for that type.
+func ParseTypeMappings(data []byte) (map[string]schema.JSONSchema, error) {
+	var raw map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	mappings := make(map[string]schema.JSONSchema)
+
+	for path, rawSchema := range raw {
+		if !isPackageType(path) {
+			return nil, fmt.Errorf("type mapping key '%s' is not a fully-qualified type path", path)
+		}
+
+		sch, err := schema.FromJSON(rawSchema)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing type mapping for %s: %s", path, err)
+		}
+
+		mappings[path] = sch
+	}
+
+	return mappings, nil
+}
+
+// typeMappingForSelector returns the mapped schema for the package type the selector refers to.
+func (g *JSONSchemaGenerator) typeMappingForSelector(ownerDecl *declInfo, selector *ast.SelectorExpr, field *ast.Field) (schema.JSONSchema, bool, error) {
+	path := typeNamePath(ownerDecl.pkg.TypesInfo.Uses[selector.Sel], "")
+	if path == "" {
+		return nil, false, nil
+	}
+
+	return g.typeMappingForPath(path, field)
+}
+
+// typeMappingForPath returns a copy of the mapped schema for the type path with any string or
+// numeric attrs from the field's annotation applied.
+func (g *JSONSchemaGenerator) typeMappingForPath(path string, field *ast.Field) (schema.JSONSchema, bool, error) {
+	mapped, found := g.typeMappings[path]
+	if !found {
+		return nil, false, nil
+	}
+
+	g.LogVerbose("using type mapping for ", path)
+
+	var err error
+	sch := mapped.Clone()
+
+	switch typed := sch.(type) {
+	case schema.NumericSchema:
+		err = g.addNumericAttrsForField(typed, field)
+	case schema.StringSchema:
+		err = g.addStringAttrsForField(typed, field)
+	}
+
+	return sch, true, err
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MappedDecimal struct {
+	value int64
+	exp   int32
+}
+
+type MappedHolder struct {
+	Amount MappedDecimal `json:"amount"`
+	// @jsonSchema(maxLength=20)
+	Limit    *MappedDecimal  `json:"limit"`
+	Day      time.Time       `json:"day"`
+	Duration time.Duration   `json:"duration"`
+	Raw      json.RawMessage `json:"raw"`
+}
+
+const testMappings = `{
+	"github.com/brainicorn/jsonschemagen/generator/MappedDecimal": {"type": "string", "pattern": "^-?[0-9]+(\\.[0-9]+)?$"},
+	"time/Time": {"type": "string", "format": "date"},
+	"time/Duration": {}
+}`
+
+func TestTypeMappings(t *testing.T) {
+	t.Parallel()
+
+	mappings, err := ParseTypeMappings([]byte(testMappings))
+	assert.NoError(t, err)
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.TypeMappings = mappings
+
+	jsonSchema, err := GenerateIt(pkg, "MappedHolder", opts)
+
+	assert.NoError(t, err)
+
+	props := jsonSchema.(schema.ObjectSchema).GetProperties()
+
+	amount := props["amount"].(schema.StringSchema)
+	assert.Equal(t, schema.SchemaTypeString, amount.GetType().String)
+	assert.Equal(t, `^-?[0-9]+(\.[0-9]+)?$`, amount.GetPattern())
+	assert.Equal(t, int64(0), amount.GetMaxLength())
+
+	// field attrs are applied to a copy of the mapping
+	limit := props["limit"].(schema.StringSchema)
+	assert.Equal(t, int64(20), limit.GetMaxLength())
+
+	day := props["day"].(schema.StringSchema)
+	assert.Equal(t, "date", day.GetFormat())
+
+	assert.Nil(t, props["duration"].GetType())
+
+	// defaults still apply for types that aren't mapped
+	raw := props["raw"].(schema.ObjectSchema)
+	assert.Equal(t, schema.SchemaTypeObject, raw.GetType().String)
+	assert.Empty(t, jsonSchema.GetDefinitions())
+}
+
+func TestParseTypeMappingsErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseTypeMappings([]byte(`{"Decimal": {"type": "string"}}`))
+	assert.EqualError(t, err, "type mapping key 'Decimal' is not a fully-qualified type path")
+
+	_, err = ParseTypeMappings([]byte(`["time/Time"]`))
+	assert.Error(t, err)
+}
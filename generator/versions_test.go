@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type VersionChild struct {
+	Name string `json:"name"`
+}
+
+// @jsonSchema(id="#order")
+type VersionHolder struct {
+	// @jsonSchema(minimum=1, maximum=10, exclusiveMaximum=true)
+	Count int           `json:"count"`
+	Child *VersionChild `json:"child"`
+	// @jsonSchema(const="v1")
+	Kind    string          `json:"kind"`
+	Timeout MarshalDuration `json:"timeout"`
+}
+
+func marshalVersionHolder(t *testing.T, version schema.SpecVersion) map[string]interface{} {
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+	opts.SpecVersion = version
+
+	jsonSchema, err := GenerateIt(pkg, "VersionHolder", opts)
+	assert.NoError(t, err)
+
+	schemaBytes, err := schema.MarshalForVersion(jsonSchema, version)
+	assert.NoError(t, err)
+
+	var out map[string]interface{}
+	assert.NoError(t, json.Unmarshal(schemaBytes, &out))
+
+	return out
+}
+
+func TestMarshalDraft04Unchanged(t *testing.T) {
+	t.Parallel()
+
+	pkg := "github.com/brainicorn/jsonschemagen/generator"
+	opts := NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = QuietLevel
+
+	jsonSchema, err := GenerateIt(pkg, "VersionHolder", opts)
+	assert.NoError(t, err)
+
+	plain, _ := json.MarshalIndent(jsonSchema, "", "  ")
+	versioned, err := schema.MarshalIndentForVersion(jsonSchema, schema.SpecVersionDraftV4, "", "  ")
+
+	assert.NoError(t, err)
+	assert.Equal(t, string(plain), string(versioned))
+}
+
+func TestMarshalDraft07(t *testing.T) {
+	t.Parallel()
+
+	out := marshalVersionHolder(t, schema.SpecVersionDraft07)
+	props := out["properties"].(map[string]interface{})
+
+	assert.Equal(t, string(schema.SpecVersionDraft07), out["$schema"])
+	assert.Equal(t, "#order", out["$id"])
+	assert.NotContains(t, out, "id")
+	assert.Contains(t, out, "definitions")
+
+	count := props["count"].(map[string]interface{})
+	assert.Equal(t, 10.0, count["exclusiveMaximum"])
+	assert.Equal(t, 1.0, count["minimum"])
+	assert.NotContains(t, count, "maximum")
+
+	assert.Equal(t, "#/definitions/github_com-brainicorn-jsonschemagen-generator-VersionChild", props["child"].(map[string]interface{})["$ref"])
+	assert.Equal(t, "v1", props["kind"].(map[string]interface{})["const"])
+
+	// an open schema is the boolean schema true
+	assert.Equal(t, true, props["timeout"])
+}
+
+func TestMarshalDraft202012(t *testing.T) {
+	t.Parallel()
+
+	out := marshalVersionHolder(t, schema.SpecVersionDraft202012)
+	props := out["properties"].(map[string]interface{})
+
+	assert.Equal(t, string(schema.SpecVersionDraft202012), out["$schema"])
+	assert.Equal(t, "order", out["$anchor"])
+	assert.NotContains(t, out, "$id")
+	assert.NotContains(t, out, "definitions")
+	assert.Contains(t, out["$defs"], "github_com-brainicorn-jsonschemagen-generator-VersionChild")
+	assert.Equal(t, "#/$defs/github_com-brainicorn-jsonschemagen-generator-VersionChild", props["child"].(map[string]interface{})["$ref"])
+}
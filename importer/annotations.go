@@ -0,0 +1,327 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// annotationEscaper escapes a value for a quoted annotation attribute. Annotations live in line
+// comments so newlines can't be kept.
+var annotationEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r\n", " ", "\n", " ")
+
+// attributes are the attributes of a @jsonSchema annotation in the order they're written.
+type attributes []string
+
+func (a *attributes) add(name string, value string) {
+	*a = append(*a, name+"="+value)
+}
+
+func (a *attributes) addString(name string, value string) {
+	a.add(name, quote(value))
+}
+
+func (a *attributes) addNumber(name string, value float64) {
+	a.add(name, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func (a *attributes) addList(name string, values []string) {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, quote(v))
+	}
+
+	a.add(name, "["+strings.Join(quoted, ", ")+"]")
+}
+
+func (a *attributes) addDocs(s schema.JSONSchema) {
+	if s.GetTitle() != "" {
+		a.addString("title", s.GetTitle())
+	}
+
+	if s.GetDescription() != "" {
+		a.addString("description", s.GetDescription())
+	}
+}
+
+// comment returns the annotation as a line comment, or nothing if there are no attributes.
+func (a attributes) comment(indent string) string {
+	if len(a) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s// @jsonSchema(%s)\n", indent, strings.Join(a, ", "))
+}
+
+// addConstraintAttrs adds the validation keywords of a schema that isn't declared as a type of its
+// own. goType is the GO type used for the schema.
+func (c *converter) addConstraintAttrs(attrs *attributes, s schema.JSONSchema, goType string) {
+	jsonTypes, _ := typesOf(s)
+
+	if len(jsonTypes) > 1 {
+		attrs.addList("type", jsonTypes)
+		c.addValueAttrs(attrs, s, true)
+		return
+	}
+
+	if len(jsonTypes) == 0 {
+		c.addValueAttrs(attrs, s, true)
+		return
+	}
+
+	switch jsonTypes[0] {
+	case schema.SchemaTypeArray:
+		if arr, ok := s.(schema.ArraySchema); ok {
+			if arr.GetMinItems() > 0 {
+				attrs.add("minItems", strconv.FormatInt(arr.GetMinItems(), 10))
+			}
+			if arr.GetMaxItems() > 0 {
+				attrs.add("maxItems", strconv.FormatInt(arr.GetMaxItems(), 10))
+			}
+			if arr.GetUniqueItems() {
+				attrs.add("uniqueItems", "true")
+			}
+		}
+
+	case schema.SchemaTypeObject:
+		if obj, ok := s.(schema.ObjectSchema); ok {
+			if ap := obj.GetAdditionalProperties(); ap != nil && ap.Schema == nil && !ap.Boolean {
+				attrs.add("additionalProperties", "false")
+			}
+		}
+
+	default:
+		c.addScalarAttrs(attrs, s, goType, true)
+	}
+}
+
+// addScalarAttrs adds the keywords of a string, number, integer or boolean schema.
+func (c *converter) addScalarAttrs(attrs *attributes, s schema.JSONSchema, goType string, withEnum bool) {
+	if simple, ok := s.(schema.SimpleSchema); ok && simple.GetFormat() != "" {
+		// time.Time already implies the date-time format
+		if goType != "time.Time" {
+			attrs.addString("format", simple.GetFormat())
+		}
+	}
+
+	c.addValueAttrs(attrs, s, withEnum)
+
+	jsonTypes, _ := typesOf(s)
+
+	switch jsonTypes[0] {
+	case schema.SchemaTypeString:
+		if str, ok := s.(schema.StringSchema); ok {
+			if str.GetMinLength() > 0 {
+				attrs.add("minLength", strconv.FormatInt(str.GetMinLength(), 10))
+			}
+			if str.GetMaxLength() > 0 {
+				attrs.add("maxLength", strconv.FormatInt(str.GetMaxLength(), 10))
+			}
+			if str.GetPattern() != "" {
+				attrs.addString("pattern", str.GetPattern())
+			}
+		}
+
+	case schema.SchemaTypeInteger, schema.SchemaTypeNumber:
+		if num, ok := s.(schema.NumericSchema); ok {
+			if num.GetMinimum() != 0 {
+				attrs.addNumber("minimum", num.GetMinimum())
+				if num.GetExclusiveMinimum() {
+					attrs.add("exclusiveMinimum", "true")
+				}
+			}
+			if num.GetMaximum() != 0 {
+				attrs.addNumber("maximum", num.GetMaximum())
+				if num.GetExclusiveMaximum() {
+					attrs.add("exclusiveMaximum", "true")
+				}
+			}
+			if num.GetMultipleOf() != 0 {
+				attrs.addNumber("multipleOf", num.GetMultipleOf())
+			}
+		}
+	}
+}
+
+// addValueAttrs adds the default, enum and const keywords.
+func (c *converter) addValueAttrs(attrs *attributes, s schema.JSONSchema, withEnum bool) {
+	if def := s.GetDefault(); def != nil {
+		attrs.addString("default", jsonLiteral(def))
+	}
+
+	if withEnum && len(s.GetEnum()) > 0 {
+		values := make([]string, 0, len(s.GetEnum()))
+		for _, v := range s.GetEnum() {
+			values = append(values, jsonLiteral(v))
+		}
+		attrs.addList("enum", values)
+	}
+
+	if value, hasConst := s.GetConst(); hasConst {
+		attrs.addString("const", jsonLiteral(value))
+	}
+}
+
+// addObjectAttrs adds the keywords of an object that the generator reads from a type's annotation.
+func (c *converter) addObjectAttrs(attrs *attributes, name string, obj schema.ObjectSchema) error {
+	if obj.GetID() != "" {
+		attrs.addString("id", obj.GetID())
+	}
+
+	if obj.GetMinProperties() > 0 {
+		attrs.add("minProperties", strconv.FormatInt(obj.GetMinProperties(), 10))
+	}
+
+	if obj.GetMaxProperties() > 0 {
+		attrs.add("maxProperties", strconv.FormatInt(obj.GetMaxProperties(), 10))
+	}
+
+	if ap := obj.GetAdditionalProperties(); ap != nil {
+		switch {
+		case ap.Schema == nil && !ap.Boolean:
+			attrs.add("additionalProperties", "false")
+
+		// a map's value type already describes its additional properties
+		case ap.Schema != nil && isStruct(obj):
+			path, err := c.branchPath(ap.Schema, name+"Additional")
+			if err != nil {
+				return err
+			}
+			attrs.addString("additionalProperties", path)
+		}
+	}
+
+	if err := c.addPatternAttrs(attrs, name, obj); err != nil {
+		return err
+	}
+
+	if err := c.addDependencyAttrs(attrs, name, obj); err != nil {
+		return err
+	}
+
+	return c.addCombinatorAttrs(attrs, name, obj)
+}
+
+func (c *converter) addPatternAttrs(attrs *attributes, name string, obj schema.ObjectSchema) error {
+	patternProps := obj.GetPatternProperties()
+
+	if len(patternProps) == 0 {
+		return nil
+	}
+
+	patterns := make([]string, 0, len(patternProps))
+	for pattern := range patternProps {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	entries := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		path, err := c.branchPath(patternProps[pattern], name+"Pattern")
+		if err != nil {
+			return err
+		}
+		entries = append(entries, pattern+"="+path)
+	}
+
+	attrs.addList("patternProperties", entries)
+
+	return nil
+}
+
+// addDependencyAttrs adds the dependencies of the object. The generator emits them as
+// dependencies or as dependentRequired and dependentSchemas depending on the spec version.
+func (c *converter) addDependencyAttrs(attrs *attributes, name string, obj schema.ObjectSchema) error {
+	deps := make(map[string]*schema.StringArrayOrSchema)
+
+	for prop, dep := range obj.GetDependencies() {
+		deps[prop] = dep
+	}
+
+	for prop, required := range obj.GetDependentRequired() {
+		deps[prop] = &schema.StringArrayOrSchema{Array: required}
+	}
+
+	for prop, depSchema := range obj.GetDependentSchemas() {
+		deps[prop] = &schema.StringArrayOrSchema{Schema: depSchema}
+	}
+
+	if len(deps) == 0 {
+		return nil
+	}
+
+	props := make([]string, 0, len(deps))
+	for prop := range deps {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	entries := make([]string, 0, len(props))
+	for _, prop := range props {
+		if deps[prop].Schema == nil {
+			entries = append(entries, prop+"="+strings.Join(deps[prop].Array, ","))
+			continue
+		}
+
+		path, err := c.branchPath(deps[prop].Schema, name+exportedName(prop)+"Dependency")
+		if err != nil {
+			return err
+		}
+		entries = append(entries, prop+"="+path)
+	}
+
+	attrs.addList("dependencies", entries)
+
+	return nil
+}
+
+// addCombinatorAttrs adds allOf, anyOf, oneOf and not as type paths.
+func (c *converter) addCombinatorAttrs(attrs *attributes, name string, s schema.JSONSchema) error {
+	combinators := []struct {
+		keyword  string
+		branches []schema.JSONSchema
+	}{
+		{"allOf", s.GetAllOf()},
+		{"anyOf", s.GetAnyOf()},
+		{"oneOf", s.GetOneOf()},
+	}
+
+	for _, combinator := range combinators {
+		if len(combinator.branches) == 0 {
+			continue
+		}
+
+		paths := make([]string, 0, len(combinator.branches))
+		for _, branch := range combinator.branches {
+			path, err := c.branchPath(branch, name+"Option")
+			if err != nil {
+				return err
+			}
+			paths = append(paths, path)
+		}
+
+		attrs.addList(combinator.keyword, paths)
+	}
+
+	// the not of a false schema only stands in for false, which can't be imported
+	if _, isBool := s.(schema.BooleanSchema); !isBool && s.GetNot() != nil {
+		path, err := c.branchPath(s.GetNot(), name+"Not")
+		if err != nil {
+			return err
+		}
+		attrs.addString("not", path)
+	}
+
+	return nil
+}
+
+func quote(value string) string {
+	return `"` + annotationEscaper.Replace(value) + `"`
+}
+
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
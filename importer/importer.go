@@ -0,0 +1,298 @@
+// Package importer generates GO source from an existing JSON Schema so that third-party schemas can
+// be turned into structs that round-trip through the generator.
+//
+// Every definition becomes a named type and the root becomes a type named by Options.RootName:
+//
+//	src, err := importer.GenerateJSON(schemaBytes, importer.Options{PackageName: "pets", ImportPath: "github.com/example/pets"})
+//
+// Objects with properties become structs whose required fields are values and whose optional fields
+// are pointers with omitempty. Constraints become @jsonSchema annotations, oneOf and anyOf become
+// annotated interfaces and enums become a named type with a typed constant for each value.
+// Generating a schema from the output gives an equivalent schema.
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// Options holds the configuration for generating GO source.
+type Options struct {
+	// PackageName is the name used in the package clause of the generated source.
+	PackageName string
+	// ImportPath is the import path of the generated package. Annotations like oneOf reference types
+	// by their fully-qualified path so this must be where the source ends up. Defaults to PackageName.
+	ImportPath string
+	// RootName is the name of the type generated for the root schema. Defaults to the last element of
+	// the root's x-go-path, or Root.
+	RootName string
+}
+
+type kind uint8
+
+const (
+	// kindScalar types are pointers when they're optional.
+	kindScalar kind = iota
+	// kindStruct types are pointers when they're optional.
+	kindStruct
+	// kindReference types such as slices, maps and interfaces are never pointers.
+	kindReference
+)
+
+type converter struct {
+	opts      Options
+	root      schema.JSONSchema
+	defs      map[string]schema.JSONSchema
+	defNames  map[string]string
+	taken     map[string]bool
+	decls     []string
+	imports   map[string]bool
+	valueType string
+	pending   string
+}
+
+// Generate returns gofmt'd GO source for the schema and all of its definitions.
+func Generate(root schema.JSONSchema, opts Options) ([]byte, error) {
+	if opts.PackageName == "" {
+		return nil, fmt.Errorf("a package name is required")
+	}
+
+	if opts.ImportPath == "" {
+		opts.ImportPath = opts.PackageName
+	}
+
+	c := &converter{
+		opts:     opts,
+		root:     root,
+		defs:     root.GetDefinitions(),
+		defNames: make(map[string]string),
+		taken:    make(map[string]bool),
+		imports:  make(map[string]bool),
+	}
+
+	if c.defs == nil {
+		c.defs = make(map[string]schema.JSONSchema)
+	}
+
+	if err := c.convert(); err != nil {
+		return nil, err
+	}
+
+	return c.source()
+}
+
+// GenerateJSON parses the JSON encoding of a schema and returns GO source for it.
+func GenerateJSON(schemaBytes []byte, opts Options) ([]byte, error) {
+	root, err := schema.FromJSON(schemaBytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("error parsing schema: %s", err)
+	}
+
+	return Generate(root, opts)
+}
+
+func (c *converter) convert() error {
+	keys := make([]string, 0, len(c.defs))
+	for key := range c.defs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rootName := c.opts.RootName
+	if rootName == "" {
+		rootName = goPathName(c.root)
+	}
+	if rootName == "" {
+		rootName = "Root"
+	}
+
+	// a root that's only a $ref is generated as the definition it points to
+	if c.root.GetRef() == "" {
+		c.defNames["#"] = c.reserve(exportedName(rootName))
+	}
+
+	for _, key := range keys {
+		name := goPathName(c.defs[key])
+		if name == "" {
+			name = definitionName(key)
+		}
+
+		c.defNames[key] = c.reserve(name)
+	}
+
+	if c.root.GetRef() == "" {
+		if err := c.declareNamed(c.defNames["#"], c.root); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range keys {
+		if err := c.declareNamed(c.defNames[key], c.defs[key]); err != nil {
+			return fmt.Errorf("error generating definition %s: %s", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *converter) source() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("// Code generated by jsonschemagen import. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", c.opts.PackageName)
+
+	if len(c.imports) > 0 {
+		paths := make([]string, 0, len(c.imports))
+		for path := range c.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		buf.WriteString("\nimport (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+		buf.WriteString(")\n")
+	}
+
+	for _, decl := range c.decls {
+		buf.WriteString("\n")
+		buf.WriteString(decl)
+	}
+
+	src, err := format.Source(buf.Bytes())
+
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated source: %s", err)
+	}
+
+	return src, nil
+}
+
+// reserve returns a package-level name based on name that hasn't been used yet.
+func (c *converter) reserve(name string) string {
+	unique := name
+	for i := 2; c.taken[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	c.taken[unique] = true
+
+	return unique
+}
+
+// startDecl reserves a slot for a declaration so that it's written before any types declared while
+// building it.
+func (c *converter) startDecl() int {
+	c.decls = append(c.decls, "")
+
+	return len(c.decls) - 1
+}
+
+// typePath returns the fully-qualified path annotations use to refer to a generated type.
+func (c *converter) typePath(name string) string {
+	return c.opts.ImportPath + "/" + name
+}
+
+// resolve follows $refs to the schema they point to.
+func (c *converter) resolve(s schema.JSONSchema) (schema.JSONSchema, error) {
+	seen := make(map[string]bool)
+
+	for s.GetRef() != "" {
+		ref := s.GetRef()
+
+		if seen[ref] {
+			return nil, fmt.Errorf("circular $ref %s", ref)
+		}
+		seen[ref] = true
+
+		if ref == "#" {
+			s = c.root
+			continue
+		}
+
+		key, err := definitionKey(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		def, found := c.defs[key]
+		if !found {
+			return nil, fmt.Errorf("unresolvable $ref %s", ref)
+		}
+
+		s = def
+	}
+
+	return s, nil
+}
+
+// refName returns the name of the type generated for the $ref.
+func (c *converter) refName(ref string) (string, error) {
+	if ref == "#" {
+		if name, found := c.defNames["#"]; found {
+			return name, nil
+		}
+
+		return c.refName(c.root.GetRef())
+	}
+
+	key, err := definitionKey(ref)
+	if err != nil {
+		return "", err
+	}
+
+	name, found := c.defNames[key]
+	if !found {
+		return "", fmt.Errorf("unresolvable $ref %s", ref)
+	}
+
+	return name, nil
+}
+
+// kindOf returns how the GO type for the schema behaves when the field it's used for is optional.
+func (c *converter) kindOf(s schema.JSONSchema) kind {
+	resolved, err := c.resolve(s)
+	if err != nil {
+		return kindReference
+	}
+
+	s, _ = unwrapNullable(resolved)
+
+	if s.GetRef() != "" {
+		return c.kindOf(s)
+	}
+
+	if isUnion(s) {
+		return kindReference
+	}
+
+	jsonTypes, _ := typesOf(s)
+
+	switch {
+	case isStruct(s):
+		return kindStruct
+	case len(jsonTypes) != 1:
+		return kindReference
+	case jsonTypes[0] == schema.SchemaTypeObject, jsonTypes[0] == schema.SchemaTypeArray:
+		return kindReference
+	}
+
+	return kindScalar
+}
+
+func definitionKey(ref string) (string, error) {
+	for _, prefix := range []string{schema.DefinitionRoot, "#/$defs/"} {
+		if strings.HasPrefix(ref, prefix) {
+			return ref[len(prefix):], nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported $ref %s, only local definitions can be imported", ref)
+}
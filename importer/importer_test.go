@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brainicorn/jsonschemagen/diff"
+	"github.com/brainicorn/jsonschemagen/generator"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var storeSchema = `{
+	"$schema": "http://json-schema.org/draft-04/schema#",
+	"type": "object",
+	"title": "Store",
+	"properties": {
+		"id": {"type": "string", "format": "uuid"},
+		"name": {"type": "string", "minLength": 1, "maxLength": 250, "description": "the \"store\" name"},
+		"opened": {"type": "string", "format": "date-time"},
+		"rating": {"type": "number", "minimum": 1, "maximum": 5},
+		"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "uniqueItems": true},
+		"owner": {"$ref": "#/definitions/Person"},
+		"manager": {"$ref": "#/definitions/Person"},
+		"size": {"type": "string", "enum": ["small", "large"]},
+		"pet": {"type": "object", "oneOf": [{"$ref": "#/definitions/Cat"}, {"$ref": "#/definitions/Dog"}]},
+		"parent": {"$ref": "#"},
+		"nick": {"type": ["string", "null"]},
+		"counts": {"type": "object", "additionalProperties": {"type": "integer"}}
+	},
+	"required": ["id", "name", "owner"],
+	"definitions": {
+		"Person": {"type": "object", "properties": {"name": {"type": "string"}, "level": {"$ref": "#/definitions/Level"}}, "additionalProperties": false},
+		"Cat": {"type": "object", "properties": {"meow": {"type": "boolean"}}},
+		"Dog": {"type": "object", "properties": {"bark": {"type": "boolean"}}},
+		"Level": {"type": "integer", "enum": [1, 2, 3], "x-enumNames": ["LevelLow", "LevelMid", "LevelHigh"]}
+	}
+}`
+
+func TestGenerateRequiresPackageName(t *testing.T) {
+	t.Parallel()
+
+	_, err := GenerateJSON([]byte(storeSchema), Options{})
+
+	assert.EqualError(t, err, "a package name is required")
+}
+
+func TestGenerateUnsupportedRef(t *testing.T) {
+	t.Parallel()
+
+	_, err := GenerateJSON([]byte(`{"type": "object", "properties": {"a": {"$ref": "other.json#/definitions/A"}}}`), Options{PackageName: "pets"})
+
+	assert.Error(t, err)
+}
+
+func TestGenerateStructs(t *testing.T) {
+	t.Parallel()
+
+	src, err := GenerateJSON([]byte(storeSchema), Options{PackageName: "pets", ImportPath: "github.com/acme/pets"})
+	assert.NoError(t, err)
+
+	code := string(src)
+
+	assert.Contains(t, code, "package pets")
+	assert.Contains(t, code, "// @jsonSchema(title=\"Store\")\ntype Root struct {")
+	assert.Regexp(t, `// @jsonSchema\(required=true, format="uuid"\)\n\tID\s+string\s+`+"`json:\"id\"`", code)
+	assert.Contains(t, code, "// @jsonSchema(required=true, description=\"the \\\"store\\\" name\", minLength=1, maxLength=250)")
+	assert.Regexp(t, `Opened\s+\*time\.Time\s+`+"`json:\"opened,omitempty\"`", code)
+	assert.Regexp(t, `Owner\s+Person\s+`+"`json:\"owner\"`", code)
+	assert.Regexp(t, `Parent\s+\*Root\s+`+"`json:\"parent,omitempty\"`", code)
+	assert.Contains(t, code, "Tags []RootTagsItem `json:\"tags,omitempty\"`")
+	assert.Contains(t, code, "// @jsonSchema(pattern=\"^[a-z]+$\")\ntype RootTagsItem string")
+	assert.Regexp(t, `// @jsonSchema\(nullable=true\)\n\tNick\s+\*string`, code)
+	assert.Regexp(t, `Counts\s+map\[string\]int\s+`+"`json:\"counts,omitempty\"`", code)
+}
+
+func TestGenerateUnionsAndEnums(t *testing.T) {
+	t.Parallel()
+
+	src, err := GenerateJSON([]byte(storeSchema), Options{PackageName: "pets", ImportPath: "github.com/acme/pets"})
+	assert.NoError(t, err)
+
+	code := string(src)
+
+	assert.Contains(t, code, "// @jsonSchema(oneOf=[\"github.com/acme/pets/Cat\", \"github.com/acme/pets/Dog\"])\ntype RootPet interface{}")
+	assert.Contains(t, code, "type RootSize string")
+	assert.Contains(t, code, "RootSizeSmall RootSize = \"small\"")
+	assert.Contains(t, code, "type Level int")
+	assert.Contains(t, code, "LevelHigh Level = 3")
+	assert.Contains(t, code, "// @jsonSchema(additionalProperties=false)\ntype Person struct {")
+}
+
+func TestGenerateDefs(t *testing.T) {
+	t.Parallel()
+
+	src, err := GenerateJSON([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {"owner": {"$ref": "#/$defs/Person"}},
+		"$defs": {"Person": {"type": "object", "properties": {"name": {"type": "string"}}}}
+	}`), Options{PackageName: "pets"})
+	assert.NoError(t, err)
+
+	code := string(src)
+	assert.Contains(t, code, "type Person struct {")
+	assert.Regexp(t, `Owner\s+\*Person\s+`, code)
+}
+
+func TestGenerateRootName(t *testing.T) {
+	t.Parallel()
+
+	src, err := GenerateJSON([]byte(`{"type": "object", "properties": {"a": {"type": "string"}}, "x-go-path": "github.com/acme/pets/Store"}`), Options{PackageName: "pets"})
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), "type Store struct {")
+
+	src, err = GenerateJSON([]byte(`{"type": "object", "properties": {"a": {"type": "string"}}}`), Options{PackageName: "pets", RootName: "shop"})
+	assert.NoError(t, err)
+	assert.Contains(t, string(src), "type Shop struct {")
+}
+
+func TestGenerateRoundTrip(t *testing.T) {
+	dir, err := os.MkdirTemp(".", "roundtrip")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pkg := "github.com/brainicorn/jsonschemagen/importer/" + filepath.Base(dir)
+
+	src, err := GenerateJSON([]byte(storeSchema), Options{PackageName: "pets", ImportPath: pkg})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "types.go"), src, 0644))
+
+	opts := generator.NewOptions()
+	opts.LogLevel = generator.QuietLevel
+
+	generated, err := generator.NewJSONSchemaGenerator(pkg, "Root", opts).Generate()
+	assert.NoError(t, err)
+
+	generatedJSON, err := json.Marshal(generated)
+	assert.NoError(t, err)
+
+	changes, err := diff.CompareJSON([]byte(storeSchema), generatedJSON)
+	assert.NoError(t, err)
+
+	for _, change := range changes {
+		assert.Equal(t, diff.CompatibilityFull, change.Compatibility, change.String())
+	}
+}
@@ -0,0 +1,670 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// initialisms are upper-cased as a whole when they make up a word of a GO name.
+var initialisms = map[string]bool{
+	"api": true, "cpu": true, "css": true, "dns": true, "html": true, "http": true, "https": true,
+	"id": true, "ip": true, "json": true, "sql": true, "ssh": true, "tcp": true, "tls": true,
+	"ttl": true, "udp": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// declareNamed declares a named type for a definition or the root.
+func (c *converter) declareNamed(name string, s schema.JSONSchema) error {
+	c.pending = name
+	typ, err := c.typeFor(s, name, false)
+	c.pending = ""
+
+	if err != nil || typ == name {
+		return err
+	}
+
+	inner, _ := unwrapNullable(s)
+
+	attrs := &attributes{}
+	attrs.addDocs(inner)
+
+	if obj, ok := inner.(schema.ObjectSchema); ok {
+		if err = c.addObjectAttrs(attrs, name, obj); err != nil {
+			return err
+		}
+	} else if inner.GetRef() == "" {
+		c.addConstraintAttrs(attrs, inner, typ)
+	}
+
+	c.decls = append(c.decls, fmt.Sprintf("%stype %s %s\n", attrs.comment(""), name, typ))
+
+	return nil
+}
+
+// typeFor returns the GO type for the schema. Structs, unions, enums and constrained scalars that
+// aren't a field's type are declared as named types using name. Field types carry their
+// constraints in the field's annotation instead.
+func (c *converter) typeFor(s schema.JSONSchema, name string, field bool) (string, error) {
+	s, _ = unwrapNullable(s)
+
+	if ref := s.GetRef(); ref != "" {
+		return c.refName(ref)
+	}
+
+	if isStruct(s) {
+		return c.declareStruct(name, s.(schema.ObjectSchema))
+	}
+
+	if isUnion(s) {
+		return c.declareUnion(name, s)
+	}
+
+	if c.isTypedEnum(s) {
+		return c.declareEnum(name, s)
+	}
+
+	jsonTypes, _ := typesOf(s)
+
+	if len(jsonTypes) == 0 {
+		return c.jsonValueType(), nil
+	}
+
+	// the field's annotation lists the types
+	if len(jsonTypes) > 1 {
+		if field {
+			return "interface{}", nil
+		}
+
+		return c.jsonValueType(), nil
+	}
+
+	switch jsonTypes[0] {
+	case schema.SchemaTypeObject:
+		obj, ok := s.(schema.ObjectSchema)
+		if !ok || obj.GetAdditionalProperties() == nil || obj.GetAdditionalProperties().Schema == nil {
+			return "map[string]interface{}", nil
+		}
+
+		valueType, err := c.typeFor(obj.GetAdditionalProperties().Schema, name+"Value", false)
+		return "map[string]" + valueType, err
+
+	case schema.SchemaTypeArray:
+		arr, ok := s.(schema.ArraySchema)
+		if !ok || arr.GetItems() == nil {
+			return "[]" + c.jsonValueType(), nil
+		}
+
+		itemType, err := c.typeFor(arr.GetItems(), name+"Item", false)
+		return "[]" + itemType, err
+	}
+
+	typ := c.scalarType(s, jsonTypes[0], field)
+
+	if field {
+		return typ, nil
+	}
+
+	attrs := &attributes{}
+	c.addConstraintAttrs(attrs, s, typ)
+
+	if len(*attrs) == 0 {
+		return typ, nil
+	}
+
+	name = c.claim(name)
+	c.decls = append(c.decls, fmt.Sprintf("%stype %s %s\n", attrs.comment(""), name, typ))
+
+	return name, nil
+}
+
+func (c *converter) scalarType(s schema.JSONSchema, jsonType string, field bool) string {
+	var format string
+	if simple, ok := s.(schema.SimpleSchema); ok {
+		format = simple.GetFormat()
+	}
+
+	switch jsonType {
+	case schema.SchemaTypeString:
+		// the generator only adds the date-time format for time.Time fields
+		if field && format == "date-time" {
+			c.imports["time"] = true
+			return "time.Time"
+		}
+		return "string"
+
+	case schema.SchemaTypeInteger:
+		if format == "int32" || format == "int64" {
+			return format
+		}
+		return "int"
+
+	case schema.SchemaTypeNumber:
+		if format == "float" {
+			return "float32"
+		}
+		return "float64"
+
+	case schema.SchemaTypeBoolean:
+		return "bool"
+	}
+
+	return c.jsonValueType()
+}
+
+// claim reserves the name for a declared type. Definition names are reserved up front, so the
+// definition that's being declared gets its own name back.
+func (c *converter) claim(name string) string {
+	if name == c.pending {
+		c.pending = ""
+		return name
+	}
+
+	return c.reserve(name)
+}
+
+func (c *converter) declareStruct(name string, obj schema.ObjectSchema) (string, error) {
+	name = c.claim(name)
+	slot := c.startDecl()
+
+	attrs := &attributes{}
+	attrs.addDocs(obj)
+
+	if err := c.addObjectAttrs(attrs, name, obj); err != nil {
+		return "", err
+	}
+
+	required := make(map[string]bool)
+	for _, propName := range obj.GetRequired() {
+		required[propName] = true
+	}
+
+	// fields follow the property order so that the generated schema keeps it
+	props := obj.GetProperties()
+	propNames := obj.GetPropertyOrder()
+
+	var buf strings.Builder
+	fieldNames := make(map[string]bool)
+
+	fmt.Fprintf(&buf, "%stype %s struct {\n", attrs.comment(""), name)
+
+	for _, propName := range propNames {
+		prop := props[propName]
+
+		fieldName := exportedName(propName)
+		if fieldName == "" {
+			fieldName = "Field"
+		}
+		for base, i := fieldName, 2; fieldNames[fieldName]; i++ {
+			fieldName = fmt.Sprintf("%s%d", base, i)
+		}
+		fieldNames[fieldName] = true
+
+		typ, err := c.typeFor(prop, name+fieldName, true)
+		if err != nil {
+			return "", fmt.Errorf("error generating field for property %s: %s", propName, err)
+		}
+
+		inner, nullable := unwrapNullable(prop)
+
+		pointer := false
+		switch c.kindOf(prop) {
+		case kindScalar:
+			pointer = !required[propName] || nullable
+		case kindStruct:
+			// a struct can't contain itself by value
+			pointer = !required[propName] || nullable || inner.GetRef() == "#" || c.containsByValue(prop, name, make(map[string]bool))
+		}
+
+		if pointer {
+			typ = "*" + typ
+		}
+
+		tag := propName
+		if !required[propName] {
+			tag += ",omitempty"
+		}
+
+		fieldAttrs := &attributes{}
+		if required[propName] {
+			fieldAttrs.add("required", "true")
+		}
+		if nullable {
+			fieldAttrs.add("nullable", "true")
+		}
+		fieldAttrs.addDocs(inner)
+
+		if c.carriesConstraints(inner) {
+			c.addConstraintAttrs(fieldAttrs, inner, strings.TrimPrefix(typ, "*"))
+		} else if isUnion(inner) {
+			c.addUnionTypeAttr(fieldAttrs, inner)
+		}
+
+		fmt.Fprintf(&buf, "%s%s %s `json:%q`\n", fieldAttrs.comment("\t"), fieldName, typ, tag)
+	}
+
+	buf.WriteString("}\n")
+	c.decls[slot] = buf.String()
+
+	return name, nil
+}
+
+// carriesConstraints returns true if the schema's constraints belong in the annotation of the field
+// using it rather than with a declared type.
+func (c *converter) carriesConstraints(s schema.JSONSchema) bool {
+	return s.GetRef() == "" && !isStruct(s) && !isUnion(s) && !c.isTypedEnum(s)
+}
+
+// containsByValue returns true if the struct for s would contain the named struct if all of its
+// required struct fields were values.
+func (c *converter) containsByValue(s schema.JSONSchema, name string, seen map[string]bool) bool {
+	s, nullable := unwrapNullable(s)
+
+	if nullable {
+		return false
+	}
+
+	if ref := s.GetRef(); ref != "" {
+		refName, err := c.refName(ref)
+		if err != nil {
+			return false
+		}
+
+		if refName == name {
+			return true
+		}
+
+		if seen[refName] {
+			return false
+		}
+		seen[refName] = true
+
+		resolved, err := c.resolve(s)
+		if err != nil {
+			return false
+		}
+
+		return c.containsByValue(resolved, name, seen)
+	}
+
+	obj, ok := s.(schema.ObjectSchema)
+	if !ok || !isStruct(s) {
+		return false
+	}
+
+	for _, propName := range obj.GetRequired() {
+		if prop, found := obj.GetProperties()[propName]; found && c.containsByValue(prop, name, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// declareUnion declares an interface annotated with the branches of the schema's combinators.
+func (c *converter) declareUnion(name string, s schema.JSONSchema) (string, error) {
+	name = c.claim(name)
+	slot := c.startDecl()
+
+	attrs := &attributes{}
+	attrs.addDocs(s)
+
+	if err := c.addCombinatorAttrs(attrs, name, s); err != nil {
+		return "", err
+	}
+
+	c.decls[slot] = fmt.Sprintf("%stype %s interface{}\n", attrs.comment(""), name)
+
+	return name, nil
+}
+
+// addUnionTypeAttr sets the type of a union field to the types of its branches. Without it the
+// generator assumes the branches are all objects.
+func (c *converter) addUnionTypeAttr(attrs *attributes, s schema.JSONSchema) {
+	found := make(map[string]bool)
+
+	for _, branch := range append(append(append([]schema.JSONSchema{}, s.GetAllOf()...), s.GetAnyOf()...), s.GetOneOf()...) {
+		resolved, err := c.resolve(branch)
+		if err != nil {
+			return
+		}
+
+		branchTypes, _ := typesOf(resolved)
+		if isStruct(resolved) {
+			branchTypes = []string{schema.SchemaTypeObject}
+		}
+
+		// a branch that accepts any type can't be described with a type list
+		if len(branchTypes) == 0 {
+			return
+		}
+
+		for _, t := range branchTypes {
+			found[t] = true
+		}
+	}
+
+	if len(found) == 0 || (len(found) == 1 && found[schema.SchemaTypeObject]) {
+		return
+	}
+
+	jsonTypes := make([]string, 0, len(found))
+	for t := range found {
+		jsonTypes = append(jsonTypes, t)
+	}
+	sort.Strings(jsonTypes)
+
+	attrs.addList("type", jsonTypes)
+}
+
+// isTypedEnum returns true if the schema is an enum that can be declared as typed constants.
+func (c *converter) isTypedEnum(s schema.JSONSchema) bool {
+	jsonTypes, _ := typesOf(s)
+
+	if len(s.GetEnum()) == 0 || len(jsonTypes) != 1 {
+		return false
+	}
+
+	values := 0
+	for _, v := range s.GetEnum() {
+		if v == nil {
+			continue
+		}
+		values++
+
+		switch jsonTypes[0] {
+		case schema.SchemaTypeString:
+			if _, ok := v.(string); !ok {
+				return false
+			}
+		case schema.SchemaTypeInteger, schema.SchemaTypeNumber:
+			if _, err := strconv.ParseFloat(fmt.Sprint(v), 64); err != nil {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return values > 0
+}
+
+// declareEnum declares a named type and a typed constant for each enum value. The generator
+// detects the constants and turns them back into the enum.
+func (c *converter) declareEnum(name string, s schema.JSONSchema) (string, error) {
+	jsonTypes, _ := typesOf(s)
+
+	name = c.claim(name)
+	slot := c.startDecl()
+
+	baseType := c.scalarType(s, jsonTypes[0], false)
+
+	attrs := &attributes{}
+	attrs.addDocs(s)
+	c.addScalarAttrs(attrs, s, baseType, false)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%stype %s %s\n\nconst (\n", attrs.comment(""), name, baseType)
+
+	names := s.GetEnumNames()
+	descs := s.GetEnumDescriptions()
+
+	for i, v := range s.GetEnum() {
+		if v == nil {
+			continue
+		}
+
+		var constName string
+		if i < len(names) && isExportedIdent(names[i]) && !c.taken[names[i]] {
+			constName = c.reserve(names[i])
+		} else {
+			constName = c.reserve(name + exportedName(strings.Replace(fmt.Sprint(v), "-", "Neg ", 1)))
+		}
+
+		if i < len(descs) && descs[i] != "" {
+			fmt.Fprintf(&buf, "\t// %s\n", singleLine(descs[i]))
+		}
+
+		var literal string
+		if str, ok := v.(string); ok {
+			literal = strconv.Quote(str)
+		} else {
+			literal = fmt.Sprint(v)
+		}
+
+		fmt.Fprintf(&buf, "\t%s %s = %s\n", constName, name, literal)
+	}
+
+	buf.WriteString(")\n")
+	c.decls[slot] = buf.String()
+
+	return name, nil
+}
+
+// branchPath returns the type path or JSON type the generator needs to regenerate a branch of a
+// combinator or the schema of a map value. Branches that aren't refs or plain JSON types are
+// declared as named types.
+func (c *converter) branchPath(s schema.JSONSchema, name string) (string, error) {
+	if ref := s.GetRef(); ref != "" {
+		refName, err := c.refName(ref)
+		return c.typePath(refName), err
+	}
+
+	if jsonType, ok := c.plainJSONType(s); ok {
+		return jsonType, nil
+	}
+
+	name = c.reserve(name)
+
+	return c.typePath(name), c.declareNamed(name, s)
+}
+
+// plainJSONType returns the JSON type of schemas that have nothing but a single scalar type.
+func (c *converter) plainJSONType(s schema.JSONSchema) (string, bool) {
+	jsonTypes, nullable := typesOf(s)
+
+	if nullable || len(jsonTypes) != 1 || isUnion(s) || s.GetTitle() != "" || s.GetDescription() != "" {
+		return "", false
+	}
+
+	switch jsonTypes[0] {
+	case schema.SchemaTypeString, schema.SchemaTypeInteger, schema.SchemaTypeNumber, schema.SchemaTypeBoolean:
+	default:
+		return "", false
+	}
+
+	attrs := &attributes{}
+	c.addConstraintAttrs(attrs, s, c.scalarType(s, jsonTypes[0], false))
+
+	return jsonTypes[0], len(*attrs) == 0
+}
+
+// jsonValueType returns the name of a type that marshals to any JSON value, declaring it the first
+// time it's needed. The generator uses an open schema for json.Marshalers without an annotation.
+func (c *converter) jsonValueType() string {
+	if c.valueType != "" {
+		return c.valueType
+	}
+
+	c.valueType = c.reserve("JSONValue")
+	c.imports["encoding/json"] = true
+
+	c.decls = append(c.decls, strings.Replace(`type JSONValue json.RawMessage
+
+// MarshalJSON returns the raw JSON.
+func (v JSONValue) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	return v, nil
+}
+
+// UnmarshalJSON keeps a copy of the raw JSON.
+func (v *JSONValue) UnmarshalJSON(data []byte) error {
+	*v = append((*v)[0:0], data...)
+	return nil
+}
+`, "JSONValue", c.valueType, -1))
+
+	return c.valueType
+}
+
+// typesOf returns the JSON types of the schema without null and whether null was one of them.
+func typesOf(s schema.JSONSchema) ([]string, bool) {
+	soa := s.GetType()
+
+	if soa == nil {
+		return nil, s.GetNullable()
+	}
+
+	all := soa.Array
+	if len(all) == 0 && soa.String != "" {
+		all = []string{soa.String}
+	}
+
+	var jsonTypes []string
+	nullable := s.GetNullable()
+
+	for _, t := range all {
+		if t == schema.SchemaTypeNull {
+			nullable = true
+			continue
+		}
+		jsonTypes = append(jsonTypes, t)
+	}
+
+	return jsonTypes, nullable
+}
+
+// unwrapNullable returns the schema that's made nullable by s along with true if s accepts null.
+// The generator wraps nullable refs in an anyOf with a null schema or, for OpenAPI, in an allOf.
+func unwrapNullable(s schema.JSONSchema) (schema.JSONSchema, bool) {
+	_, nullable := typesOf(s)
+
+	if s.GetType() != nil || s.GetRef() != "" {
+		return s, nullable
+	}
+
+	if nullable && len(s.GetAllOf()) == 1 && len(s.GetAnyOf()) == 0 && len(s.GetOneOf()) == 0 {
+		return s.GetAllOf()[0], true
+	}
+
+	for _, branches := range [][]schema.JSONSchema{s.GetAnyOf(), s.GetOneOf()} {
+		if len(branches) != 2 || len(s.GetAllOf()) > 0 {
+			continue
+		}
+
+		for i, branch := range branches {
+			if isNullSchema(branch) {
+				return branches[1-i], true
+			}
+		}
+	}
+
+	return s, nullable
+}
+
+func isNullSchema(s schema.JSONSchema) bool {
+	soa := s.GetType()
+
+	return soa != nil && (soa.String == schema.SchemaTypeNull || (len(soa.Array) == 1 && soa.Array[0] == schema.SchemaTypeNull))
+}
+
+// isStruct returns true if the schema is an object with properties of its own.
+func isStruct(s schema.JSONSchema) bool {
+	obj, ok := s.(schema.ObjectSchema)
+
+	return ok && s.GetRef() == "" && (len(obj.GetProperties()) > 0 || len(obj.GetPatternProperties()) > 0)
+}
+
+// isUnion returns true if the schema is only a combination of other schemas.
+func isUnion(s schema.JSONSchema) bool {
+	return s.GetRef() == "" && !isStruct(s) && (len(s.GetAllOf()) > 0 || len(s.GetAnyOf()) > 0 || len(s.GetOneOf()) > 0)
+}
+
+// goPathName returns the type name from the schema's x-go-path.
+func goPathName(s schema.JSONSchema) string {
+	obj, ok := s.(schema.ObjectSchema)
+	if !ok || obj.GetGoPath() == "" {
+		return ""
+	}
+
+	path := obj.GetGoPath()
+	if idx := strings.Index(path, "["); idx != -1 {
+		path = path[:idx]
+	}
+
+	return exportedName(path[strings.LastIndex(path, "/")+1:])
+}
+
+// definitionName returns a type name for a definition key like github_com-example-pets-Pet.
+func definitionName(key string) string {
+	if idx := strings.Index(key, "["); idx != -1 {
+		key = key[:idx]
+	}
+
+	key = key[strings.LastIndexAny(key, "-/")+1:]
+
+	name := exportedName(key)
+	if name == "" {
+		name = "Definition"
+	}
+
+	return name
+}
+
+// exportedName converts a JSON name like first_name or firstName into an exported GO name.
+func exportedName(s string) string {
+	var b strings.Builder
+
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(word)
+		b.WriteRune(unicode.ToUpper(r))
+		b.WriteString(word[size:])
+	}
+
+	name := b.String()
+	if name == "" {
+		return ""
+	}
+
+	if r, _ := utf8.DecodeRuneInString(name); !unicode.IsUpper(r) {
+		name = "X" + name
+	}
+
+	return name
+}
+
+func isExportedIdent(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+
+	return unicode.IsUpper(r) && exportedName(name) == name
+}
+
+// jsonLiteral formats a value for an annotation. Strings are used as is.
+func jsonLiteral(v interface{}) string {
+	if str, ok := v.(string); ok {
+		return str
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,726 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxExampleDepth stops examples of recursive schemas that can't be cut short, e.g. a required
+// property that refers back to its own type.
+const maxExampleDepth = 32
+
+// exampleAlphabet holds the characters used for generated strings, in order of preference.
+const exampleAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.~"
+
+// maxExampleSpread is the widest range of whole numbers examples are picked from.
+const maxExampleSpread = 1 << 62
+
+// exampleEpoch is the earliest time used for date and time formats.
+var exampleEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ExampleOptions holds the configuration for building example instances.
+type ExampleOptions struct {
+	// Seed seeds the choices made for values the schema doesn't pin down, such as which enum value
+	// is used or the characters of a string. The same schema and seed always give the same example.
+	Seed int64
+	// Minimal only fills required properties and adds the fewest array items the schema allows.
+	Minimal bool
+}
+
+type exampleGenerator struct {
+	root   JSONSchema
+	opts   ExampleOptions
+	rand   *rand.Rand
+	active map[string]bool
+}
+
+// MarshalExample builds a representative JSON instance of the schema. Defaults, consts and enums are
+// used where the schema has them. Otherwise values are generated to match the type, format,
+// numeric bounds, string lengths and patterns and array sizes. The first branch of a oneOf or anyOf
+// is used and all branches of an allOf are merged. Local $refs are resolved against the definitions
+// of the root schema.
+func MarshalExample(s JSONSchema, opts ExampleOptions) ([]byte, error) {
+	example, err := newExampleGenerator(s, opts).value(s, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(example)
+}
+
+// MarshalIndentExample is like MarshalExample but applies indent to format the output.
+func MarshalIndentExample(s JSONSchema, opts ExampleOptions, prefix, indent string) ([]byte, error) {
+	example, err := newExampleGenerator(s, opts).value(s, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(example, prefix, indent)
+}
+
+func newExampleGenerator(root JSONSchema, opts ExampleOptions) *exampleGenerator {
+	return &exampleGenerator{
+		root: root,
+		opts: opts,
+		rand: rand.New(rand.NewSource(opts.Seed)),
+		// the root is always being built so "#" is never followed again from an optional property
+		active: map[string]bool{"#": true},
+	}
+}
+
+func (g *exampleGenerator) value(s JSONSchema, depth int) (interface{}, error) {
+	if depth > maxExampleDepth {
+		return nil, nil
+	}
+
+	if isFalseSchema(s) {
+		return nil, fmt.Errorf("no value is valid against the schema false")
+	}
+
+	if ref := s.GetRef(); ref != "" {
+		resolved, err := g.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		wasActive := g.active[ref]
+		g.active[ref] = true
+		defer func() { g.active[ref] = wasActive }()
+
+		return g.value(resolved, depth+1)
+	}
+
+	if def := s.GetDefault(); def != nil {
+		return exampleDefault(def, primaryType(typeValue(s.GetType()))), nil
+	}
+
+	if value, hasConst := s.GetConst(); hasConst {
+		return value, nil
+	}
+
+	if enum := s.GetEnum(); len(enum) > 0 {
+		return enum[g.rand.Intn(len(enum))], nil
+	}
+
+	if oneOf := s.GetOneOf(); len(oneOf) > 0 {
+		return g.value(oneOf[0], depth+1)
+	}
+
+	if anyOf := s.GetAnyOf(); len(anyOf) > 0 {
+		return g.value(anyOf[0], depth+1)
+	}
+
+	var example interface{}
+	var err error
+
+	switch primaryType(typeValue(s.GetType())) {
+	case SchemaTypeObject:
+		example, err = g.object(s, depth)
+	case SchemaTypeArray:
+		example, err = g.array(s, depth)
+	case SchemaTypeString:
+		example, err = g.string(s)
+	case SchemaTypeInteger:
+		example = g.number(s, true)
+	case SchemaTypeNumber:
+		example = g.number(s, false)
+	case SchemaTypeBoolean:
+		example = g.rand.Intn(2) == 1
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, branch := range s.GetAllOf() {
+		branchExample, err := g.value(branch, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		example = mergeExamples(example, branchExample)
+	}
+
+	return example, nil
+}
+
+func (g *exampleGenerator) resolve(ref string) (JSONSchema, error) {
+	if ref == "#" {
+		return g.root, nil
+	}
+
+	for _, prefix := range []string{DefinitionRoot, DefsRoot} {
+		if !strings.HasPrefix(ref, prefix) {
+			continue
+		}
+
+		if def, found := g.root.GetDefinitions()[ref[len(prefix):]]; found {
+			return def, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unresolvable $ref %s", ref)
+}
+
+// cyclic returns true if the schema refers to a schema that's already being built.
+func (g *exampleGenerator) cyclic(s JSONSchema) bool {
+	if s.GetRef() != "" {
+		return g.active[s.GetRef()]
+	}
+
+	// nullable refs are wrapped in an anyOf or allOf
+	for _, branches := range [][]JSONSchema{s.GetAnyOf(), s.GetOneOf(), s.GetAllOf()} {
+		if len(branches) > 0 && branches[0].GetRef() != "" {
+			return g.active[branches[0].GetRef()]
+		}
+	}
+
+	return false
+}
+
+func (g *exampleGenerator) object(s JSONSchema, depth int) (interface{}, error) {
+	example := newOrderedObject()
+
+	obj, ok := s.(ObjectSchema)
+	if !ok {
+		return example, nil
+	}
+
+	props := obj.GetProperties()
+	order := obj.GetPropertyOrder()
+
+	required := make(map[string]bool)
+	for _, name := range obj.GetRequired() {
+		required[name] = true
+	}
+
+	included := make(map[string]bool)
+	for _, name := range order {
+		if required[name] || (!g.opts.Minimal && !g.cyclic(props[name]) && !isFalseSchema(props[name])) {
+			included[name] = true
+		}
+	}
+
+	// properties required by the presence of other properties
+	for changed := true; changed; {
+		changed = false
+		for name, deps := range dependentProperties(obj) {
+			if !included[name] {
+				continue
+			}
+			for _, dep := range deps {
+				if _, found := props[dep]; found && !included[dep] {
+					included[dep] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	// optional properties make up the numbers when minProperties asks for more
+	for _, name := range order {
+		if int64(len(included)) >= obj.GetMinProperties() {
+			break
+		}
+		included[name] = true
+	}
+
+	for _, name := range order {
+		if !included[name] {
+			continue
+		}
+
+		value, err := g.value(props[name], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		example.set(name, value)
+	}
+
+	if err := g.extraProperties(example, obj, depth); err != nil {
+		return nil, err
+	}
+
+	return example, nil
+}
+
+// extraProperties adds properties for patternProperties and additionalProperties. Each pattern
+// gets an entry and maps get a single entry unless the example is minimal, then they're only
+// added to make up minProperties.
+func (g *exampleGenerator) extraProperties(example *orderedObject, obj ObjectSchema, depth int) error {
+	patternProps := obj.GetPatternProperties()
+
+	patterns := make([]string, 0, len(patternProps))
+	for pattern := range patternProps {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	wanted := obj.GetMinProperties()
+
+	for _, pattern := range patterns {
+		if g.opts.Minimal && int64(len(example.keys)) >= wanted {
+			return nil
+		}
+
+		name, err := g.patternString(pattern, 1, 0)
+		if err != nil {
+			return err
+		}
+
+		if example.has(name) {
+			continue
+		}
+
+		value, err := g.value(patternProps[pattern], depth+1)
+		if err != nil {
+			return err
+		}
+		example.set(name, value)
+	}
+
+	if !g.opts.Minimal && len(obj.GetProperties()) == 0 && len(patterns) == 0 && wanted < 1 {
+		wanted = 1
+	}
+
+	additional := obj.GetAdditionalProperties()
+	if additional != nil && additional.Schema == nil && !additional.Boolean {
+		return nil
+	}
+
+	for i := 1; int64(len(example.keys)) < wanted; i++ {
+		name := fmt.Sprintf("key%d", i)
+		if example.has(name) {
+			continue
+		}
+
+		var value interface{} = g.letters(8)
+		if additional != nil && additional.Schema != nil {
+			var err error
+			if value, err = g.value(additional.Schema, depth+1); err != nil {
+				return err
+			}
+		}
+		example.set(name, value)
+	}
+
+	return nil
+}
+
+func (g *exampleGenerator) array(s JSONSchema, depth int) (interface{}, error) {
+	example := make([]interface{}, 0)
+
+	arr, ok := s.(ArraySchema)
+	if !ok || arr.GetItems() == nil || isFalseSchema(arr.GetItems()) {
+		return example, nil
+	}
+
+	count := arr.GetMinItems()
+	if !g.opts.Minimal && count < 1 && !g.cyclic(arr.GetItems()) {
+		count = 1
+	}
+	if arr.GetMaxItems() > 0 && count > arr.GetMaxItems() {
+		count = arr.GetMaxItems()
+	}
+
+	seen := make(map[string]bool)
+
+	for int64(len(example)) < count {
+		var item interface{}
+		var err error
+
+		// unique items get a few tries to come up with a value that hasn't been used
+		for attempt := 0; attempt < 10; attempt++ {
+			if item, err = g.value(arr.GetItems(), depth+1); err != nil {
+				return nil, err
+			}
+
+			if !arr.GetUniqueItems() {
+				break
+			}
+
+			key, _ := json.Marshal(item)
+			if !seen[string(key)] {
+				seen[string(key)] = true
+				break
+			}
+		}
+
+		example = append(example, item)
+	}
+
+	return example, nil
+}
+
+func (g *exampleGenerator) string(s JSONSchema) (interface{}, error) {
+	// numbers encoded as strings keep their bounds as x- keywords
+	if num, ok := s.(NumericStringSchema); ok {
+		return strconv.FormatFloat(g.number(num, false).(float64), 'f', -1, 64), nil
+	}
+
+	var minLength, maxLength int64
+	var pattern string
+
+	if str, ok := s.(StringSchema); ok {
+		minLength, maxLength, pattern = str.GetMinLength(), str.GetMaxLength(), str.GetPattern()
+	}
+
+	if simple, ok := s.(SimpleSchema); ok {
+		if formatted, found := g.formatted(simple.GetFormat()); found {
+			return formatted, nil
+		}
+	}
+
+	if pattern != "" {
+		return g.patternString(pattern, minLength, maxLength)
+	}
+
+	length := int64(8)
+	if length < minLength {
+		length = minLength
+	}
+	if maxLength > 0 && length > maxLength {
+		length = maxLength
+	}
+
+	return g.letters(int(length)), nil
+}
+
+// formatted returns an example of a string format.
+func (g *exampleGenerator) formatted(format string) (string, bool) {
+	when := exampleEpoch.Add(time.Duration(g.rand.Int63n(5*365*24)) * time.Hour)
+
+	switch format {
+	case "date-time":
+		return when.Format(time.RFC3339), true
+	case "date":
+		return when.Format("2006-01-02"), true
+	case "time":
+		return when.Format("15:04:05Z07:00"), true
+	case "email":
+		return g.letters(6) + "@example.com", true
+	case "hostname":
+		return g.letters(6) + ".example.com", true
+	case "ipv4":
+		// 192.0.2.0/24 is reserved for documentation
+		return fmt.Sprintf("192.0.2.%d", 1+g.rand.Intn(254)), true
+	case "ipv6":
+		// 2001:db8::/32 is reserved for documentation
+		return fmt.Sprintf("2001:db8::%x", 1+g.rand.Intn(0xfffe)), true
+	case "uri":
+		return "https://example.com/" + g.letters(6), true
+	case "uuid":
+		b := make([]byte, 16)
+		g.rand.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), true
+	}
+
+	return "", false
+}
+
+func (g *exampleGenerator) letters(n int) string {
+	var b strings.Builder
+
+	for i := 0; i < n; i++ {
+		b.WriteByte(exampleAlphabet[g.rand.Intn(26)])
+	}
+
+	return b.String()
+}
+
+// patternString returns a string matching the pattern, trying to keep within the lengths. A
+// maxLength of 0 means there's no maximum.
+func (g *exampleGenerator) patternString(pattern string, minLength, maxLength int64) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern %s: %s", pattern, err)
+	}
+
+	re = re.Simplify()
+
+	var example string
+
+	// repetitions grow with every attempt until the string is long enough
+	for extra := 0; extra < 16; extra++ {
+		var b strings.Builder
+		g.writePattern(&b, re, extra)
+		example = b.String()
+
+		length := int64(len([]rune(example)))
+		if length >= minLength && (maxLength < 1 || length <= maxLength) {
+			break
+		}
+	}
+
+	return example, nil
+}
+
+func (g *exampleGenerator) writePattern(b *strings.Builder, re *syntax.Regexp, extra int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+
+	case syntax.OpCharClass:
+		b.WriteRune(g.classRune(re.Rune))
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(exampleAlphabet[g.rand.Intn(26)])
+
+	case syntax.OpCapture:
+		g.writePattern(b, re.Sub[0], extra)
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.writePattern(b, sub, extra)
+		}
+
+	case syntax.OpAlternate:
+		g.writePattern(b, re.Sub[g.rand.Intn(len(re.Sub))], extra)
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+
+		count := min + g.rand.Intn(3) + extra
+		if max >= 0 && count > max {
+			count = max
+		}
+
+		for i := 0; i < count; i++ {
+			g.writePattern(b, re.Sub[0], extra)
+		}
+	}
+}
+
+// classRune picks a rune from a character class, preferring readable characters.
+func (g *exampleGenerator) classRune(ranges []rune) rune {
+	var candidates []rune
+
+	for _, r := range exampleAlphabet {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if r >= ranges[i] && r <= ranges[i+1] {
+				candidates = append(candidates, r)
+				break
+			}
+		}
+	}
+
+	if len(candidates) > 0 {
+		return candidates[g.rand.Intn(len(candidates))]
+	}
+
+	if len(ranges) < 2 {
+		return 'x'
+	}
+
+	i := 2 * g.rand.Intn(len(ranges)/2)
+	if ranges[i] < '!' && ranges[i+1] >= '!' {
+		return '!'
+	}
+
+	return ranges[i]
+}
+
+func (g *exampleGenerator) number(s JSONSchema, integer bool) interface{} {
+	num, ok := s.(NumericSchema)
+	if !ok {
+		if integer {
+			return int64(g.rand.Intn(100))
+		}
+		return float64(g.rand.Intn(10000)) / 100
+	}
+
+	// 0 can't be told apart from a missing bound, so it's only used when it's within the other bound
+	lo, hi := num.GetMinimum(), num.GetMaximum()
+	exclusiveMin, exclusiveMax := num.GetExclusiveMinimum(), num.GetExclusiveMaximum()
+
+	// draft-06 and newer exclusive limits are numbers of their own
+	if limit, isNumber := num.GetExtensions()["exclusiveMinimum"].(float64); isNumber {
+		lo, exclusiveMin = limit, true
+	}
+	if limit, isNumber := num.GetExtensions()["exclusiveMaximum"].(float64); isNumber {
+		hi, exclusiveMax = limit, true
+	}
+
+	switch {
+	case lo == 0 && hi < 0:
+		lo = hi - 100
+	case hi == 0 && lo < 0:
+		hi = 0
+	case hi == 0:
+		hi = lo + 100
+	}
+
+	if multipleOf := num.GetMultipleOf(); multipleOf > 0 {
+		first, last := math.Ceil(lo/multipleOf), math.Floor(hi/multipleOf)
+		if exclusiveMin && first*multipleOf <= lo {
+			first++
+		}
+		if exclusiveMax && last*multipleOf >= hi {
+			last--
+		}
+		if last < first {
+			last = first
+		}
+
+		value := g.between(first, last) * multipleOf
+		if integer {
+			return integerValue(math.Round(value))
+		}
+		return value
+	}
+
+	if integer {
+		first, last := math.Ceil(lo), math.Floor(hi)
+		if exclusiveMin && first <= lo {
+			first++
+		}
+		if exclusiveMax && last >= hi {
+			last--
+		}
+		if last < first {
+			last = first
+		}
+
+		return integerValue(g.between(first, last))
+	}
+
+	if hi <= lo {
+		return lo
+	}
+
+	// somewhere inside the bounds so that exclusive bounds hold too, rounded for readability
+	value := lo + (hi-lo)*(0.1+0.8*g.rand.Float64())
+	if rounded := math.Round(value*100) / 100; rounded > lo && rounded < hi {
+		value = rounded
+	}
+
+	return value
+}
+
+// between returns a whole number from first to last. Bounds further apart than rand can handle
+// are narrowed to the start of the range.
+func (g *exampleGenerator) between(first, last float64) float64 {
+	spread := last - first
+	if spread > maxExampleSpread {
+		spread = maxExampleSpread
+	}
+
+	return first + float64(g.rand.Int63n(int64(spread)+1))
+}
+
+// integerValue returns v as an int64 when it fits, bounds outside of int64 are still whole numbers
+func integerValue(v float64) interface{} {
+	if v >= math.MinInt64 && v < math.MaxInt64 {
+		return int64(v)
+	}
+
+	return v
+}
+
+// dependentProperties returns the properties each property requires when it's present.
+func dependentProperties(obj ObjectSchema) map[string][]string {
+	deps := make(map[string][]string)
+
+	for name, dep := range obj.GetDependencies() {
+		if dep != nil && dep.Schema == nil {
+			deps[name] = append(deps[name], dep.Array...)
+		}
+	}
+
+	for name, required := range obj.GetDependentRequired() {
+		deps[name] = append(deps[name], required...)
+	}
+
+	return deps
+}
+
+// exampleDefault returns the default value. The generator writes defaults from annotations as
+// strings, so they're converted to the type of the schema where possible.
+func exampleDefault(def interface{}, jsonType string) interface{} {
+	str, isString := def.(string)
+	if !isString {
+		return def
+	}
+
+	switch jsonType {
+	case SchemaTypeInteger:
+		if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return i
+		}
+	case SchemaTypeNumber:
+		if f, err := strconv.ParseFloat(str, 64); err == nil {
+			return f
+		}
+	case SchemaTypeBoolean:
+		if b, err := strconv.ParseBool(str); err == nil {
+			return b
+		}
+	case SchemaTypeObject, SchemaTypeArray:
+		var v interface{}
+		if err := json.Unmarshal([]byte(str), &v); err == nil {
+			return v
+		}
+	}
+
+	return def
+}
+
+// mergeExamples merges the properties of allOf branches into one object. Anything other than two
+// objects keeps the first example.
+func mergeExamples(example, branch interface{}) interface{} {
+	if example == nil {
+		return branch
+	}
+
+	obj, isObj := example.(*orderedObject)
+	branchObj, branchIsObj := branch.(*orderedObject)
+
+	if !isObj || !branchIsObj {
+		return example
+	}
+
+	for _, key := range branchObj.keys {
+		if !obj.has(key) {
+			obj.set(key, branchObj.values[key])
+		}
+	}
+
+	return obj
+}
+
+// typeValue returns the type as it's found in JSON, a string or a list of strings.
+func typeValue(jsonType *StringOrArray) interface{} {
+	if jsonType == nil {
+		return nil
+	}
+
+	if len(jsonType.Array) > 0 {
+		types := make([]interface{}, 0, len(jsonType.Array))
+		for _, t := range jsonType.Array {
+			types = append(types, t)
+		}
+		return types
+	}
+
+	return jsonType.String
+}
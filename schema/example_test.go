@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExampleSkipsFalseSchemas(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := FromJSON([]byte(`{
+		"type": "object",
+		"properties": {
+			"anything": true,
+			"never": false,
+			"empty": {"type": "array", "items": false}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	example, err := MarshalExample(parsed, ExampleOptions{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"anything": null, "empty": []}`, string(example))
+
+	// nothing is valid against a required false schema
+	parsed, err = FromJSON([]byte(`{"type": "object", "required": ["never"], "properties": {"never": false}}`))
+	assert.NoError(t, err)
+
+	_, err = MarshalExample(parsed, ExampleOptions{})
+	assert.EqualError(t, err, "no value is valid against the schema false")
+}
+
+func TestExampleNumbersWithHugeBounds(t *testing.T) {
+	t.Parallel()
+
+	for original, expected := range map[string]func(float64) bool{
+		`{"type": "integer", "maximum": 9223372036854775807}`:                    func(n float64) bool { return n >= 0 },
+		`{"type": "integer", "maximum": 18446744073709551615}`:                   func(n float64) bool { return n >= 0 },
+		`{"type": "integer", "minimum": -1e20, "maximum": 1e20}`:                 func(n float64) bool { return n >= -1e20 && n <= 1e20 },
+		`{"type": "number", "minimum": -1e20, "maximum": 1e20, "multipleOf": 2}`: func(n float64) bool { return n >= -1e20 && n <= 1e20 },
+		`{"type": "integer", "minimum": 1e20, "maximum": 1e21}`:                  func(n float64) bool { return n >= 1e20 && n <= 1e21 },
+		`{"type": "integer", "minimum": -9223372036854775808, "maximum": 0}`:     func(n float64) bool { return n <= 0 },
+	} {
+		parsed, err := FromJSON([]byte(original))
+		assert.NoError(t, err, original)
+
+		example, err := MarshalExample(parsed, ExampleOptions{})
+		assert.NoError(t, err, original)
+
+		var n float64
+		assert.NoError(t, json.Unmarshal(example, &n), original)
+		assert.True(t, expected(n), "%s gave %s", original, example)
+	}
+}
+
+func TestExampleFromParsedSchema(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := FromJSON([]byte(`{
+		"type": "object",
+		"properties": {
+			"count": {"type": "integer", "default": "5"},
+			"size": {"type": "string", "enum": ["small"]},
+			"pet": {"oneOf": [{"$ref": "#/definitions/Dog"}, {"type": "string"}]},
+			"tags": {"type": "array", "items": {"type": "string"}, "minItems": 3},
+			"score": {"type": "number", "exclusiveMinimum": 2, "maximum": 3}
+		},
+		"definitions": {
+			"Dog": {"type": "object", "properties": {"bark": {"type": "boolean", "const": true}}}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	exampleBytes, err := MarshalExample(parsed, ExampleOptions{Seed: 42})
+	assert.NoError(t, err)
+
+	var example map[string]interface{}
+	assert.NoError(t, json.Unmarshal(exampleBytes, &example))
+
+	assert.Equal(t, float64(5), example["count"])
+	assert.Equal(t, "small", example["size"])
+	assert.Equal(t, map[string]interface{}{"bark": true}, example["pet"])
+	assert.Len(t, example["tags"], 3)
+	assert.Greater(t, example["score"], float64(2))
+	assert.LessOrEqual(t, example["score"], float64(3))
+}
+
+func TestExampleFollowsDefs(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := FromJSON([]byte(`{
+		"type": "object",
+		"required": ["pet"],
+		"properties": {"pet": {"$ref": "#/$defs/Dog"}},
+		"$defs": {
+			"Dog": {"type": "object", "properties": {"bark": {"type": "boolean", "const": true}}}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	example, err := MarshalExample(parsed, ExampleOptions{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"pet": {"bark": true}}`, string(example))
+
+	parsed, err = FromJSON([]byte(`{"type": "object", "required": ["pet"], "properties": {"pet": {"$ref": "#/$defs/Cat"}}}`))
+	assert.NoError(t, err)
+
+	_, err = MarshalExample(parsed, ExampleOptions{})
+	assert.EqualError(t, err, "unresolvable $ref #/$defs/Cat")
+}
@@ -0,0 +1,197 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// marshalSchema converts a schema to JSON the same way encoding/json does, with the fields of
+// embedded schemas inlined in declaration order, and then adds the extension keywords. Schemas
+// can't use a plain MarshalJSON on basicSchema because it would be promoted to every schema that
+// embeds it.
+func marshalSchema(s JSONSchema) ([]byte, error) {
+	return schemaObject(s).MarshalJSON()
+}
+
+// schemaObject returns the keywords of the schema in the order they're marshalled.
+func schemaObject(s JSONSchema) *orderedObject {
+	obj := newOrderedObject()
+
+	addSchemaFields(obj, reflect.ValueOf(s).Elem())
+
+	// parsed schemas write their definitions back under the keyword they were read from
+	if kw, ok := s.(interface{ definitionsKeyword() string }); ok {
+		obj.rename("definitions", kw.definitionsKeyword())
+	}
+
+	extensions := s.GetExtensions()
+
+	keys := make([]string, 0, len(extensions))
+	for k := range extensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// modelled keywords win over extensions with the same name
+	for _, k := range keys {
+		if !obj.has(k) {
+			obj.set(k, extensions[k])
+		}
+	}
+
+	return obj
+}
+
+func addSchemaFields(obj *orderedObject, v reflect.Value) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		if field.Anonymous {
+			if value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+
+			addSchemaFields(obj, value)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		name := tag[0]
+
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if len(tag) > 1 && tag[1] == "omitempty" && isEmptyValue(value) {
+			continue
+		}
+
+		obj.set(name, value.Interface())
+	}
+}
+
+// isEmptyValue reports whether the value would be dropped by omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// jsonKeyOrder returns the keys of the object found under keyword in the JSON object, in the order
+// they appear.
+func jsonKeyOrder(js []byte, keyword string) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+
+	tree, err := decodeOrdered(dec)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if obj, ok := tree.(*orderedObject); ok {
+		if sub, ok := obj.get(keyword).(*orderedObject); ok {
+			return sub.keys, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// restorePropertyOrder sets the property order of the objects nested in s from the JSON it was
+// parsed from. Nested schemas are parsed from re-marshalled maps, which don't keep the order of
+// their keys.
+func restorePropertyOrder(s JSONSchema, node interface{}) {
+	obj, ok := node.(*orderedObject)
+	if s == nil || !ok {
+		return
+	}
+
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		items, _ := obj.get(keyword).([]interface{})
+		var branches []JSONSchema
+
+		switch keyword {
+		case "allOf":
+			branches = s.GetAllOf()
+		case "anyOf":
+			branches = s.GetAnyOf()
+		case "oneOf":
+			branches = s.GetOneOf()
+		}
+
+		for i := 0; i < len(items) && i < len(branches); i++ {
+			restorePropertyOrder(branches[i], items[i])
+		}
+	}
+
+	restorePropertyOrder(s.GetNot(), obj.get("not"))
+	if obj.has("definitions") {
+		restoreMapOrder(s.GetDefinitions(), obj.get("definitions"))
+	} else {
+		restoreMapOrder(s.GetDefinitions(), obj.get("$defs"))
+	}
+
+	if arr, ok := s.(ArraySchema); ok {
+		restorePropertyOrder(arr.GetItems(), obj.get("items"))
+	}
+
+	objSchema, ok := s.(ObjectSchema)
+	if !ok {
+		return
+	}
+
+	if props, ok := obj.get("properties").(*orderedObject); ok {
+		objSchema.SetPropertyOrder(props.keys)
+	}
+
+	restoreMapOrder(objSchema.GetProperties(), obj.get("properties"))
+	restoreMapOrder(objSchema.GetPatternProperties(), obj.get("patternProperties"))
+	restoreMapOrder(objSchema.GetDependentSchemas(), obj.get("dependentSchemas"))
+	restorePropertyOrder(objSchema.GetPropertyNames(), obj.get("propertyNames"))
+
+	if ap := objSchema.GetAdditionalProperties(); ap != nil {
+		restorePropertyOrder(ap.Schema, obj.get("additionalProperties"))
+	}
+}
+
+func restoreMapOrder(schemas map[string]JSONSchema, node interface{}) {
+	obj, ok := node.(*orderedObject)
+	if !ok {
+		return
+	}
+
+	for key, s := range schemas {
+		restorePropertyOrder(s, obj.get(key))
+	}
+}
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"bytes"
 	"encoding/json"
 	"strings"
 )
@@ -33,6 +34,17 @@ func NewStringOrArray(v interface{}) *StringOrArray {
 		}
 	}
 
+	// arrays decoded from JSON
+	if ia, ok := v.([]interface{}); ok {
+		soa := &StringOrArray{}
+		for _, item := range ia {
+			if is, ok := item.(string); ok {
+				soa.Array = append(soa.Array, is)
+			}
+		}
+		return soa
+	}
+
 	return &StringOrArray{}
 }
 
@@ -55,13 +67,26 @@ func (soa *StringOrArray) UnmarshalJSON(data []byte) error {
 		}
 		sa.Array = a
 	} else {
-		sa.String = string(data)
+		if err := json.Unmarshal(data, &sa.String); err != nil {
+			return err
+		}
 	}
 
 	*soa = sa
 	return nil
 }
 
+// constValue holds the value of the const keyword so that falsy values like "", 0 and null are
+// still marshalled.
+type constValue struct {
+	value interface{}
+}
+
+// MarshalJSON convert this object to JSON
+func (cv *constValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cv.value)
+}
+
 // JSONSchema is the base interface that represents common json-schema attributes.
 type JSONSchema interface {
 	Clone() JSONSchema
@@ -77,6 +102,12 @@ type JSONSchema interface {
 	GetNot() JSONSchema
 	GetDefinitions() map[string]JSONSchema
 	GetDefault() interface{}
+	GetEnum() []interface{}
+	GetConst() (interface{}, bool)
+	GetEnumNames() []string
+	GetEnumDescriptions() []string
+	GetNullable() bool
+	GetExtensions() map[string]interface{}
 
 	AddDefinition(key string, def JSONSchema)
 	SetSchemaURI(uri string)
@@ -89,69 +120,122 @@ type JSONSchema interface {
 	SetOneOf(items []JSONSchema)
 	SetNot(not JSONSchema)
 	SetDefault(def interface{})
+	SetEnum(enum []interface{})
+	SetConst(value interface{})
+	SetEnumNames(names []string)
+	SetEnumDescriptions(descriptions []string)
+	SetNullable(nullable bool)
 	SetType(typeList string)
+	SetExtension(key string, value interface{})
 }
 
 // BasicSchema is the base implementation of the JsonSchema interface.
 type basicSchema struct {
-	SchemaURI    string                `json:"$schema,omitempty"`
-	ID           string                `json:"id,omitempty"`
-	Ref          string                `json:"$ref,omitempty"`
-	JSONType     *StringOrArray        `json:"type,omitempty"`
-	Title        string                `json:"title,omitempty"`
-	Description  string                `json:"description,omitempty"`
-	AllOf        []JSONSchema          `json:"allOf,omitempty"`
-	AnyOf        []JSONSchema          `json:"anyOf,omitempty"`
-	OneOf        []JSONSchema          `json:"oneOf,omitempty"`
-	Not          JSONSchema            `json:"not,omitempty"`
-	Definitions  map[string]JSONSchema `json:"definitions,omitempty"`
-	DefaultValue interface{}           `json:"default,omitempty"`
-}
-
-// FromJSON returns a JSONSchema object from the given json bytes.
+	SchemaURI    string                 `json:"$schema,omitempty"`
+	ID           string                 `json:"id,omitempty"`
+	Ref          string                 `json:"$ref,omitempty"`
+	JSONType     *StringOrArray         `json:"type,omitempty"`
+	Title        string                 `json:"title,omitempty"`
+	Description  string                 `json:"description,omitempty"`
+	AllOf        []JSONSchema           `json:"allOf,omitempty"`
+	AnyOf        []JSONSchema           `json:"anyOf,omitempty"`
+	OneOf        []JSONSchema           `json:"oneOf,omitempty"`
+	Not          JSONSchema             `json:"not,omitempty"`
+	Definitions  map[string]JSONSchema  `json:"definitions,omitempty"`
+	DefaultValue interface{}            `json:"default,omitempty"`
+	Enum         []interface{}          `json:"enum,omitempty"`
+	Const        *constValue            `json:"const,omitempty"`
+	EnumNames    []string               `json:"x-enumNames,omitempty"`
+	EnumDescs    []string               `json:"x-enumDescriptions,omitempty"`
+	Nullable     bool                   `json:"nullable,omitempty"`
+	Extensions   map[string]interface{} `json:"-"`
+	// defsKeyword is the keyword the definitions were parsed from, 2019-09 and newer use $defs
+	defsKeyword string
+}
+
+// FromJSON returns a JSONSchema object from the given json bytes. Keywords the schema types don't
+// model, including x- extensions, are kept in the extensions of the schema they belong to so that
+// marshalling the result gives back the same JSON.
 func FromJSON(js []byte) (JSONSchema, error) {
 	var err error
 	var obj JSONSchema
 
 	var stuff map[string]interface{}
 
+	// draft-06 and newer allow true and false anywhere a schema is expected
+	switch string(bytes.TrimSpace(js)) {
+	case "true":
+		return NewBooleanSchema(true), nil
+	case "false":
+		return NewBooleanSchema(false), nil
+	}
+
 	err = json.Unmarshal(js, &stuff)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if jsontype, ok := stuff["type"]; ok {
-		switch jsontype {
-		case SchemaTypeObject:
-			obj = &defaultObjectSchema{}
-			err = json.Unmarshal(js, obj)
-			return obj, err
-		case SchemaTypeString:
-			obj = &defaultStringSchema{}
-			err = json.Unmarshal(js, obj)
-		case SchemaTypeArray:
-			obj = &defaultArraySchema{}
-			err = json.Unmarshal(js, obj)
-		case SchemaTypeInteger, SchemaTypeNumber:
-			obj = &defaultNumericSchema{}
-			err = json.Unmarshal(js, obj)
-		case SchemaTypeBoolean:
-			obj = &defaultSimpleSchema{}
-			err = json.Unmarshal(js, obj)
-		default:
-			obj = &defaultSimpleSchema{}
-			err = json.Unmarshal(js, obj)
+	switch primaryType(stuff["type"]) {
+	case SchemaTypeObject:
+		obj = &defaultObjectSchema{}
+	case SchemaTypeString:
+		obj = &defaultStringSchema{}
+		for _, k := range []string{"x-maximum", "x-minimum", "x-multipleOf"} {
+			if _, found := stuff[k]; found {
+				obj = &defaultNumericStringSchema{}
+			}
 		}
+	case SchemaTypeArray:
+		obj = &defaultArraySchema{}
+	case SchemaTypeInteger, SchemaTypeNumber:
+		obj = &defaultNumericSchema{}
+	case "":
+		// schemas without a type, such as a $ref, {} or a bare anyOf, are still valid schemas
+		obj = &basicSchema{}
+	default:
+		obj = &defaultSimpleSchema{}
 	}
 
-	if ref, ok := stuff["$ref"]; ok {
-		obj = &basicSchema{}
-		obj.(*basicSchema).Ref = ref.(string)
+	err = json.Unmarshal(js, obj)
+
+	if err != nil {
+		return obj, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+
+	tree, err := decodeOrdered(dec)
+
+	if err != nil {
+		return obj, err
 	}
 
-	return obj, err
+	restorePropertyOrder(obj, tree)
+
+	return obj, nil
+}
+
+// primaryType returns the type that decides which schema implementation is used. For a list of
+// types that's the first one that isn't null, the keywords of the others end up as extensions.
+func primaryType(jsonType interface{}) string {
+	switch t := jsonType.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if is, ok := item.(string); ok && is != SchemaTypeNull {
+				return is
+			}
+		}
 
+		if len(t) > 0 {
+			return SchemaTypeNull
+		}
+	}
+
+	return ""
 }
 
 // NewBasicSchema creates a new BasicSchema
@@ -179,95 +263,194 @@ func (s *basicSchema) UnmarshalJSON(b []byte) error {
 
 	err = json.Unmarshal(b, &stuff)
 
-	if err == nil {
-		for k, v := range stuff {
+	if err != nil {
+		return err
+	}
+
+	for k, v := range stuff {
+		modelled, err := s.unmarshalKeyword(k, v, stuff)
+		if err != nil {
+			return err
+		}
+
+		// keywords that aren't modelled, or don't have the expected shape, are kept as they are
+		if !modelled {
+			s.SetExtension(k, v)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalKeyword sets the field for a decoded keyword and returns whether the keyword is
+// modelled by basicSchema.
+func (s *basicSchema) unmarshalKeyword(k string, v interface{}, stuff map[string]interface{}) (bool, error) {
+	var ok bool
+	var err error
+
+	switch k {
+	case "$schema":
+		s.SchemaURI, ok = v.(string)
+	case "id":
+		s.ID, ok = v.(string)
+	case "$ref":
+		s.Ref, ok = v.(string)
+	case "type":
+		if _, isString := v.(string); isString {
+			s.JSONType, ok = NewStringOrArray(v), true
+		} else if types, isList := stringList(v); isList {
+			s.JSONType, ok = &StringOrArray{Array: types}, true
+		}
+	case "title":
+		s.Title, ok = v.(string)
+	case "description":
+		s.Description, ok = v.(string)
+	case "allOf", "anyOf", "oneOf":
+		var branches []JSONSchema
+		if branches, ok, err = subschemaList(v); ok {
 			switch k {
-			case "$schema":
-				s.SchemaURI = v.(string)
-			case "id":
-				s.ID = v.(string)
-			case "$ref":
-				s.Ref = v.(string)
-			case "type":
-				s.JSONType = NewStringOrArray(v)
-			case "title":
-				s.Title = v.(string)
-			case "description":
-				s.Description = v.(string)
 			case "allOf":
-				for _, xo := range v.([]interface{}) {
-					xb, xerr := json.Marshal(xo)
-					if xerr != nil {
-						return xerr
-					}
-					xs, xerr := FromJSON(xb)
-					if xerr != nil {
-						return xerr
-					}
-					s.AllOf = append(s.AllOf, xs)
-				}
-
+				s.AllOf = branches
 			case "anyOf":
-				for _, xo := range v.([]interface{}) {
-					xb, xerr := json.Marshal(xo)
-					if xerr != nil {
-						return xerr
-					}
-					xs, xerr := FromJSON(xb)
-					if xerr != nil {
-						return xerr
-					}
-					s.AnyOf = append(s.AnyOf, xs)
-				}
-
+				s.AnyOf = branches
 			case "oneOf":
-				for _, xo := range v.([]interface{}) {
-					xb, xerr := json.Marshal(xo)
-					if xerr != nil {
-						return xerr
-					}
-					xs, xerr := FromJSON(xb)
-					if xerr != nil {
-						return xerr
-					}
-					s.OneOf = append(s.OneOf, xs)
-				}
-
-			case "not":
-				xb, xerr := json.Marshal(v)
-				if xerr != nil {
-					return err
-				}
-				xs, xerr := FromJSON(xb)
-				if xerr != nil {
-					return xerr
-				}
-				s.Not = xs
-			case "definitions":
-				for mk, mv := range v.(map[string]interface{}) {
-					mb, xerr := json.Marshal(mv)
-					if xerr != nil {
-						return xerr
-					}
-					ms, xerr := FromJSON(mb)
-					if xerr != nil {
-						return xerr
-					}
-					s.Definitions[mk] = ms
-				}
-				//			case "default":
-				//				s.Description = v.(string)
+				s.OneOf = branches
 			}
 		}
+	case "not":
+		s.Not, ok, err = subschema(v)
+	case "definitions", "$defs":
+		// a schema with both keeps $defs as is
+		if _, hasDefinitions := stuff["definitions"]; k == "$defs" && hasDefinitions {
+			return false, nil
+		}
+
+		var defs map[string]JSONSchema
+		if defs, ok, err = subschemaMap(v); ok {
+			s.Definitions = defs
+			s.defsKeyword = k
+		}
+	case "enum":
+		s.Enum, ok = v.([]interface{})
+	case "const":
+		s.Const, ok = &constValue{value: v}, true
+	case "x-enumNames":
+		s.EnumNames, ok = stringList(v)
+	case "x-enumDescriptions":
+		s.EnumDescs, ok = stringList(v)
+	case "nullable":
+		s.Nullable, ok = v.(bool)
+	case "default":
+		s.DefaultValue, ok = v, true
+	}
+
+	return ok, err
+}
+
+// subschema parses a decoded JSON value as a schema. ok is false if the value isn't an object or a
+// boolean.
+func subschema(v interface{}) (JSONSchema, bool, error) {
+	switch v.(type) {
+	case map[string]interface{}, bool:
+	default:
+		return nil, false, nil
+	}
+
+	sb, err := json.Marshal(v)
+	if err != nil {
+		return nil, false, err
+	}
+
+	parsed, err := FromJSON(sb)
+	if err != nil {
+		return nil, false, err
 	}
 
-	return err
+	return parsed, true, nil
+}
+
+// subschemaList parses a decoded JSON array of schemas. ok is false if any of the items isn't a
+// schema.
+func subschemaList(v interface{}) ([]JSONSchema, bool, error) {
+	items, isList := v.([]interface{})
+	if !isList {
+		return nil, false, nil
+	}
+
+	schemas := make([]JSONSchema, 0, len(items))
+
+	for _, item := range items {
+		parsed, ok, err := subschema(item)
+		if !ok || err != nil {
+			return nil, false, err
+		}
+
+		schemas = append(schemas, parsed)
+	}
+
+	return schemas, true, nil
+}
+
+// subschemaMap parses a decoded JSON object whose values are schemas. ok is false if any of the
+// values isn't a schema.
+func subschemaMap(v interface{}) (map[string]JSONSchema, bool, error) {
+	values, isMap := v.(map[string]interface{})
+	if !isMap {
+		return nil, false, nil
+	}
+
+	schemas := make(map[string]JSONSchema, len(values))
+
+	for key, value := range values {
+		parsed, ok, err := subschema(value)
+		if !ok || err != nil {
+			return nil, false, err
+		}
+
+		schemas[key] = parsed
+	}
+
+	return schemas, true, nil
+}
+
+// stringList returns a decoded JSON array of strings. ok is false if any of the items isn't a
+// string.
+func stringList(v interface{}) ([]string, bool) {
+	items, isList := v.([]interface{})
+	if !isList {
+		return nil, false
+	}
+
+	strs := make([]string, 0, len(items))
+
+	for _, item := range items {
+		str, isString := item.(string)
+		if !isString {
+			return nil, false
+		}
+
+		strs = append(strs, str)
+	}
+
+	return strs, true
+}
+
+// MarshalJSON converts this schema to JSON including its extensions
+func (s *basicSchema) MarshalJSON() ([]byte, error) {
+	return marshalSchema(s)
 }
 
 func (s *basicSchema) Clone() JSONSchema {
 	s2 := &basicSchema{}
 	*s2 = *s
 
+	if s.Extensions != nil {
+		s2.Extensions = make(map[string]interface{}, len(s.Extensions))
+		for k, v := range s.Extensions {
+			s2.Extensions[k] = v
+		}
+	}
+
 	return s2
 }
 
@@ -319,6 +502,36 @@ func (s *basicSchema) GetDefault() interface{} {
 	return s.DefaultValue
 }
 
+func (s *basicSchema) GetEnum() []interface{} {
+	return s.Enum
+}
+
+func (s *basicSchema) GetConst() (interface{}, bool) {
+	if s.Const == nil {
+		return nil, false
+	}
+
+	return s.Const.value, true
+}
+
+func (s *basicSchema) GetEnumNames() []string {
+	return s.EnumNames
+}
+
+func (s *basicSchema) GetEnumDescriptions() []string {
+	return s.EnumDescs
+}
+
+func (s *basicSchema) GetNullable() bool {
+	return s.Nullable
+}
+
+// GetExtensions returns the keywords that aren't modelled by the schema, such as x- extensions or
+// keywords from newer drafts, keyed by name.
+func (s *basicSchema) GetExtensions() map[string]interface{} {
+	return s.Extensions
+}
+
 func (s *basicSchema) AddDefinition(key string, def JSONSchema) {
 	s.Definitions[key] = def
 }
@@ -362,6 +575,26 @@ func (s *basicSchema) SetDefault(def interface{}) {
 	s.DefaultValue = def
 }
 
+func (s *basicSchema) SetEnum(enum []interface{}) {
+	s.Enum = enum
+}
+
+func (s *basicSchema) SetConst(value interface{}) {
+	s.Const = &constValue{value: value}
+}
+
+func (s *basicSchema) SetEnumNames(names []string) {
+	s.EnumNames = names
+}
+
+func (s *basicSchema) SetEnumDescriptions(descriptions []string) {
+	s.EnumDescs = descriptions
+}
+
+func (s *basicSchema) SetNullable(nullable bool) {
+	s.Nullable = nullable
+}
+
 func (s *basicSchema) SetType(typeList string) {
 	if len(strings.TrimSpace(typeList)) < 1 {
 		return
@@ -375,3 +608,28 @@ func (s *basicSchema) SetType(typeList string) {
 		s.JSONType = &StringOrArray{String: types[0]}
 	}
 }
+
+// SetExtension sets a keyword that isn't modelled by the schema. It's written after the modelled
+// keywords when the schema is marshalled.
+func (s *basicSchema) SetExtension(key string, value interface{}) {
+	if s.Extensions == nil {
+		s.Extensions = make(map[string]interface{})
+	}
+
+	s.Extensions[key] = value
+}
+
+// definitionsKeyword returns the keyword the definitions are marshalled under.
+func (s *basicSchema) definitionsKeyword() string {
+	if s.defsKeyword == "" {
+		return "definitions"
+	}
+
+	return s.defsKeyword
+}
+
+// claimKeyword removes a keyword from the extensions once a schema that embeds basicSchema has
+// unmarshalled it itself.
+func (s *basicSchema) claimKeyword(key string) {
+	delete(s.Extensions, key)
+}
@@ -0,0 +1,129 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertRoundTrip(t *testing.T, original string) JSONSchema {
+	parsed, err := FromJSON([]byte(original))
+	assert.NoError(t, err, original)
+
+	marshalled, err := json.Marshal(parsed)
+	assert.NoError(t, err, original)
+	assert.JSONEq(t, original, string(marshalled))
+
+	return parsed
+}
+
+func TestParseBooleanSchemas(t *testing.T) {
+	t.Parallel()
+
+	for _, original := range []string{
+		`true`,
+		`false`,
+		`{"type": "object", "properties": {"a": true, "b": false}}`,
+		`{"items": true}`,
+		`{"type": "array", "items": true}`,
+		`{"not": true}`,
+		`{"type": "array", "prefixItems": [{"type": "string"}, true], "items": false}`,
+		`{"allOf": [true, {"type": "string"}], "definitions": {"Never": false}}`,
+		`{"type": "object", "patternProperties": {"^x-": false}, "propertyNames": true, "additionalProperties": false}`,
+	} {
+		assertRoundTrip(t, original)
+	}
+
+	parsed := assertRoundTrip(t, `{"type": "object", "properties": {"a": true, "b": false}}`)
+	props := parsed.(ObjectSchema).GetProperties()
+
+	assert.True(t, props["a"].(BooleanSchema).GetBoolean())
+	assert.Nil(t, props["a"].GetNot())
+
+	// false still reads as a schema that allows nothing
+	assert.False(t, props["b"].(BooleanSchema).GetBoolean())
+	assert.NotNil(t, props["b"].GetNot())
+
+	items := assertRoundTrip(t, `{"type": "array", "items": false}`).(ArraySchema).GetItems()
+	assert.False(t, items.(BooleanSchema).GetBoolean())
+}
+
+func TestParseDefs(t *testing.T) {
+	t.Parallel()
+
+	parsed := assertRoundTrip(t, `{
+		"type": "object",
+		"properties": {"pet": {"$ref": "#/$defs/Pet"}},
+		"$defs": {"Pet": {"type": "object", "properties": {"name": {"type": "string"}}}}
+	}`)
+
+	assert.Contains(t, parsed.GetDefinitions(), "Pet")
+	assert.NotContains(t, parsed.GetExtensions(), "$defs")
+
+	marshalled, err := json.Marshal(parsed)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(marshalled), `"definitions"`)
+
+	// definitions win when a schema has both
+	parsed = assertRoundTrip(t, `{"definitions": {"A": {"type": "string"}}, "$defs": {"B": {"type": "integer"}}}`)
+
+	assert.Contains(t, parsed.GetDefinitions(), "A")
+	assert.NotContains(t, parsed.GetDefinitions(), "B")
+	assert.Contains(t, parsed.GetExtensions(), "$defs")
+}
+
+func TestParseKeepsUnexpectedShapesAsExtensions(t *testing.T) {
+	t.Parallel()
+
+	for keyword, original := range map[string]string{
+		"x-enumNames":          `{"x-enumNames": [1]}`,
+		"x-enumDescriptions":   `{"x-enumDescriptions": "one"}`,
+		"nullable":             `{"nullable": "yes"}`,
+		"title":                `{"title": 1}`,
+		"not":                  `{"not": "never"}`,
+		"maxLength":            `{"type": "string", "maxLength": "3"}`,
+		"items":                `{"type": "array", "items": [{"type": "string"}]}`,
+		"uniqueItems":          `{"type": "array", "uniqueItems": "yes"}`,
+		"maximum":              `{"type": "integer", "maximum": "10"}`,
+		"format":               `{"type": "string", "format": 1}`,
+		"required":             `{"type": "object", "required": "name"}`,
+		"additionalProperties": `{"type": "object", "additionalProperties": "no"}`,
+	} {
+		parsed := assertRoundTrip(t, original)
+		assert.Contains(t, parsed.GetExtensions(), keyword, original)
+	}
+}
+
+func TestParseKeepsUnknownKeywords(t *testing.T) {
+	t.Parallel()
+
+	original := `{
+		"type": "object",
+		"x-vendor": {"owner": "pets"},
+		"examples": [{"name": "rex"}],
+		"properties": {
+			"name": {"type": ["string", "null"], "maxLength": 3, "default": "rex", "readOnly": true},
+			"pet": {"oneOf": [{"$ref": "#/definitions/Cat"}, {"type": "null"}]},
+			"any": {},
+			"cat": {"$ref": "#/definitions/Cat", "description": "the cat"},
+			"counts": {"type": "object", "additionalProperties": {"type": "integer", "minimum": 1}},
+			"either": {"type": ["string", "integer"], "minLength": 1, "maximum": 5}
+		},
+		"definitions": {
+			"Cat": {"type": "object", "x-go-path": "github.com/acme/pets/Cat"}
+		}
+	}`
+
+	parsed := assertRoundTrip(t, original)
+
+	assert.Equal(t, map[string]interface{}{"owner": "pets"}, parsed.GetExtensions()["x-vendor"])
+
+	props := parsed.(ObjectSchema).GetProperties()
+	assert.Equal(t, "rex", props["name"].GetDefault())
+	assert.Equal(t, []string{"string", "null"}, props["name"].GetType().Array)
+	assert.Len(t, props["pet"].GetOneOf(), 2)
+	assert.Equal(t, "the cat", props["cat"].GetDescription())
+	assert.NotNil(t, props["counts"].(ObjectSchema).GetAdditionalProperties().Schema)
+	assert.Equal(t, "github.com/acme/pets/Cat", parsed.GetDefinitions()["Cat"].(ObjectSchema).GetGoPath())
+}
@@ -0,0 +1,366 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIVersion is the OpenAPI version that components are written for.
+type OpenAPIVersion string
+
+const (
+	// OpenAPIVersion30 uses the OpenAPI 3.0 schema object, a subset of draft-04 with the nullable
+	// keyword.
+	OpenAPIVersion30 OpenAPIVersion = "3.0"
+	// OpenAPIVersion31 uses the OpenAPI 3.1 schema object which is the full 2020-12 spec.
+	OpenAPIVersion31 OpenAPIVersion = "3.1"
+
+	// ComponentsRoot is the root of refs to OpenAPI component schemas
+	ComponentsRoot = "#/components/schemas/"
+)
+
+// openAPI30Keywords are the draft-04 keywords the OpenAPI 3.0 schema object supports. Other
+// keywords, apart from x- extensions, are dropped.
+var openAPI30Keywords = map[string]bool{
+	"$ref": true, "title": true, "description": true, "type": true, "format": true, "default": true,
+	"enum": true, "multipleOf": true, "maximum": true, "exclusiveMaximum": true, "minimum": true,
+	"exclusiveMinimum": true, "maxLength": true, "minLength": true, "pattern": true, "maxItems": true,
+	"minItems": true, "uniqueItems": true, "maxProperties": true, "minProperties": true, "required": true,
+	"allOf": true, "oneOf": true, "anyOf": true, "not": true, "items": true, "properties": true,
+	"additionalProperties": true, "nullable": true, "readOnly": true, "writeOnly": true,
+	"example": true, "deprecated": true, "discriminator": true, "xml": true, "externalDocs": true,
+}
+
+// invalidComponentChars matches the runs of characters OpenAPI doesn't allow in component names,
+// which must match ^[a-zA-Z0-9.\-_]+$
+var invalidComponentChars = regexp.MustCompile(`[^a-zA-Z0-9.\-_]+`)
+
+var repeatedDots = regexp.MustCompile(`\.{2,}`)
+
+// componentNameReplacer spells out the parts of type arguments, slices before brackets
+var componentNameReplacer = strings.NewReplacer("[]", "slice.", "*", "ptr.", "[", ".", "]", ".", ",", ".", " ", "")
+
+// MarshalOpenAPIComponents marshals the schemas, keyed by definition key, as an indented JSON
+// document with just components.schemas. Refs to definitions are rewritten to point at the
+// components, definitions are expected to be passed as components themselves, and keywords are
+// converted to what the OpenAPI version supports.
+//
+// Keys that aren't valid component names, like those of generic instantiations, get their type
+// arguments separated by dots instead of brackets and refs are rewritten to match.
+func MarshalOpenAPIComponents(schemas map[string]JSONSchema, version OpenAPIVersion) ([]byte, error) {
+	doc, err := openAPIComponents(schemas, version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// MarshalOpenAPIComponentsYAML is like MarshalOpenAPIComponents but writes YAML.
+func MarshalOpenAPIComponentsYAML(schemas map[string]JSONSchema, version OpenAPIVersion) ([]byte, error) {
+	var buf bytes.Buffer
+
+	doc, err := openAPIComponents(schemas, version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err = enc.Encode(toYAMLNode(doc)); err == nil {
+		err = enc.Close()
+	}
+
+	return buf.Bytes(), err
+}
+
+func openAPIComponents(schemas map[string]JSONSchema, version OpenAPIVersion) (*orderedObject, error) {
+	var names []string
+
+	for name := range schemas {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	refNames := componentNames(names)
+	components := newOrderedObject()
+
+	for _, key := range names {
+		tree, err := orderedTree(schemas[key])
+
+		if err != nil {
+			return nil, err
+		}
+
+		tree.remove("$schema")
+
+		name := refNames[key]
+
+		if version == OpenAPIVersion31 {
+			components.set(name, convertOpenAPI31(convertSchemaNode(tree, SpecVersionDraft202012, true), name, refNames))
+			continue
+		}
+
+		components.set(name, convertOpenAPI30(convertSchemaNode(tree, SpecVersionDraftV4, true), name, refNames))
+	}
+
+	// the sanitised names can sort differently than the keys
+	sort.Strings(components.keys)
+
+	return newOrderedObject().set("components", newOrderedObject().set("schemas", components)), nil
+}
+
+// componentNames maps each of the sorted keys to a valid component name. Keys that are already
+// valid keep their name and a number is added to sanitised names that would clash with another.
+func componentNames(keys []string) map[string]string {
+	names := make(map[string]string, len(keys))
+	taken := make(map[string]bool, len(keys))
+
+	for _, key := range keys {
+		if !invalidComponentChars.MatchString(key) {
+			names[key] = key
+			taken[key] = true
+		}
+	}
+
+	for _, key := range keys {
+		if _, found := names[key]; found {
+			continue
+		}
+
+		base := componentName(key)
+		name := base
+
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%s%d", base, i)
+		}
+
+		names[key] = name
+		taken[name] = true
+	}
+
+	return names
+}
+
+// componentName turns the type arguments in a key into dot separated parts, e.g.
+// Paged[*github_com-example-User] becomes Paged.ptr.github_com-example-User. Definition keys have
+// no dots of their own so this can't clash with the key of another type. Any other invalid
+// characters are replaced with underscores.
+func componentName(key string) string {
+	name := componentNameReplacer.Replace(key)
+	name = invalidComponentChars.ReplaceAllString(name, "_")
+	name = strings.Trim(repeatedDots.ReplaceAllString(name, "."), "._")
+
+	if name == "" {
+		return "_"
+	}
+
+	return name
+}
+
+// convertOpenAPIRefs points refs at the components and drops definitions since they're passed as
+// components of their own.
+func convertOpenAPIRefs(s *orderedObject, name string, refNames map[string]string) {
+	s.remove("definitions")
+	s.remove("$defs")
+
+	ref, ok := s.get("$ref").(string)
+	if !ok {
+		return
+	}
+
+	key := ""
+
+	switch {
+	case ref == "#":
+		s.values["$ref"] = ComponentsRoot + name
+		return
+	case strings.HasPrefix(ref, DefinitionRoot):
+		key = strings.TrimPrefix(ref, DefinitionRoot)
+	case strings.HasPrefix(ref, DefsRoot):
+		key = strings.TrimPrefix(ref, DefsRoot)
+	default:
+		return
+	}
+
+	if refName, found := refNames[key]; found {
+		s.values["$ref"] = ComponentsRoot + refName
+		return
+	}
+
+	s.values["$ref"] = ComponentsRoot + componentName(key)
+}
+
+// convertOpenAPI30 converts a draft-04 schema to an OpenAPI 3.0 schema object. Type arrays and
+// anyOf with a null schema become nullable, other type arrays become an anyOf and unsupported
+// keywords are dropped.
+func convertOpenAPI30(node interface{}, name string, refNames map[string]string) interface{} {
+	s, ok := node.(*orderedObject)
+	if !ok {
+		return node
+	}
+
+	convertOpenAPIRefs(s, name, refNames)
+
+	walkSubschemas(s, func(keyword string, sub interface{}) interface{} {
+		return convertOpenAPI30(sub, name, refNames)
+	})
+
+	if types, ok := s.get("type").([]interface{}); ok {
+		var nonNull []interface{}
+
+		for _, t := range types {
+			if t == SchemaTypeNull {
+				s.set("nullable", true)
+			} else {
+				nonNull = append(nonNull, t)
+			}
+		}
+
+		switch len(nonNull) {
+		case 0:
+			s.remove("type")
+		case 1:
+			s.values["type"] = nonNull[0]
+		default:
+			var anyOf []interface{}
+			for _, t := range nonNull {
+				anyOf = append(anyOf, newOrderedObject().set("type", t))
+			}
+
+			s.rename("type", "anyOf")
+			s.values["anyOf"] = anyOf
+		}
+	}
+
+	if anyOf, ok := s.get("anyOf").([]interface{}); ok {
+		var nonNull []interface{}
+
+		for _, sub := range anyOf {
+			if subObj, isObj := sub.(*orderedObject); isObj && len(subObj.keys) == 1 && subObj.get("type") == SchemaTypeNull {
+				s.set("nullable", true)
+			} else {
+				nonNull = append(nonNull, sub)
+			}
+		}
+
+		// refs can't have siblings so a single nullable ref is wrapped in an allOf
+		key := "anyOf"
+		if len(nonNull) == 1 && len(nonNull) < len(anyOf) {
+			s.rename("anyOf", "allOf")
+			key = "allOf"
+		}
+
+		s.values[key] = nonNull
+	}
+
+	for _, key := range append([]string{}, s.keys...) {
+		if !openAPI30Keywords[key] && !strings.HasPrefix(key, "x-") {
+			s.remove(key)
+		}
+	}
+
+	return s
+}
+
+// convertOpenAPI31 converts a 2020-12 schema to an OpenAPI 3.1 schema object. The only difference
+// is that 3.1 dropped the nullable keyword in favour of null types.
+func convertOpenAPI31(node interface{}, name string, refNames map[string]string) interface{} {
+	s, ok := node.(*orderedObject)
+	if !ok {
+		return node
+	}
+
+	convertOpenAPIRefs(s, name, refNames)
+
+	walkSubschemas(s, func(keyword string, sub interface{}) interface{} {
+		return convertOpenAPI31(sub, name, refNames)
+	})
+
+	if nullable, _ := s.get("nullable").(bool); !nullable {
+		s.remove("nullable")
+		return s
+	}
+
+	s.remove("nullable")
+
+	if enum, ok := s.get("enum").([]interface{}); ok && !containsValue(enum, nil) {
+		s.values["enum"] = append(enum, nil)
+	}
+
+	switch t := s.get("type").(type) {
+	case string:
+		s.values["type"] = []interface{}{t, SchemaTypeNull}
+	case []interface{}:
+		if !containsValue(t, SchemaTypeNull) {
+			s.values["type"] = append(t, SchemaTypeNull)
+		}
+	default:
+		// wrap anything without a type, e.g. an allOf around a ref
+		if allOf, ok := s.get("allOf").([]interface{}); ok && len(allOf) == 1 {
+			s.rename("allOf", "anyOf")
+			s.values["anyOf"] = append(allOf, newOrderedObject().set("type", SchemaTypeNull))
+		} else {
+			wrapped := &orderedObject{keys: s.keys, values: s.values}
+			return newOrderedObject().set("anyOf", []interface{}{wrapped, newOrderedObject().set("type", SchemaTypeNull)})
+		}
+	}
+
+	return s
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toYAMLNode converts a decoded JSON tree to a yaml node, keeping the order of keys.
+func toYAMLNode(value interface{}) *yaml.Node {
+	switch typed := value.(type) {
+	case *orderedObject:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range typed.keys {
+			node.Content = append(node.Content, toYAMLNode(key), toYAMLNode(typed.values[key]))
+		}
+		return node
+
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range typed {
+			node.Content = append(node.Content, toYAMLNode(item))
+		}
+		return node
+
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: typed}
+
+	case json.Number:
+		if _, err := typed.Int64(); err == nil {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: typed.String()}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: typed.String()}
+
+	case bool:
+		if typed {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"}
+	}
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
@@ -50,25 +50,37 @@ func (s *defaultArraySchema) UnmarshalJSON(b []byte) error {
 
 	if err == nil {
 		for k, v := range stuff {
+			var ok bool
+
 			switch k {
 			case "items":
-				mb, xerr := json.Marshal(v.(interface{}))
-				if xerr != nil {
-					return xerr
-				}
-				ms, xerr := FromJSON(mb)
-				if xerr != nil {
-					return xerr
+				// draft-04 tuples have an array of items, those are kept as extensions
+				var items JSONSchema
+				if items, ok, err = subschema(v); ok {
+					s.Items = items
 				}
-				s.Items = ms
 			case "maxItems":
-				s.MaxItems = int64(v.(float64))
+				var n float64
+				if n, ok = v.(float64); ok {
+					s.MaxItems = int64(n)
+				}
 			case "minItems":
-				s.MinItems = int64(v.(float64))
+				var n float64
+				if n, ok = v.(float64); ok {
+					s.MinItems = int64(n)
+				}
 			case "additionalItems":
-				s.AdditionalItems = v.(bool)
+				s.AdditionalItems, ok = v.(bool)
 			case "uniqueItems":
-				s.UniqueItems = v.(bool)
+				s.UniqueItems, ok = v.(bool)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				s.claimKeyword(k)
 			}
 		}
 	}
@@ -76,6 +88,11 @@ func (s *defaultArraySchema) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+// MarshalJSON converts this schema to JSON including its extensions
+func (s *defaultArraySchema) MarshalJSON() ([]byte, error) {
+	return marshalSchema(s)
+}
+
 func (s *defaultArraySchema) Clone() JSONSchema {
 	s2 := &defaultArraySchema{}
 	*s2 = *s
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"encoding/json"
+)
+
+// BooleanSchema is the draft-06 and newer boolean schema. true allows any value and false allows
+// none. A false schema has an empty not schema so that code which only looks at the keywords of a
+// schema still treats it as allowing nothing.
+type BooleanSchema interface {
+	JSONSchema
+	GetBoolean() bool
+}
+
+type defaultBooleanSchema struct {
+	*basicSchema
+	Boolean bool `json:"-"`
+}
+
+// NewBooleanSchema creates the boolean schema true or false.
+func NewBooleanSchema(b bool) BooleanSchema {
+	s := &defaultBooleanSchema{
+		basicSchema: NewBasicSchema("").(*basicSchema),
+		Boolean:     b,
+	}
+
+	if !b {
+		s.Not = NewBasicSchema("")
+	}
+
+	return s
+}
+
+// MarshalJSON converts this schema to JSON, which is always just true or false
+func (s *defaultBooleanSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Boolean)
+}
+
+func (s *defaultBooleanSchema) Clone() JSONSchema {
+	return NewBooleanSchema(s.Boolean)
+}
+
+func (s *defaultBooleanSchema) GetBoolean() bool {
+	return s.Boolean
+}
+
+// isFalseSchema returns true for the boolean schema false.
+func isFalseSchema(s JSONSchema) bool {
+	b, ok := s.(BooleanSchema)
+	return ok && !b.GetBoolean()
+}
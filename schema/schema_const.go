@@ -12,8 +12,38 @@ const (
 	SpecVersionDraftV4 = "http://json-schema.org/draft-04/schema#"
 	// SpecVersionDraftV4Hyper is the draft-04 hyper spec
 	SpecVersionDraftV4Hyper = "http://json-schema.org/draft-04/hyper-schema#"
+	// SpecVersionDraft06 is the draft-06 spec
+	SpecVersionDraft06 SpecVersion = "http://json-schema.org/draft-06/schema#"
+	// SpecVersionDraft07 is the draft-07 spec
+	SpecVersionDraft07 SpecVersion = "http://json-schema.org/draft-07/schema#"
+	// SpecVersionDraft201909 is the draft 2019-09 spec
+	SpecVersionDraft201909 SpecVersion = "https://json-schema.org/draft/2019-09/schema"
+	// SpecVersionDraft202012 is the draft 2020-12 spec
+	SpecVersionDraft202012 SpecVersion = "https://json-schema.org/draft/2020-12/schema"
 )
 
+var specVersionOrder = map[SpecVersion]int{
+	SpecVersionDraftV4:      4,
+	SpecVersionDraftV4Hyper: 4,
+	SpecVersionDraft06:      6,
+	SpecVersionDraft07:      7,
+	SpecVersionDraft201909:  201909,
+	SpecVersionDraft202012:  202012,
+	SpecVersionCurrent:      202012,
+	SpecVersionCurrentHyper: 202012,
+}
+
+// AtLeast returns true if this spec version is the same as or newer than the other version.
+// Unknown versions are treated as draft-04.
+func (v SpecVersion) AtLeast(other SpecVersion) bool {
+	mine, found := specVersionOrder[v]
+	if !found {
+		mine = specVersionOrder[SpecVersionDraftV4]
+	}
+
+	return mine >= specVersionOrder[other]
+}
+
 const (
 	// SchemaTypeObject is the object type
 	SchemaTypeObject string = "object"
@@ -27,4 +57,6 @@ const (
 	SchemaTypeString = "string"
 	// SchemaTypeArray is the array type
 	SchemaTypeArray = "array"
+	// SchemaTypeNull is the null type
+	SchemaTypeNull = "null"
 )
@@ -52,17 +52,24 @@ func (s *defaultNumericSchema) UnmarshalJSON(b []byte) error {
 
 	if err == nil {
 		for k, v := range stuff {
+			var ok bool
+
 			switch k {
 			case "maximum":
-				s.Maximum = v.(float64)
+				s.Maximum, ok = v.(float64)
 			case "minimum":
-				s.Minimum = v.(float64)
+				s.Minimum, ok = v.(float64)
 			case "multipleOf":
-				s.MultipleOf = v.(float64)
+				s.MultipleOf, ok = v.(float64)
+			// draft-06 and newer use numeric exclusive limits, those are kept as extensions
 			case "exclusiveMaximum":
-				s.ExclusiveMaximum = v.(bool)
+				s.ExclusiveMaximum, ok = v.(bool)
 			case "exclusiveMinimum":
-				s.ExclusiveMinimum = v.(bool)
+				s.ExclusiveMinimum, ok = v.(bool)
+			}
+
+			if ok {
+				s.claimKeyword(k)
 			}
 		}
 	}
@@ -70,6 +77,11 @@ func (s *defaultNumericSchema) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+// MarshalJSON converts this schema to JSON including its extensions
+func (s *defaultNumericSchema) MarshalJSON() ([]byte, error) {
+	return marshalSchema(s)
+}
+
 func (s *defaultNumericSchema) Clone() JSONSchema {
 	s2 := &defaultNumericSchema{}
 	*s2 = *s
@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"encoding/json"
+)
+
+// NumericStringSchema represents the schema for a number encoded as a JSON string, such as a field
+// with the ",string" json tag option. Numeric constraints can't be validated against a string so
+// they are kept as x- extension keywords.
+type NumericStringSchema interface {
+	StringSchema
+	NumericSchema
+}
+
+type defaultNumericStringSchema struct {
+	*defaultStringSchema
+	Maximum          float64 `json:"x-maximum,omitempty"`
+	Minimum          float64 `json:"x-minimum,omitempty"`
+	MultipleOf       float64 `json:"x-multipleOf,omitempty"`
+	ExclusiveMaximum bool    `json:"x-exclusiveMaximum,omitempty"`
+	ExclusiveMinimum bool    `json:"x-exclusiveMinimum,omitempty"`
+}
+
+// NewNumericStringSchema creates a new numeric string schema.
+func NewNumericStringSchema() NumericStringSchema {
+	return &defaultNumericStringSchema{
+		defaultStringSchema: NewStringSchema().(*defaultStringSchema),
+	}
+}
+
+func (s *defaultNumericStringSchema) UnmarshalJSON(b []byte) error {
+	var err error
+	var stuff map[string]interface{}
+
+	ss := &defaultStringSchema{}
+	err = json.Unmarshal(b, ss)
+
+	if err == nil {
+		s.defaultStringSchema = ss
+		err = json.Unmarshal(b, &stuff)
+	}
+
+	if err == nil {
+		for k, v := range stuff {
+			var ok bool
+
+			switch k {
+			case "x-maximum":
+				s.Maximum, ok = v.(float64)
+			case "x-minimum":
+				s.Minimum, ok = v.(float64)
+			case "x-multipleOf":
+				s.MultipleOf, ok = v.(float64)
+			case "x-exclusiveMaximum":
+				s.ExclusiveMaximum, ok = v.(bool)
+			case "x-exclusiveMinimum":
+				s.ExclusiveMinimum, ok = v.(bool)
+			}
+
+			if ok {
+				s.claimKeyword(k)
+			}
+		}
+	}
+
+	return err
+}
+
+// MarshalJSON converts this schema to JSON including its extensions
+func (s *defaultNumericStringSchema) MarshalJSON() ([]byte, error) {
+	return marshalSchema(s)
+}
+
+func (s *defaultNumericStringSchema) Clone() JSONSchema {
+	s2 := &defaultNumericStringSchema{}
+	*s2 = *s
+
+	s2.defaultStringSchema = s.defaultStringSchema.Clone().(*defaultStringSchema)
+	return s2
+}
+
+func (s *defaultNumericStringSchema) GetMultipleOf() float64 {
+	return s.MultipleOf
+}
+
+func (s *defaultNumericStringSchema) GetMaximum() float64 {
+	return s.Maximum
+}
+
+func (s *defaultNumericStringSchema) GetMinimum() float64 {
+	return s.Minimum
+}
+
+func (s *defaultNumericStringSchema) GetExclusiveMaximum() bool {
+	return s.ExclusiveMaximum
+}
+
+func (s *defaultNumericStringSchema) GetExclusiveMinimum() bool {
+	return s.ExclusiveMinimum
+}
+
+func (s *defaultNumericStringSchema) SetMultipleOf(multipleOf float64) {
+	s.MultipleOf = multipleOf
+}
+
+func (s *defaultNumericStringSchema) SetMaximum(maximum float64) {
+	s.Maximum = maximum
+}
+
+func (s *defaultNumericStringSchema) SetMinimum(minimum float64) {
+	s.Minimum = minimum
+}
+
+func (s *defaultNumericStringSchema) SetExclusiveMaximum(exclusiveMaximum bool) {
+	s.ExclusiveMaximum = exclusiveMaximum
+}
+
+func (s *defaultNumericStringSchema) SetExclusiveMinimum(exclusiveMinimum bool) {
+	s.ExclusiveMinimum = exclusiveMinimum
+}
@@ -1,6 +1,9 @@
 package schema
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"sort"
+)
 
 // BoolOrSchema holds a bool or a JSONSchema for values that can take either.
 // This is used for things like additionalProperties
@@ -44,8 +47,8 @@ func (b *BoolOrSchema) MarshalJSON() ([]byte, error) {
 func (b *BoolOrSchema) UnmarshalJSON(data []byte) error {
 	var bs BoolOrSchema
 	if data[0] == '{' {
-		var s JSONSchema
-		if err := json.Unmarshal(data, &s); err != nil {
+		s, err := FromJSON(data)
+		if err != nil {
 			return err
 		}
 		bs.Schema = s
@@ -55,21 +58,89 @@ func (b *BoolOrSchema) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// StringArrayOrSchema holds a list of property names or a JSONSchema for values that can take either.
+// This is used for the draft-04 dependencies keyword
+type StringArrayOrSchema struct {
+	Array  []string
+	Schema JSONSchema
+}
+
+// NewStringArrayOrSchema creates a *StringArrayOrSchema based on the given interface.
+func NewStringArrayOrSchema(v interface{}) *StringArrayOrSchema {
+	s, ok := v.(JSONSchema)
+	if ok {
+		return &StringArrayOrSchema{
+			Schema: s,
+		}
+	}
+
+	a, ok := v.([]string)
+	if ok {
+		return &StringArrayOrSchema{
+			Array: a,
+		}
+	}
+
+	return &StringArrayOrSchema{}
+}
+
+// MarshalJSON convert this object to JSON
+func (sas *StringArrayOrSchema) MarshalJSON() ([]byte, error) {
+	if sas.Schema != nil {
+		return json.Marshal(sas.Schema)
+	}
+
+	if sas.Array == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal(sas.Array)
+}
+
+// UnmarshalJSON converts this string array or schema object from a JSON structure
+func (sas *StringArrayOrSchema) UnmarshalJSON(data []byte) error {
+	var sa StringArrayOrSchema
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &sa.Array); err != nil {
+			return err
+		}
+	} else {
+		s, err := FromJSON(data)
+		if err != nil {
+			return err
+		}
+		sa.Schema = s
+	}
+
+	*sas = sa
+	return nil
+}
+
 // ObjectSchema represents a JSON object schema.
 type ObjectSchema interface {
 	JSONSchema
 	GetProperties() map[string]JSONSchema
 	SetProperties(props map[string]JSONSchema)
+	GetPropertyOrder() []string
+	SetPropertyOrder(names []string)
 	GetRequired() []string
 	GetMaxProperties() int64
 	GetMinProperties() int64
 	GetAdditionalProperties() *BoolOrSchema
-	//GetPatternProperties() map[string]JSONSchema
-	//GetDependencies() map[string]StringArrayOrObject
+	GetPropertyNames() JSONSchema
+	GetPatternProperties() map[string]JSONSchema
+	GetDependencies() map[string]*StringArrayOrSchema
+	GetDependentRequired() map[string][]string
+	GetDependentSchemas() map[string]JSONSchema
 
 	SetMaxProperties(maxProperties int64)
 	SetMinProperties(minProperties int64)
 	SetAdditionalProperties(additionalProperties *BoolOrSchema)
+	SetPropertyNames(propertyNames JSONSchema)
+	SetPatternProperties(patternProperties map[string]JSONSchema)
+	SetDependencies(dependencies map[string]*StringArrayOrSchema)
+	SetDependentRequired(dependentRequired map[string][]string)
+	SetDependentSchemas(dependentSchemas map[string]JSONSchema)
 	AddRequiredField(fieldName string)
 
 	SetGoPath(string)
@@ -78,15 +149,19 @@ type ObjectSchema interface {
 
 type defaultObjectSchema struct {
 	*basicSchema
-	Properties           map[string]JSONSchema `json:"properties,omitempty"`
-	Required             []string              `json:"required,omitempty"`
-	MaxProperties        int64                 `json:"maxProperties,omitempty"`
-	MinProperties        int64                 `json:"minProperties,omitempty"`
-	AdditionalProperties *BoolOrSchema         `json:"additionalProperties,omitempty"`
-	GoPath               string                `json:"x-go-path,omitempty"`
+	Properties           map[string]JSONSchema           `json:"properties,omitempty"`
+	Required             []string                        `json:"required,omitempty"`
+	MaxProperties        int64                           `json:"maxProperties,omitempty"`
+	MinProperties        int64                           `json:"minProperties,omitempty"`
+	AdditionalProperties *BoolOrSchema                   `json:"additionalProperties,omitempty"`
+	PropertyNames        JSONSchema                      `json:"propertyNames,omitempty"`
+	PatternProperties    map[string]JSONSchema           `json:"patternProperties,omitempty"`
+	Dependencies         map[string]*StringArrayOrSchema `json:"dependencies,omitempty"`
+	DependentRequired    map[string][]string             `json:"dependentRequired,omitempty"`
+	DependentSchemas     map[string]JSONSchema           `json:"dependentSchemas,omitempty"`
+	GoPath               string                          `json:"x-go-path,omitempty"`
+	propertyOrder        []string
 	suppressXAttrs       bool
-	//PatternProperties    map[string]JSONSchema `json:"patternProperties,omitempty"`
-	//Dependencies         map[string]StringArrayOrObject `json:"dependencies,omitempty"`
 }
 
 // NewObjectSchema creates a new object schema
@@ -96,8 +171,6 @@ func NewObjectSchema(suppressXAttrs bool) ObjectSchema {
 		Properties:     make(map[string]JSONSchema),
 		Required:       make([]string, 0),
 		suppressXAttrs: suppressXAttrs,
-		//PatternProperties: make(map[string]JSONSchema),
-		//		Dependencies:      make(map[string]StringArrayOrObject),
 	}
 }
 
@@ -108,41 +181,93 @@ func (s *defaultObjectSchema) UnmarshalJSON(b []byte) error {
 	bs := &basicSchema{}
 	err = json.Unmarshal(b, bs)
 
-	if err == nil {
-		s.basicSchema = bs
-
-		s.Properties = make(map[string]JSONSchema)
-		s.Required = make([]string, 0)
+	if err != nil {
+		return err
 	}
 
+	s.basicSchema = bs
+
+	s.Properties = make(map[string]JSONSchema)
+	s.Required = make([]string, 0)
+	s.PatternProperties = make(map[string]JSONSchema)
+
 	err = json.Unmarshal(b, &stuff)
 
+	if err == nil {
+		s.propertyOrder, err = jsonKeyOrder(b, "properties")
+	}
+
 	if err == nil {
 		for k, v := range stuff {
+			var ok bool
+
 			switch k {
 			case "maxProperties":
-				s.MaxProperties = int64(v.(float64))
+				var n float64
+				if n, ok = v.(float64); ok {
+					s.MaxProperties = int64(n)
+				}
 			case "minProperties":
-				s.MinProperties = int64(v.(float64))
+				var n float64
+				if n, ok = v.(float64); ok {
+					s.MinProperties = int64(n)
+				}
 			case "additionalProperties":
-				s.AdditionalProperties = NewBoolOrSchema(v)
-			case "properties":
-				for mk, mv := range v.(map[string]interface{}) {
-					mb, xerr := json.Marshal(mv)
-					if xerr != nil {
-						return xerr
+				switch ap := v.(type) {
+				case bool:
+					s.AdditionalProperties, ok = NewBoolOrSchema(ap), true
+				case map[string]interface{}:
+					var ms JSONSchema
+					if ms, ok, err = subschema(ap); ok {
+						s.AdditionalProperties = &BoolOrSchema{Boolean: true, Schema: ms}
 					}
-					ms, xerr := FromJSON(mb)
-					if xerr != nil {
-						return xerr
-					}
-					s.Properties[mk] = ms
 				}
-
+			case "propertyNames":
+				var ms JSONSchema
+				if ms, ok, err = subschema(v); ok {
+					s.PropertyNames = ms
+				}
+			case "properties":
+				var props map[string]JSONSchema
+				if props, ok, err = subschemaMap(v); ok {
+					s.Properties = props
+				}
+			case "patternProperties":
+				var props map[string]JSONSchema
+				if props, ok, err = subschemaMap(v); ok {
+					s.PatternProperties = props
+				}
+			case "dependencies":
+				var deps map[string]*StringArrayOrSchema
+				if deps, ok, err = dependencyMap(v); ok {
+					s.Dependencies = deps
+				}
+			case "dependentRequired":
+				var deps map[string][]string
+				if deps, ok = stringListMap(v); ok {
+					s.DependentRequired = deps
+				}
+			case "dependentSchemas":
+				var deps map[string]JSONSchema
+				if deps, ok, err = subschemaMap(v); ok {
+					s.DependentSchemas = deps
+				}
 			case "required":
-				for _, rs := range v.([]interface{}) {
-					s.Required = append(s.Required, rs.(string))
+				var required []string
+				if required, ok = stringList(v); ok {
+					s.Required = required
 				}
+			case "x-go-path":
+				s.GoPath, ok = v.(string)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			// keywords with an unexpected shape stay extensions
+			if ok {
+				s.claimKeyword(k)
 			}
 		}
 	}
@@ -151,6 +276,71 @@ func (s *defaultObjectSchema) UnmarshalJSON(b []byte) error {
 
 }
 
+// dependencyMap parses the decoded draft-04 dependencies keyword. ok is false if a dependency isn't
+// a list of property names or a schema.
+func dependencyMap(v interface{}) (map[string]*StringArrayOrSchema, bool, error) {
+	values, isMap := v.(map[string]interface{})
+	if !isMap {
+		return nil, false, nil
+	}
+
+	deps := make(map[string]*StringArrayOrSchema, len(values))
+
+	for key, value := range values {
+		if names, isList := stringList(value); isList {
+			deps[key] = NewStringArrayOrSchema(names)
+			continue
+		}
+
+		ds, ok, err := subschema(value)
+		if !ok || err != nil {
+			return nil, false, err
+		}
+
+		deps[key] = NewStringArrayOrSchema(ds)
+	}
+
+	return deps, true, nil
+}
+
+// stringListMap returns a decoded JSON object whose values are arrays of strings. ok is false if
+// any of the values isn't.
+func stringListMap(v interface{}) (map[string][]string, bool) {
+	values, isMap := v.(map[string]interface{})
+	if !isMap {
+		return nil, false
+	}
+
+	lists := make(map[string][]string, len(values))
+
+	for key, value := range values {
+		list, ok := stringList(value)
+		if !ok {
+			return nil, false
+		}
+
+		lists[key] = list
+	}
+
+	return lists, true
+}
+
+// MarshalJSON converts this schema to JSON including its extensions. Properties are written in
+// property order.
+func (s *defaultObjectSchema) MarshalJSON() ([]byte, error) {
+	obj := schemaObject(s)
+
+	if obj.has("properties") {
+		props := newOrderedObject()
+		for _, name := range s.GetPropertyOrder() {
+			props.set(name, s.Properties[name])
+		}
+		obj.set("properties", props)
+	}
+
+	return obj.MarshalJSON()
+}
+
 func (s *defaultObjectSchema) Clone() JSONSchema {
 	s2 := &defaultObjectSchema{}
 	*s2 = *s
@@ -167,6 +357,35 @@ func (s *defaultObjectSchema) SetProperties(props map[string]JSONSchema) {
 	s.Properties = props
 }
 
+// GetPropertyOrder returns the names of the properties in the order they were declared. Properties
+// that were added without an order come last, sorted by name.
+func (s *defaultObjectSchema) GetPropertyOrder() []string {
+	names := make([]string, 0, len(s.Properties))
+	seen := make(map[string]bool, len(s.Properties))
+
+	for _, name := range s.propertyOrder {
+		if _, found := s.Properties[name]; found && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+
+	var rest []string
+	for name := range s.Properties {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(names, rest...)
+}
+
+// SetPropertyOrder sets the order the properties are marshalled in.
+func (s *defaultObjectSchema) SetPropertyOrder(names []string) {
+	s.propertyOrder = names
+}
+
 func (s *defaultObjectSchema) GetRequired() []string {
 	return s.Required
 }
@@ -187,13 +406,25 @@ func (s *defaultObjectSchema) GetAdditionalProperties() *BoolOrSchema {
 	return s.AdditionalProperties
 }
 
-//func (s *defaultObjectSchema) GetPatternProperties() map[string]JSONSchema {
-//	return s.PatternProperties
-//}
+func (s *defaultObjectSchema) GetPropertyNames() JSONSchema {
+	return s.PropertyNames
+}
 
-//func (s *DefaultObjectSchema) GetDependencies() map[string]StringArrayOrObject {
-//	return s.Dependencies
-//}
+func (s *defaultObjectSchema) GetPatternProperties() map[string]JSONSchema {
+	return s.PatternProperties
+}
+
+func (s *defaultObjectSchema) GetDependencies() map[string]*StringArrayOrSchema {
+	return s.Dependencies
+}
+
+func (s *defaultObjectSchema) GetDependentRequired() map[string][]string {
+	return s.DependentRequired
+}
+
+func (s *defaultObjectSchema) GetDependentSchemas() map[string]JSONSchema {
+	return s.DependentSchemas
+}
 
 func (s *defaultObjectSchema) SetMaxProperties(maxProperties int64) {
 	s.MaxProperties = maxProperties
@@ -207,6 +438,26 @@ func (s *defaultObjectSchema) SetAdditionalProperties(additionalProperties *Bool
 	s.AdditionalProperties = additionalProperties
 }
 
+func (s *defaultObjectSchema) SetPropertyNames(propertyNames JSONSchema) {
+	s.PropertyNames = propertyNames
+}
+
+func (s *defaultObjectSchema) SetPatternProperties(patternProperties map[string]JSONSchema) {
+	s.PatternProperties = patternProperties
+}
+
+func (s *defaultObjectSchema) SetDependencies(dependencies map[string]*StringArrayOrSchema) {
+	s.Dependencies = dependencies
+}
+
+func (s *defaultObjectSchema) SetDependentRequired(dependentRequired map[string][]string) {
+	s.DependentRequired = dependentRequired
+}
+
+func (s *defaultObjectSchema) SetDependentSchemas(dependentSchemas map[string]JSONSchema) {
+	s.DependentSchemas = dependentSchemas
+}
+
 func (s *defaultObjectSchema) SetGoPath(path string) {
 	if s.suppressXAttrs {
 		s.GoPath = ""
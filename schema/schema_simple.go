@@ -30,17 +30,19 @@ func (s *defaultSimpleSchema) UnmarshalJSON(b []byte) error {
 	bs := &basicSchema{}
 	err = json.Unmarshal(b, bs)
 
-	if err == nil {
-		s.basicSchema = bs
+	if err != nil {
+		return err
 	}
 
+	s.basicSchema = bs
+
 	err = json.Unmarshal(b, &stuff)
 
 	if err == nil {
 		for k, v := range stuff {
-			switch k {
-			case "format":
-				s.Format = v.(string)
+			if format, ok := v.(string); ok && k == "format" {
+				s.Format = format
+				s.claimKeyword(k)
 			}
 		}
 	}
@@ -48,6 +50,11 @@ func (s *defaultSimpleSchema) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+// MarshalJSON converts this schema to JSON including its extensions
+func (s *defaultSimpleSchema) MarshalJSON() ([]byte, error) {
+	return marshalSchema(s)
+}
+
 func (s *defaultSimpleSchema) Clone() JSONSchema {
 	s2 := &defaultSimpleSchema{}
 	*s2 = *s
@@ -47,13 +47,24 @@ func (s *defaultStringSchema) UnmarshalJSON(b []byte) error {
 
 	if err == nil {
 		for k, v := range stuff {
+			var ok bool
+			var n float64
+
 			switch k {
 			case "pattern":
-				s.Pattern = v.(string)
+				s.Pattern, ok = v.(string)
 			case "maxLength":
-				s.MaxLength = int64(v.(float64))
+				if n, ok = v.(float64); ok {
+					s.MaxLength = int64(n)
+				}
 			case "minLength":
-				s.MinLength = int64(v.(float64))
+				if n, ok = v.(float64); ok {
+					s.MinLength = int64(n)
+				}
+			}
+
+			if ok {
+				s.claimKeyword(k)
 			}
 		}
 	}
@@ -61,6 +72,11 @@ func (s *defaultStringSchema) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+// MarshalJSON converts this schema to JSON including its extensions
+func (s *defaultStringSchema) MarshalJSON() ([]byte, error) {
+	return marshalSchema(s)
+}
+
 func (s *defaultStringSchema) Clone() JSONSchema {
 	s2 := &defaultStringSchema{}
 	*s2 = *s
@@ -0,0 +1,554 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefsRoot is the root of the definition ref for draft 2019-09 and newer
+	DefsRoot = "#/$defs/"
+)
+
+// schemaMapKeywords hold objects whose values are all schemas
+var schemaMapKeywords = []string{"properties", "patternProperties", "definitions", "$defs", "dependentSchemas"}
+
+// schemaKeywords hold a single schema
+var schemaKeywords = []string{"additionalProperties", "additionalItems", "not", "if", "then", "else", "contains",
+	"propertyNames", "unevaluatedItems", "unevaluatedProperties"}
+
+// schemaArrayKeywords hold arrays of schemas
+var schemaArrayKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+
+// MarshalForVersion marshals the schema tree to JSON for the given spec version. The tree is built
+// using draft-04 keywords, so keywords that changed between drafts are rewritten, e.g. id becomes
+// $id, boolean exclusiveMaximum becomes numeric, definitions become $defs, const becomes a single
+// value enum for draft-04 and empty sub-schemas become the boolean schema true for draft-06 and
+// newer. The $schema of the root is set to the version.
+func MarshalForVersion(s JSONSchema, version SpecVersion) ([]byte, error) {
+	converted, err := convertForVersion(s, version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(converted)
+}
+
+// MarshalIndentForVersion is like MarshalForVersion but applies indent to format the output.
+func MarshalIndentForVersion(s JSONSchema, version SpecVersion, prefix, indent string) ([]byte, error) {
+	converted, err := convertForVersion(s, version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(converted, prefix, indent)
+}
+
+func convertForVersion(s JSONSchema, version SpecVersion) (interface{}, error) {
+	root, err := orderedTree(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	root.setFirst("$schema", string(version))
+
+	return convertSchemaNode(root, version, true), nil
+}
+
+// orderedTree marshals the schema and decodes it again keeping the order of keys.
+func orderedTree(s JSONSchema) (*orderedObject, error) {
+	raw, err := json.Marshal(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	tree, err := decodeOrdered(dec)
+
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := tree.(*orderedObject)
+	if !ok {
+		return nil, fmt.Errorf("schema must be a JSON object")
+	}
+
+	return root, nil
+}
+
+// convertSchemaNode rewrites a schema and all of its sub-schemas for the version.
+func convertSchemaNode(node interface{}, version SpecVersion, isRoot bool) interface{} {
+	draft06 := version.AtLeast(SpecVersionDraft06)
+
+	switch typed := node.(type) {
+	case bool:
+		if draft06 {
+			return typed
+		}
+
+		// draft-04 has no boolean schemas
+		if typed {
+			return newOrderedObject()
+		}
+
+		return newOrderedObject().set("not", newOrderedObject())
+
+	case *orderedObject:
+		convertIDs(typed, version)
+		convertExclusiveLimits(typed, version)
+		convertDefinitions(typed, version)
+		convertConst(typed, version)
+		convertDependencies(typed, version)
+		convertItems(typed, version)
+		convertPropertyNames(typed, version)
+
+		walkSubschemas(typed, func(keyword string, sub interface{}) interface{} {
+			// draft-04 already allows booleans for these
+			if _, isBool := sub.(bool); isBool && (keyword == "additionalProperties" || keyword == "additionalItems") {
+				return sub
+			}
+
+			return convertSchemaNode(sub, version, false)
+		})
+
+		if draft06 && !isRoot && len(typed.keys) == 0 {
+			return true
+		}
+	}
+
+	return node
+}
+
+// walkSubschemas calls fn with each direct sub-schema of the schema and the keyword it's under,
+// replacing the sub-schema with the result.
+func walkSubschemas(s *orderedObject, fn func(keyword string, sub interface{}) interface{}) {
+	for _, keyword := range schemaMapKeywords {
+		if schemas, ok := s.get(keyword).(*orderedObject); ok {
+			for _, key := range schemas.keys {
+				schemas.values[key] = fn(keyword, schemas.values[key])
+			}
+		}
+	}
+
+	// only the schema values of dependencies are schemas, the rest are property lists
+	if deps, ok := s.get("dependencies").(*orderedObject); ok {
+		for _, key := range deps.keys {
+			if _, isList := deps.values[key].([]interface{}); !isList {
+				deps.values[key] = fn("dependencies", deps.values[key])
+			}
+		}
+	}
+
+	for _, keyword := range schemaKeywords {
+		if s.has(keyword) {
+			s.values[keyword] = fn(keyword, s.values[keyword])
+		}
+	}
+
+	arrayKeywords := schemaArrayKeywords
+	if _, isList := s.get("items").([]interface{}); isList {
+		arrayKeywords = append(arrayKeywords, "items")
+	} else if s.has("items") {
+		s.values["items"] = fn("items", s.values["items"])
+	}
+
+	for _, keyword := range arrayKeywords {
+		if schemas, ok := s.get(keyword).([]interface{}); ok {
+			for i := range schemas {
+				schemas[i] = fn(keyword, schemas[i])
+			}
+		}
+	}
+}
+
+func convertIDs(s *orderedObject, version SpecVersion) {
+	if !version.AtLeast(SpecVersionDraft06) {
+		s.rename("$id", "id")
+
+		if anchor, ok := s.get("$anchor").(string); ok {
+			s.rename("$anchor", "id")
+			if !strings.HasPrefix(anchor, "#") {
+				s.values["id"] = "#" + anchor
+			}
+		}
+
+		return
+	}
+
+	s.rename("id", "$id")
+
+	// plain name fragments moved to $anchor in 2019-09
+	if id, ok := s.get("$id").(string); ok && strings.HasPrefix(id, "#") && version.AtLeast(SpecVersionDraft201909) {
+		s.rename("$id", "$anchor")
+		s.values["$anchor"] = strings.TrimPrefix(id, "#")
+	}
+}
+
+// convertExclusiveLimits switches between the draft-04 boolean exclusiveMaximum/exclusiveMinimum
+// modifiers and the numeric keywords used by draft-06 and newer.
+func convertExclusiveLimits(s *orderedObject, version SpecVersion) {
+	for _, limit := range [][2]string{{"maximum", "exclusiveMaximum"}, {"minimum", "exclusiveMinimum"}} {
+		inclusive, exclusive := limit[0], limit[1]
+
+		if !s.has(exclusive) {
+			continue
+		}
+
+		if version.AtLeast(SpecVersionDraft06) {
+			if isExclusive, ok := s.get(exclusive).(bool); ok {
+				if isExclusive && s.has(inclusive) {
+					s.values[exclusive] = s.values[inclusive]
+				} else {
+					s.remove(exclusive)
+				}
+
+				s.remove(inclusive)
+			}
+
+			continue
+		}
+
+		if _, ok := s.get(exclusive).(bool); !ok {
+			value := s.values[exclusive]
+			s.rename(exclusive, inclusive)
+			s.values[inclusive] = value
+			s.set(exclusive, true)
+		}
+	}
+}
+
+func convertDefinitions(s *orderedObject, version SpecVersion) {
+	oldRoot, newRoot := DefsRoot, DefinitionRoot
+
+	if version.AtLeast(SpecVersionDraft201909) {
+		s.rename("definitions", "$defs")
+		oldRoot, newRoot = DefinitionRoot, DefsRoot
+	} else {
+		s.rename("$defs", "definitions")
+	}
+
+	if ref, ok := s.get("$ref").(string); ok && strings.HasPrefix(ref, oldRoot) {
+		s.values["$ref"] = newRoot + strings.TrimPrefix(ref, oldRoot)
+	}
+}
+
+func convertConst(s *orderedObject, version SpecVersion) {
+	if version.AtLeast(SpecVersionDraft06) || !s.has("const") {
+		return
+	}
+
+	value := s.values["const"]
+
+	if !s.has("enum") {
+		s.rename("const", "enum")
+		s.values["enum"] = []interface{}{value}
+		return
+	}
+
+	s.remove("const")
+}
+
+// convertPropertyNames drops propertyNames for draft-04, which has no way to constrain property
+// names.
+func convertPropertyNames(s *orderedObject, version SpecVersion) {
+	if version.AtLeast(SpecVersionDraft06) {
+		return
+	}
+
+	s.remove("propertyNames")
+}
+
+// convertDependencies switches between the draft-04 dependencies keyword and the split
+// dependentRequired and dependentSchemas keywords used by 2019-09 and newer.
+func convertDependencies(s *orderedObject, version SpecVersion) {
+	if version.AtLeast(SpecVersionDraft201909) {
+		deps, ok := s.get("dependencies").(*orderedObject)
+		if !ok {
+			return
+		}
+
+		required := newOrderedObject()
+		schemas := newOrderedObject()
+
+		for _, key := range deps.keys {
+			if _, isList := deps.values[key].([]interface{}); isList {
+				required.set(key, deps.values[key])
+			} else {
+				schemas.set(key, deps.values[key])
+			}
+		}
+
+		var keys []string
+		var values []interface{}
+
+		// only add the keywords that have something in them
+		for i, split := range []*orderedObject{required, schemas} {
+			if len(split.keys) > 0 {
+				keys = append(keys, []string{"dependentRequired", "dependentSchemas"}[i])
+				values = append(values, split)
+			}
+		}
+
+		s.replaceWith("dependencies", keys, values)
+		return
+	}
+
+	required, _ := s.get("dependentRequired").(*orderedObject)
+	schemas, _ := s.get("dependentSchemas").(*orderedObject)
+
+	if required == nil && schemas == nil {
+		return
+	}
+
+	deps, _ := s.get("dependencies").(*orderedObject)
+	if deps == nil {
+		deps = newOrderedObject()
+	}
+
+	for _, split := range []*orderedObject{required, schemas} {
+		if split != nil {
+			for _, key := range split.keys {
+				deps.set(key, split.values[key])
+			}
+		}
+	}
+
+	first := "dependentRequired"
+	if required == nil {
+		first = "dependentSchemas"
+	}
+
+	s.remove("dependencies")
+	s.rename(first, "dependencies")
+	s.values["dependencies"] = deps
+	s.remove("dependentRequired")
+	s.remove("dependentSchemas")
+}
+
+// convertItems switches between the tuple form of items with additionalItems and the prefixItems
+// keyword that replaced it in 2020-12.
+func convertItems(s *orderedObject, version SpecVersion) {
+	if version.AtLeast(SpecVersionDraft202012) {
+		tuple, isTuple := s.get("items").([]interface{})
+
+		if !isTuple {
+			// additionalItems only ever applied to tuples
+			s.remove("additionalItems")
+			return
+		}
+
+		additional, hasAdditional := s.values["additionalItems"]
+		s.remove("additionalItems")
+
+		if hasAdditional && additional != true {
+			s.replaceWith("items", []string{"prefixItems", "items"}, []interface{}{tuple, additional})
+			return
+		}
+
+		s.rename("items", "prefixItems")
+		return
+	}
+
+	if tuple, ok := s.get("prefixItems").([]interface{}); ok {
+		additional, hasAdditional := s.values["items"]
+		s.remove("items")
+		s.rename("prefixItems", "items")
+		s.values["items"] = tuple
+
+		if hasAdditional {
+			s.setAfter("items", "additionalItems", additional)
+		}
+	}
+}
+
+// orderedObject is a JSON object that keeps the order of its keys so that rewritten schemas come
+// out the same way they went in.
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedObject() *orderedObject {
+	return &orderedObject{values: make(map[string]interface{})}
+}
+
+func (o *orderedObject) has(key string) bool {
+	_, found := o.values[key]
+	return found
+}
+
+func (o *orderedObject) get(key string) interface{} {
+	return o.values[key]
+}
+
+func (o *orderedObject) set(key string, value interface{}) *orderedObject {
+	if !o.has(key) {
+		o.keys = append(o.keys, key)
+	}
+
+	o.values[key] = value
+
+	return o
+}
+
+func (o *orderedObject) setFirst(key string, value interface{}) {
+	o.remove(key)
+	o.keys = append([]string{key}, o.keys...)
+	o.values[key] = value
+}
+
+func (o *orderedObject) setAfter(after, key string, value interface{}) {
+	o.remove(key)
+
+	for i, k := range o.keys {
+		if k == after {
+			o.keys = append(o.keys[:i+1], append([]string{key}, o.keys[i+1:]...)...)
+			o.values[key] = value
+			return
+		}
+	}
+
+	o.set(key, value)
+}
+
+func (o *orderedObject) remove(key string) {
+	if !o.has(key) {
+		return
+	}
+
+	delete(o.values, key)
+
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// rename changes the name of a key while keeping its position. Any existing key with the new name
+// is replaced.
+func (o *orderedObject) rename(from, to string) {
+	if !o.has(from) || from == to {
+		return
+	}
+
+	o.remove(to)
+
+	for i, k := range o.keys {
+		if k == from {
+			o.keys[i] = to
+		}
+	}
+
+	o.values[to] = o.values[from]
+	delete(o.values, from)
+}
+
+// replaceWith swaps a key for new keys in the same position.
+func (o *orderedObject) replaceWith(key string, keys []string, values []interface{}) {
+	for i, k := range o.keys {
+		if k == key {
+			delete(o.values, key)
+			o.keys = append(o.keys[:i], append(keys, o.keys[i+1:]...)...)
+			break
+		}
+	}
+
+	for i, k := range keys {
+		o.values[k] = values[i]
+	}
+}
+
+// MarshalJSON writes the object with its keys in order
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		keyBytes, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		valueBytes, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(keyBytes)
+		buf.WriteByte(':')
+		buf.Write(valueBytes)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// decodeOrdered decodes the next JSON value, using orderedObject for objects.
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch delim := tok.(type) {
+	case json.Delim:
+		switch delim {
+		case '{':
+			obj := newOrderedObject()
+
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+
+				obj.set(keyTok.(string), value)
+			}
+
+			_, err = dec.Token()
+
+			return obj, err
+
+		case '[':
+			arr := make([]interface{}, 0)
+
+			for dec.More() {
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+
+				arr = append(arr, value)
+			}
+
+			_, err = dec.Token()
+
+			return arr, err
+		}
+	}
+
+	return tok, nil
+}
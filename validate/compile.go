@@ -0,0 +1,527 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// node is a compiled schema. Only the keywords that were present in the schema are set.
+type node struct {
+	// always is set for the boolean schemas true and false
+	always *bool
+
+	ref       string
+	refTarget *node
+
+	types    []string
+	nullable bool
+
+	enum     []interface{}
+	hasEnum  bool
+	constVal interface{}
+	hasConst bool
+
+	allOf []*node
+	anyOf []*node
+	oneOf []*node
+	not   *node
+
+	multipleOf *big.Rat
+	maximum    *big.Rat
+	minimum    *big.Rat
+
+	// draft-04 uses booleans that modify maximum and minimum, newer drafts use numbers
+	exclusiveMaximum      bool
+	exclusiveMinimum      bool
+	exclusiveMaximumValue *big.Rat
+	exclusiveMinimumValue *big.Rat
+
+	maxLength *int
+	minLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	items           *node
+	tupleItems      []*node
+	tupleKeyword    string
+	additionalItems *node
+	additionalKey   string
+	maxItems        *int
+	minItems        *int
+	uniqueItems     bool
+
+	properties           map[string]*node
+	patternProperties    []*patternNode
+	additionalProperties *node
+	required             []string
+	maxProperties        *int
+	minProperties        *int
+	propertyNames        *node
+	dependentRequired    map[string][]string
+	dependentSchemas     map[string]*node
+	dependentRequiredKey string
+	dependentSchemasKey  string
+}
+
+type patternNode struct {
+	source string
+	re     *regexp.Regexp
+	schema *node
+}
+
+// compiler compiles a decoded schema tree. Nodes are cached by their JSON Pointer so refs to the
+// same definition share a node and recursive schemas don't recurse forever.
+type compiler struct {
+	root  interface{}
+	nodes map[string]*node
+	refs  []*node
+}
+
+func (c *compiler) compile(value interface{}, pointer string) (*node, error) {
+	if n, found := c.nodes[pointer]; found {
+		return n, nil
+	}
+
+	n := &node{}
+	c.nodes[pointer] = n
+
+	switch typed := value.(type) {
+	case bool:
+		n.always = &typed
+		return n, nil
+	case map[string]interface{}:
+		return n, c.compileObject(n, typed, pointer)
+	}
+
+	return nil, fmt.Errorf("schema at '%s' must be an object or a boolean", pointer)
+}
+
+func (c *compiler) compileObject(n *node, s map[string]interface{}, pointer string) error {
+	var err error
+
+	// keywords are compiled in a fixed order so the first error is always the same one
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err = c.compileKeyword(n, s, key, pointer); err != nil {
+			return fmt.Errorf("invalid schema keyword at '%s': %s", appendPointer(pointer, key), err)
+		}
+	}
+
+	if n.tupleItems != nil && n.tupleKeyword == "items" {
+		n.additionalItems, err = c.optionalSchema(s, "additionalItems", pointer)
+		n.additionalKey = "additionalItems"
+	}
+
+	return err
+}
+
+func (c *compiler) compileKeyword(n *node, s map[string]interface{}, key string, parent string) error {
+	var err error
+	value := s[key]
+	pointer := appendPointer(parent, key)
+
+	switch key {
+	case "$ref":
+		ref, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		n.ref = ref
+		c.refs = append(c.refs, n)
+
+	case "type":
+		n.types, err = stringList(value)
+
+	case "nullable":
+		n.nullable, _ = value.(bool)
+
+	case "enum":
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("must be an array")
+		}
+		n.enum = list
+		n.hasEnum = true
+
+	case "const":
+		n.constVal = value
+		n.hasConst = true
+
+	case "allOf":
+		n.allOf, err = c.schemaList(value, pointer)
+	case "anyOf":
+		n.anyOf, err = c.schemaList(value, pointer)
+	case "oneOf":
+		n.oneOf, err = c.schemaList(value, pointer)
+	case "not":
+		n.not, err = c.compile(value, pointer)
+
+	case "multipleOf":
+		n.multipleOf, err = toNumber(value)
+		if err == nil && n.multipleOf.Sign() <= 0 {
+			err = fmt.Errorf("must be greater than 0")
+		}
+	case "maximum":
+		n.maximum, err = toNumber(value)
+	case "minimum":
+		n.minimum, err = toNumber(value)
+	case "exclusiveMaximum":
+		n.exclusiveMaximum, n.exclusiveMaximumValue, err = exclusiveLimit(value)
+	case "exclusiveMinimum":
+		n.exclusiveMinimum, n.exclusiveMinimumValue, err = exclusiveLimit(value)
+
+	case "maxLength":
+		n.maxLength, err = toCount(value)
+	case "minLength":
+		n.minLength, err = toCount(value)
+	case "pattern":
+		n.pattern, err = compilePattern(value)
+	case "format":
+		n.format, _ = value.(string)
+
+	case "prefixItems":
+		n.tupleItems, err = c.schemaList(value, pointer)
+		n.tupleKeyword = key
+		if err == nil {
+			n.additionalItems, err = c.optionalSchema(s, "items", parent)
+			n.additionalKey = "items"
+		}
+	case "items":
+		if _, hasPrefix := s["prefixItems"]; hasPrefix {
+			return nil
+		}
+		if _, isList := value.([]interface{}); isList {
+			n.tupleItems, err = c.schemaList(value, pointer)
+			n.tupleKeyword = key
+		} else {
+			n.items, err = c.compile(value, pointer)
+		}
+	case "maxItems":
+		n.maxItems, err = toCount(value)
+	case "minItems":
+		n.minItems, err = toCount(value)
+	case "uniqueItems":
+		n.uniqueItems, _ = value.(bool)
+
+	case "properties":
+		n.properties, err = c.schemaMap(value, pointer)
+	case "patternProperties":
+		n.patternProperties, err = c.patternSchemas(value, pointer)
+	case "additionalProperties":
+		n.additionalProperties, err = c.compile(value, pointer)
+	case "required":
+		n.required, err = stringList(value)
+	case "maxProperties":
+		n.maxProperties, err = toCount(value)
+	case "minProperties":
+		n.minProperties, err = toCount(value)
+	case "propertyNames":
+		n.propertyNames, err = c.compile(value, pointer)
+
+	case "dependencies":
+		err = c.dependencies(n, value, pointer)
+	case "dependentRequired":
+		n.dependentRequired, err = requiredMap(value)
+		n.dependentRequiredKey = key
+	case "dependentSchemas":
+		n.dependentSchemas, err = c.schemaMap(value, pointer)
+		n.dependentSchemasKey = key
+	}
+
+	return err
+}
+
+// dependencies splits the draft-04 dependencies keyword into property and schema dependencies.
+func (c *compiler) dependencies(n *node, value interface{}, pointer string) error {
+	deps, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("must be an object")
+	}
+
+	for name, dep := range deps {
+		if _, isList := dep.([]interface{}); isList {
+			required, err := stringList(dep)
+			if err != nil {
+				return err
+			}
+
+			if n.dependentRequired == nil {
+				n.dependentRequired = make(map[string][]string)
+			}
+			n.dependentRequired[name] = required
+			n.dependentRequiredKey = "dependencies"
+			continue
+		}
+
+		depNode, err := c.compile(dep, appendPointer(pointer, name))
+		if err != nil {
+			return err
+		}
+
+		if n.dependentSchemas == nil {
+			n.dependentSchemas = make(map[string]*node)
+		}
+		n.dependentSchemas[name] = depNode
+		n.dependentSchemasKey = "dependencies"
+	}
+
+	return nil
+}
+
+func (c *compiler) optionalSchema(s map[string]interface{}, key string, pointer string) (*node, error) {
+	value, found := s[key]
+	if !found {
+		return nil, nil
+	}
+
+	return c.compile(value, appendPointer(pointer, key))
+}
+
+func (c *compiler) schemaList(value interface{}, pointer string) ([]*node, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an array of schemas")
+	}
+
+	nodes := make([]*node, len(list))
+
+	for i, item := range list {
+		var err error
+
+		if nodes[i], err = c.compile(item, appendPointer(pointer, strconv.Itoa(i))); err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, nil
+}
+
+func (c *compiler) schemaMap(value interface{}, pointer string) (map[string]*node, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an object of schemas")
+	}
+
+	nodes := make(map[string]*node, len(m))
+
+	for name, item := range m {
+		var err error
+
+		if nodes[name], err = c.compile(item, appendPointer(pointer, name)); err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, nil
+}
+
+func (c *compiler) patternSchemas(value interface{}, pointer string) ([]*patternNode, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an object of schemas")
+	}
+
+	var patterns []*patternNode
+
+	for source, item := range m {
+		re, err := compilePattern(source)
+		if err != nil {
+			return nil, err
+		}
+
+		sch, err := c.compile(item, appendPointer(pointer, source))
+		if err != nil {
+			return nil, err
+		}
+
+		patterns = append(patterns, &patternNode{source: source, re: re, schema: sch})
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		return patterns[i].source < patterns[j].source
+	})
+
+	return patterns, nil
+}
+
+// resolveRefs points every $ref at its compiled target. Only local refs are supported: "#" and
+// JSON Pointers like "#/definitions/Name".
+func (c *compiler) resolveRefs() error {
+	// compiling a target can find more refs so this can't range over c.refs
+	for i := 0; i < len(c.refs); i++ {
+		n := c.refs[i]
+
+		if !strings.HasPrefix(n.ref, "#") {
+			return fmt.Errorf("unable to resolve $ref '%s': only local refs are supported", n.ref)
+		}
+
+		fragment, err := url.PathUnescape(strings.TrimPrefix(n.ref, "#"))
+		if err != nil {
+			return fmt.Errorf("unable to resolve $ref '%s': %s", n.ref, err)
+		}
+
+		target, found := lookupPointer(c.root, fragment)
+		if !found {
+			return fmt.Errorf("unable to resolve $ref '%s'", n.ref)
+		}
+
+		if n.refTarget, err = c.compile(target, fragment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lookupPointer finds the value at the JSON Pointer in the decoded tree.
+func lookupPointer(tree interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return tree, true
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	current := tree
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = pointerUnescaper.Replace(token)
+
+		switch typed := current.(type) {
+		case map[string]interface{}:
+			value, found := typed[token]
+			if !found {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(typed) {
+				return nil, false
+			}
+			current = typed[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func stringList(value interface{}) ([]string, error) {
+	if s, ok := value.(string); ok {
+		return []string{s}, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be a string or an array of strings")
+	}
+
+	strs := make([]string, len(list))
+
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string or an array of strings")
+		}
+		strs[i] = s
+	}
+
+	return strs, nil
+}
+
+func requiredMap(value interface{}) (map[string][]string, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be an object of string arrays")
+	}
+
+	required := make(map[string][]string, len(m))
+
+	for name, item := range m {
+		var err error
+
+		if required[name], err = stringList(item); err != nil {
+			return nil, err
+		}
+	}
+
+	return required, nil
+}
+
+func toNumber(value interface{}) (*big.Rat, error) {
+	if r, ok := toRat(value); ok {
+		return r, nil
+	}
+
+	return nil, fmt.Errorf("must be a number")
+}
+
+func toCount(value interface{}) (*int, error) {
+	r, ok := toRat(value)
+	if !ok || !r.IsInt() || r.Sign() < 0 || !r.Num().IsInt64() {
+		return nil, fmt.Errorf("must be a non-negative integer")
+	}
+
+	count := int(r.Num().Int64())
+
+	return &count, nil
+}
+
+func exclusiveLimit(value interface{}) (bool, *big.Rat, error) {
+	if b, ok := value.(bool); ok {
+		return b, nil, nil
+	}
+
+	r, err := toNumber(value)
+
+	return false, r, err
+}
+
+func compilePattern(value interface{}) (*regexp.Regexp, error) {
+	source, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("must be a string")
+	}
+
+	re, err := regexp.Compile(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %s", source, err)
+	}
+
+	return re, nil
+}
+
+// toRat converts a decoded JSON number to an exact rational.
+func toRat(value interface{}) (*big.Rat, bool) {
+	switch typed := value.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(typed.String())
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(typed) == nil {
+			return nil, false
+		}
+		return r, true
+	case int:
+		return new(big.Rat).SetInt64(int64(typed)), true
+	case int64:
+		return new(big.Rat).SetInt64(typed), true
+	}
+
+	return nil, false
+}
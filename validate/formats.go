@@ -0,0 +1,96 @@
+package validate
+
+import (
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+	timePattern     = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?([zZ]|[+-]\d{2}:\d{2})$`)
+)
+
+// stringFormats check the string formats. Formats that aren't listed are not checked.
+var stringFormats = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(s))
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	},
+	"time": func(s string) bool {
+		if !timePattern.MatchString(s) {
+			return false
+		}
+		_, err := time.Parse(time.RFC3339Nano, "2006-01-02T"+strings.ToUpper(s))
+		return err == nil
+	},
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	},
+	"hostname": func(s string) bool {
+		return len(s) <= 253 && hostnamePattern.MatchString(s)
+	},
+	"ipv4": func(s string) bool {
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	},
+	"ipv6": func(s string) bool {
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	},
+	"uri-reference": func(s string) bool {
+		_, err := url.Parse(s)
+		return err == nil
+	},
+	"uuid": uuidPattern.MatchString,
+	"regex": func(s string) bool {
+		_, err := regexp.Compile(s)
+		return err == nil
+	},
+}
+
+// integerFormats are the OpenAPI integer formats the generator emits for sized ints.
+var integerFormats = map[string][2]int64{
+	"int32": {math.MinInt32, math.MaxInt32},
+	"int64": {math.MinInt64, math.MaxInt64},
+}
+
+// checkFormat returns a message if the instance doesn't match the format.
+func checkFormat(format string, instance interface{}) string {
+	if s, isString := instance.(string); isString {
+		if check, found := stringFormats[format]; found && !check(s) {
+			return "'" + s + "' is not a valid " + format
+		}
+		return ""
+	}
+
+	limits, found := integerFormats[format]
+	if !found {
+		return ""
+	}
+
+	number, isNumber := toRat(instance)
+	if !isNumber || !number.IsInt() {
+		return ""
+	}
+
+	if number.Cmp(new(big.Rat).SetInt64(limits[0])) < 0 || number.Cmp(new(big.Rat).SetInt64(limits[1])) > 0 {
+		return ratString(number) + " does not fit in " + format
+	}
+
+	return ""
+}
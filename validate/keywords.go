@@ -0,0 +1,379 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// validate checks the instance against the node, appending a ValidationError for every violation.
+// instancePath and keywordPath are the JSON Pointers of the instance and of the node.
+func (n *node) validate(instance interface{}, instancePath string, keywordPath string, errs *[]ValidationError) {
+	if n.always != nil {
+		if !*n.always {
+			n.fail(errs, instancePath, keywordPath, "no value is allowed here")
+		}
+		return
+	}
+
+	if n.refTarget != nil {
+		n.refTarget.validate(instance, instancePath, appendPointer(keywordPath, "$ref"), errs)
+	}
+
+	n.validateGeneric(instance, instancePath, keywordPath, errs)
+
+	switch typed := instance.(type) {
+	case map[string]interface{}:
+		n.validateObject(typed, instancePath, keywordPath, errs)
+	case []interface{}:
+		n.validateArray(typed, instancePath, keywordPath, errs)
+	case string:
+		n.validateString(typed, instancePath, keywordPath, errs)
+	default:
+		if number, ok := toRat(instance); ok {
+			n.validateNumber(number, instancePath, keywordPath, errs)
+		}
+	}
+}
+
+// valid returns true if the instance passes the node without collecting errors.
+func (n *node) valid(instance interface{}, instancePath string, keywordPath string) bool {
+	var errs []ValidationError
+
+	n.validate(instance, instancePath, keywordPath, &errs)
+
+	return len(errs) == 0
+}
+
+func (n *node) fail(errs *[]ValidationError, instancePath string, keywordPath string, format string, args ...interface{}) {
+	*errs = append(*errs, ValidationError{
+		InstancePath: instancePath,
+		KeywordPath:  keywordPath,
+		Message:      fmt.Sprintf(format, args...),
+	})
+}
+
+func (n *node) validateGeneric(instance interface{}, instancePath string, keywordPath string, errs *[]ValidationError) {
+	if n.types != nil && !(n.nullable && instance == nil) {
+		actual := jsonType(instance)
+		matched := false
+
+		for _, t := range n.types {
+			if t == actual || (t == "number" && actual == "integer") {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "type"), "expected %s but got %s", strings.Join(n.types, " or "), actual)
+		}
+	}
+
+	if n.hasEnum {
+		found := false
+
+		for _, value := range n.enum {
+			if equal(instance, value) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "enum"), "value must be one of %s", describeValues(n.enum))
+		}
+	}
+
+	if n.hasConst && !equal(instance, n.constVal) {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "const"), "value must be %s", describeValue(n.constVal))
+	}
+
+	for i, sub := range n.allOf {
+		sub.validate(instance, instancePath, appendPointer(appendPointer(keywordPath, "allOf"), strconv.Itoa(i)), errs)
+	}
+
+	if n.anyOf != nil {
+		matched := false
+
+		for i, sub := range n.anyOf {
+			if sub.valid(instance, instancePath, appendPointer(appendPointer(keywordPath, "anyOf"), strconv.Itoa(i))) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "anyOf"), "value must match at least one of the anyOf schemas")
+		}
+	}
+
+	if n.oneOf != nil {
+		matches := 0
+
+		for i, sub := range n.oneOf {
+			if sub.valid(instance, instancePath, appendPointer(appendPointer(keywordPath, "oneOf"), strconv.Itoa(i))) {
+				matches++
+			}
+		}
+
+		if matches != 1 {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "oneOf"), "value must match exactly one of the oneOf schemas but matched %d", matches)
+		}
+	}
+
+	if n.not != nil && n.not.valid(instance, instancePath, appendPointer(keywordPath, "not")) {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "not"), "value must not match the not schema")
+	}
+
+	if n.format != "" {
+		if msg := checkFormat(n.format, instance); msg != "" {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "format"), "%s", msg)
+		}
+	}
+}
+
+func (n *node) validateNumber(number *big.Rat, instancePath string, keywordPath string, errs *[]ValidationError) {
+	if n.multipleOf != nil && !new(big.Rat).Quo(number, n.multipleOf).IsInt() {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "multipleOf"), "%s is not a multiple of %s", ratString(number), ratString(n.multipleOf))
+	}
+
+	if n.maximum != nil {
+		cmp := number.Cmp(n.maximum)
+		if n.exclusiveMaximum && cmp >= 0 {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "maximum"), "must be less than %s", ratString(n.maximum))
+		} else if cmp > 0 {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "maximum"), "must be at most %s", ratString(n.maximum))
+		}
+	}
+
+	if n.minimum != nil {
+		cmp := number.Cmp(n.minimum)
+		if n.exclusiveMinimum && cmp <= 0 {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "minimum"), "must be greater than %s", ratString(n.minimum))
+		} else if cmp < 0 {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "minimum"), "must be at least %s", ratString(n.minimum))
+		}
+	}
+
+	if n.exclusiveMaximumValue != nil && number.Cmp(n.exclusiveMaximumValue) >= 0 {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "exclusiveMaximum"), "must be less than %s", ratString(n.exclusiveMaximumValue))
+	}
+
+	if n.exclusiveMinimumValue != nil && number.Cmp(n.exclusiveMinimumValue) <= 0 {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "exclusiveMinimum"), "must be greater than %s", ratString(n.exclusiveMinimumValue))
+	}
+}
+
+func (n *node) validateString(s string, instancePath string, keywordPath string, errs *[]ValidationError) {
+	length := utf8.RuneCountInString(s)
+
+	if n.maxLength != nil && length > *n.maxLength {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "maxLength"), "length must be at most %d but is %d", *n.maxLength, length)
+	}
+
+	if n.minLength != nil && length < *n.minLength {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "minLength"), "length must be at least %d but is %d", *n.minLength, length)
+	}
+
+	if n.pattern != nil && !n.pattern.MatchString(s) {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "pattern"), "does not match pattern '%s'", n.pattern.String())
+	}
+}
+
+func (n *node) validateArray(items []interface{}, instancePath string, keywordPath string, errs *[]ValidationError) {
+	if n.maxItems != nil && len(items) > *n.maxItems {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "maxItems"), "must have at most %d items but has %d", *n.maxItems, len(items))
+	}
+
+	if n.minItems != nil && len(items) < *n.minItems {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "minItems"), "must have at least %d items but has %d", *n.minItems, len(items))
+	}
+
+	if n.uniqueItems {
+	unique:
+		for i := 1; i < len(items); i++ {
+			for j := 0; j < i; j++ {
+				if equal(items[i], items[j]) {
+					n.fail(errs, instancePath, appendPointer(keywordPath, "uniqueItems"), "items %d and %d are equal", j, i)
+					break unique
+				}
+			}
+		}
+	}
+
+	for i, item := range items {
+		itemPath := appendPointer(instancePath, strconv.Itoa(i))
+
+		switch {
+		case n.items != nil:
+			n.items.validate(item, itemPath, appendPointer(keywordPath, "items"), errs)
+		case i < len(n.tupleItems):
+			n.tupleItems[i].validate(item, itemPath, appendPointer(appendPointer(keywordPath, n.tupleKeyword), strconv.Itoa(i)), errs)
+		case n.tupleItems != nil && n.additionalItems != nil:
+			n.additionalItems.validate(item, itemPath, appendPointer(keywordPath, n.additionalKey), errs)
+		}
+	}
+}
+
+func (n *node) validateObject(obj map[string]interface{}, instancePath string, keywordPath string, errs *[]ValidationError) {
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	if n.maxProperties != nil && len(obj) > *n.maxProperties {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "maxProperties"), "must have at most %d properties but has %d", *n.maxProperties, len(obj))
+	}
+
+	if n.minProperties != nil && len(obj) < *n.minProperties {
+		n.fail(errs, instancePath, appendPointer(keywordPath, "minProperties"), "must have at least %d properties but has %d", *n.minProperties, len(obj))
+	}
+
+	for _, name := range n.required {
+		if _, found := obj[name]; !found {
+			n.fail(errs, instancePath, appendPointer(keywordPath, "required"), "missing required property '%s'", name)
+		}
+	}
+
+	for _, name := range names {
+		for _, dep := range n.dependentRequired[name] {
+			if _, found := obj[dep]; !found {
+				n.fail(errs, instancePath, appendPointer(appendPointer(keywordPath, n.dependentRequiredKey), name), "property '%s' is required when '%s' is present", dep, name)
+			}
+		}
+
+		if sub, found := n.dependentSchemas[name]; found {
+			sub.validate(obj, instancePath, appendPointer(appendPointer(keywordPath, n.dependentSchemasKey), name), errs)
+		}
+	}
+
+	for _, name := range names {
+		value := obj[name]
+		valuePath := appendPointer(instancePath, name)
+		matched := false
+
+		if sub, found := n.properties[name]; found {
+			matched = true
+			sub.validate(value, valuePath, appendPointer(appendPointer(keywordPath, "properties"), name), errs)
+		}
+
+		for _, pattern := range n.patternProperties {
+			if pattern.re.MatchString(name) {
+				matched = true
+				pattern.schema.validate(value, valuePath, appendPointer(appendPointer(keywordPath, "patternProperties"), pattern.source), errs)
+			}
+		}
+
+		if !matched && n.additionalProperties != nil {
+			additionalPath := appendPointer(keywordPath, "additionalProperties")
+
+			if n.additionalProperties.always != nil && !*n.additionalProperties.always {
+				n.fail(errs, valuePath, additionalPath, "property '%s' is not allowed", name)
+			} else {
+				n.additionalProperties.validate(value, valuePath, additionalPath, errs)
+			}
+		}
+
+		if n.propertyNames != nil {
+			n.propertyNames.validate(name, valuePath, appendPointer(keywordPath, "propertyNames"), errs)
+		}
+	}
+}
+
+// jsonType returns the JSON type of a decoded value. Whole numbers are integers.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	if r, ok := toRat(value); ok {
+		if r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// equal compares decoded JSON values, treating numbers with the same value as equal.
+func equal(a interface{}, b interface{}) bool {
+	if ra, ok := toRat(a); ok {
+		rb, ok := toRat(b)
+		return ok && ra.Cmp(rb) == 0
+	}
+
+	switch typed := a.(type) {
+	case []interface{}:
+		other, ok := b.([]interface{})
+		if !ok || len(typed) != len(other) {
+			return false
+		}
+		for i := range typed {
+			if !equal(typed[i], other[i]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]interface{}:
+		other, ok := b.(map[string]interface{})
+		if !ok || len(typed) != len(other) {
+			return false
+		}
+		for key, value := range typed {
+			otherValue, found := other[key]
+			if !found || !equal(value, otherValue) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return a == b
+}
+
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	f, _ := r.Float64()
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func describeValue(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(b)
+}
+
+func describeValues(values []interface{}) string {
+	described := make([]string, len(values))
+
+	for i, value := range values {
+		described[i] = describeValue(value)
+	}
+
+	return "[" + strings.Join(described, ", ") + "]"
+}
@@ -0,0 +1,134 @@
+// Package validate validates JSON documents against schemas built by the generator or parsed with
+// the schema package.
+//
+// A schema is compiled once into a Validator which can then be used to validate any number of
+// documents, concurrently if needed:
+//
+//	validator, err := validate.Compile(rootSchema)
+//	...
+//	errs, err := validator.Validate(docBytes)
+//
+// Every violation is returned, each with a JSON Pointer to the offending value in the document and
+// a JSON Pointer to the keyword in the schema that failed. Local $refs are resolved against the
+// definitions (or $defs) of the root schema.
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/brainicorn/jsonschemagen/schema"
+)
+
+// ValidationError is a single violation found while validating a document.
+type ValidationError struct {
+	// InstancePath is a JSON Pointer to the value in the document, "" for the document itself.
+	InstancePath string
+	// KeywordPath is a JSON Pointer to the failing keyword in the schema. Refs that were followed
+	// show up as $ref segments.
+	KeywordPath string
+	// Message describes the violation.
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("#%s: %s (%s)", e.InstancePath, e.Message, e.KeywordPath)
+}
+
+// Validator validates documents against a compiled schema. It's safe for concurrent use.
+type Validator struct {
+	root *node
+}
+
+// Compile compiles the schema into a Validator. An error is returned if a $ref can't be resolved,
+// a pattern isn't a valid regular expression or a keyword has the wrong kind of value.
+func Compile(s schema.JSONSchema) (*Validator, error) {
+	schemaBytes, err := json.Marshal(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return CompileJSON(schemaBytes)
+}
+
+// CompileJSON compiles a schema from its JSON encoding. Unlike schema.FromJSON, keywords the schema
+// package doesn't model are kept.
+func CompileJSON(schemaBytes []byte) (*Validator, error) {
+	tree, err := decode(bytes.NewReader(schemaBytes))
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding schema: %s", err)
+	}
+
+	c := &compiler{root: tree, nodes: make(map[string]*node)}
+	root, err := c.compile(tree, "")
+
+	if err == nil {
+		err = c.resolveRefs()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Validator{root: root}, nil
+}
+
+// Validate validates the JSON document. An error is only returned if the document isn't valid
+// JSON, violations are returned as ValidationErrors.
+func (v *Validator) Validate(doc []byte) ([]ValidationError, error) {
+	return v.ValidateReader(bytes.NewReader(doc))
+}
+
+// ValidateReader is like Validate but reads the document from r.
+func (v *Validator) ValidateReader(r io.Reader) ([]ValidationError, error) {
+	instance, err := decode(r)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding document: %s", err)
+	}
+
+	return v.ValidateValue(instance), nil
+}
+
+// ValidateValue validates a document that was already decoded with encoding/json into interfaces.
+// Numbers can be float64s or json.Numbers.
+func (v *Validator) ValidateValue(instance interface{}) []ValidationError {
+	var errs []ValidationError
+
+	v.root.validate(instance, "", "", &errs)
+
+	return errs
+}
+
+// decode decodes a single JSON value keeping numbers exact.
+func decode(r io.Reader) (interface{}, error) {
+	var value interface{}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+
+	return value, nil
+}
+
+// pointerEscaper escapes a JSON Pointer reference token.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// pointerUnescaper reverses pointerEscaper.
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func appendPointer(pointer string, token string) string {
+	return pointer + "/" + pointerEscaper.Replace(token)
+}
@@ -0,0 +1,276 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brainicorn/jsonschemagen/generator"
+	"github.com/brainicorn/jsonschemagen/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ValidateItem struct {
+	// @jsonSchema(required=true)
+	SKU string `json:"sku"`
+	// @jsonSchema(minimum=1, maximum=10, exclusiveMaximum=true)
+	Quantity int `json:"quantity"`
+}
+
+// @jsonSchema(additionalProperties=false)
+type ValidateOrder struct {
+	// @jsonSchema(required=true, minLength=2, pattern="^[a-z]+$")
+	Name string `json:"name"`
+	// @jsonSchema(multipleOf=0.5)
+	Price float64 `json:"price"`
+	// @jsonSchema(uniqueItems=true, maxItems=2)
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created"`
+	// @jsonSchema(nullable=true)
+	Item  *ValidateItem  `json:"item"`
+	Items []ValidateItem `json:"items"`
+	// @jsonSchema(const="v1")
+	Kind string `json:"kind"`
+	// @jsonSchema(enum=["red","green"])
+	Color string         `json:"color"`
+	Extra map[string]int `json:"extra"`
+}
+
+func compileOrder(t *testing.T, version schema.SpecVersion) *Validator {
+	opts := generator.NewOptions()
+	opts.IncludeTests = true
+	opts.LogLevel = generator.QuietLevel
+	opts.SpecVersion = version
+
+	orderSchema, err := generator.NewJSONSchemaGenerator("github.com/brainicorn/jsonschemagen/validate", "ValidateOrder", opts).Generate()
+	assert.NoError(t, err)
+
+	validator, err := Compile(orderSchema)
+	assert.NoError(t, err)
+
+	return validator
+}
+
+func compileJSON(t *testing.T, schemaJSON string) *Validator {
+	validator, err := CompileJSON([]byte(schemaJSON))
+	assert.NoError(t, err)
+
+	return validator
+}
+
+// failures returns "instancePath keywordPath" for each error to keep assertions short.
+func failures(t *testing.T, validator *Validator, doc string) []string {
+	errs, err := validator.Validate([]byte(doc))
+	assert.NoError(t, err)
+
+	var found []string
+	for _, e := range errs {
+		found = append(found, e.InstancePath+" "+e.KeywordPath)
+	}
+
+	return found
+}
+
+const validOrder = `{
+	"name": "widget",
+	"price": 2.5,
+	"tags": ["a", "b"],
+	"created": "2024-01-02T03:04:05Z",
+	"item": null,
+	"items": [{"sku": "a1", "quantity": 9}],
+	"kind": "v1",
+	"color": "red",
+	"extra": {"x": 1}
+}`
+
+const invalidOrder = `{
+	"price": 2.2,
+	"tags": ["a", "a", "b"],
+	"created": "yesterday",
+	"item": {"quantity": 10},
+	"items": [{"sku": 7, "quantity": 0}],
+	"kind": "v2",
+	"color": "blue",
+	"extra": {"x": "one"},
+	"unknown": true
+}`
+
+func TestValidateGeneratedSchema(t *testing.T) {
+	t.Parallel()
+
+	validator := compileOrder(t, schema.SpecVersionDraftV4)
+
+	assert.Empty(t, failures(t, validator, validOrder))
+
+	assert.Equal(t, []string{
+		" /required",
+		"/color /properties/color/enum",
+		"/created /properties/created/format",
+		"/extra/x /properties/extra/additionalProperties/type",
+		"/item /properties/item/anyOf",
+		"/items/0/quantity /properties/items/items/$ref/properties/quantity/minimum",
+		"/items/0/sku /properties/items/items/$ref/properties/sku/type",
+		"/kind /properties/kind/enum",
+		"/price /properties/price/multipleOf",
+		"/tags /properties/tags/maxItems",
+		"/tags /properties/tags/uniqueItems",
+		"/unknown /additionalProperties",
+	}, failures(t, validator, invalidOrder))
+}
+
+func TestValidateGeneratedSchemaForNewerVersions(t *testing.T) {
+	t.Parallel()
+
+	// the same document has to give the same results whatever version the schema was generated for
+	validator := compileOrder(t, schema.SpecVersionDraft202012)
+
+	assert.Empty(t, failures(t, validator, validOrder))
+	assert.Contains(t, failures(t, validator, invalidOrder), "/kind /properties/kind/const")
+}
+
+func TestValidateRecursiveRefs(t *testing.T) {
+	t.Parallel()
+
+	validator := compileJSON(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"children": {"type": "array", "items": {"$ref": "#"}},
+			"tree": {"$ref": "#/definitions/Node"}
+		},
+		"definitions": {
+			"Node": {
+				"type": "object",
+				"properties": {
+					"value": {"type": "integer"},
+					"next": {"$ref": "#/definitions/Node"}
+				}
+			}
+		}
+	}`)
+
+	assert.Empty(t, failures(t, validator, `{"children": [{"name": "a", "children": []}], "tree": {"next": {"value": 1}}}`))
+	assert.Equal(t, []string{
+		"/children/0/name /properties/children/items/$ref/properties/name/type",
+		"/tree/next/next/value /properties/tree/$ref/properties/next/$ref/properties/next/$ref/properties/value/type",
+	}, failures(t, validator, `{"children": [{"name": 1}], "tree": {"next": {"next": {"value": 1.5}}}}`))
+}
+
+func TestValidateDraft202012Keywords(t *testing.T) {
+	t.Parallel()
+
+	validator := compileJSON(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "string"}], "items": false},
+			"count": {"type": "integer", "exclusiveMinimum": 0, "exclusiveMaximum": 5},
+			"version": {"const": 2},
+			"child": {"$ref": "#/$defs/Child"}
+		},
+		"dependentRequired": {"count": ["version"]},
+		"$defs": {
+			"Child": {"type": "object", "propertyNames": {"maxLength": 3}}
+		}
+	}`)
+
+	assert.Empty(t, failures(t, validator, `{"point": [1, "a"], "count": 4, "version": 2.0, "child": {"abc": 1}}`))
+	assert.Equal(t, []string{
+		" /dependentRequired/count",
+		"/child/abcd /properties/child/$ref/propertyNames/maxLength",
+		"/count /properties/count/exclusiveMaximum",
+		"/point/1 /properties/point/prefixItems/1/type",
+		"/point/2 /properties/point/items",
+	}, failures(t, validator, `{"point": [1, 2, 3], "count": 5, "child": {"abcd": 1}}`))
+}
+
+func TestValidateDraft04Keywords(t *testing.T) {
+	t.Parallel()
+
+	validator := compileJSON(t, `{
+		"type": "object",
+		"properties": {
+			"pair": {"type": "array", "items": [{"type": "string"}], "additionalItems": {"type": "integer"}},
+			"ratio": {"type": "number", "minimum": 0, "exclusiveMinimum": true},
+			"id": {"type": "string", "format": "uuid"},
+			"small": {"type": "integer", "format": "int32"},
+			"either": {"oneOf": [{"type": "integer"}, {"minimum": 0}]},
+			"other": {"not": {"type": "string"}}
+		},
+		"patternProperties": {"^x-": {"type": "string"}},
+		"dependencies": {"ratio": {"required": ["pair"]}},
+		"minProperties": 1,
+		"maxProperties": 3
+	}`)
+
+	assert.Empty(t, failures(t, validator, `{"pair": ["a", 1, 2], "ratio": 0.5, "x-note": "hi"}`))
+	assert.Equal(t, []string{
+		" /maxProperties",
+		" /dependencies/ratio/required",
+		"/either /properties/either/oneOf",
+		"/id /properties/id/format",
+		"/other /properties/other/not",
+		"/ratio /properties/ratio/minimum",
+		"/small /properties/small/format",
+		"/x-note /patternProperties/^x-/type",
+	}, failures(t, validator, `{"ratio": 0, "id": "nope", "small": 3000000000, "either": 1, "other": "s", "x-note": 1}`))
+	assert.Equal(t, []string{" /minProperties"}, failures(t, validator, `{}`))
+}
+
+func TestValidateNullableAndBooleanSchemas(t *testing.T) {
+	t.Parallel()
+
+	validator := compileJSON(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "nullable": true},
+			"anything": true,
+			"nothing": false
+		}
+	}`)
+
+	assert.Empty(t, failures(t, validator, `{"name": null, "anything": [1, {"a": null}]}`))
+	assert.Equal(t, []string{"/nothing /properties/nothing"}, failures(t, validator, `{"nothing": 1}`))
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	validator := compileJSON(t, `{"properties": {"tags": {"type": "array", "items": {"type": "string", "maxLength": 2}}}}`)
+
+	errs, err := validator.ValidateReader(strings.NewReader(`{"tags": ["abc"]}`))
+
+	assert.NoError(t, err)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "#/tags/0: length must be at most 2 but is 3 (/properties/tags/items/maxLength)", errs[0].Error())
+}
+
+func TestValidateInvalidDocument(t *testing.T) {
+	t.Parallel()
+
+	validator := compileJSON(t, `{"type": "object"}`)
+
+	_, err := validator.Validate([]byte(`{"a": `))
+	assert.Error(t, err)
+
+	_, err = validator.Validate([]byte(`{} {}`))
+	assert.Error(t, err)
+}
+
+func TestCompileErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := CompileJSON([]byte(`{"properties": {"a": {"$ref": "#/definitions/Missing"}}}`))
+	assert.EqualError(t, err, "unable to resolve $ref '#/definitions/Missing'")
+
+	_, err = CompileJSON([]byte(`{"$ref": "other.json#/definitions/A"}`))
+	assert.EqualError(t, err, "unable to resolve $ref 'other.json#/definitions/A': only local refs are supported")
+
+	_, err = CompileJSON([]byte(`{"properties": {"a": {"pattern": "^(?=x)"}}}`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid pattern '^(?=x)'")
+
+	_, err = CompileJSON([]byte(`{"minLength": -1}`))
+	assert.EqualError(t, err, "invalid schema keyword at '/minLength': must be a non-negative integer")
+}